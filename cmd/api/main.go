@@ -12,11 +12,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/vern/skillflow/internal/api/router"
 	"github.com/vern/skillflow/internal/config"
+	"github.com/vern/skillflow/internal/realtime"
 	"github.com/vern/skillflow/internal/repository"
 	"github.com/vern/skillflow/internal/service"
 	"github.com/vern/skillflow/pkg/cache"
 	"github.com/vern/skillflow/pkg/database"
 	"github.com/vern/skillflow/pkg/logger"
+	"github.com/vern/skillflow/pkg/mailer"
+	"github.com/vern/skillflow/pkg/webpush"
 )
 
 func main() {
@@ -46,21 +49,57 @@ func main() {
 	// Initialize repositories
 	repos := repository.NewRepositories(db)
 
+	// Initialize the WebSocket hub and the broker that fans real-time
+	// events out to the hubs of every replica
+	replay := realtime.NewReplayBuffer(cfg.WebSocket.Broker, redisClient)
+	hub := realtime.NewHub(cfg.WebSocket, cfg.CORS.AllowedOrigins, replay, log)
+	broker, err := realtime.NewBroker(cfg.WebSocket.Broker, redisClient, hub, log)
+	if err != nil {
+		log.Fatal("Failed to initialize realtime broker", "error", err)
+	}
+
+	// Initialize the mailer for notification e-mails and digests
+	mail, err := mailer.New(cfg.Email)
+	if err != nil {
+		log.Fatal("Failed to initialize mailer", "error", err)
+	}
+
+	// Initialize Web Push, which stays disabled without a VAPID key
+	push, err := webpush.New(cfg.WebPush)
+	if err != nil {
+		log.Fatal("Failed to initialize web push", "error", err)
+	}
+
 	// Initialize services
 	services := service.NewServices(service.ServicesDeps{
-		Repos:  repos,
-		Cache:  redisClient,
-		Config: cfg,
-		Logger: log,
+		Repos:    repos,
+		Cache:    redisClient,
+		Config:   cfg,
+		Logger:   log,
+		Realtime: realtime.WithReplay(broker, replay),
+		Mailer:   mail,
+		WebPush:  push,
 	})
 
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go services.Event.RunReminders(jobsCtx, time.Minute)
+	go services.Goal.RunReminders(jobsCtx, time.Hour)
+	go services.Email.Run(jobsCtx, 5*time.Minute)
+	go func() {
+		if err := broker.Run(jobsCtx); err != nil {
+			log.Error("Realtime broker stopped", "error", err)
+		}
+	}()
+
 	// Set Gin mode
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	// Initialize router
-	r := router.NewRouter(services, cfg, log)
+	r := router.NewRouter(services, hub, cfg, log)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -71,6 +110,10 @@ func main() {
 		MaxHeaderBytes: 1 << 20, // 1 MB
 	}
 
+	// Shutdown neither closes hijacked WebSocket connections nor ends event
+	// streams
+	srv.RegisterOnShutdown(hub.Close)
+
 	// Start server in goroutine
 	go func() {
 		log.Info("Starting server", "port", cfg.Server.Port, "mode", cfg.Server.Mode)
@@ -85,6 +128,7 @@ func main() {
 	<-quit
 
 	log.Info("Shutting down server...")
+	stopJobs()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
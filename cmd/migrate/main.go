@@ -49,37 +49,188 @@ func main() {
 }
 
 func runMigrations(db *database.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		&models.User{},
 		&models.Profile{},
 		&models.Post{},
+		&models.PinnedPost{},
 		&models.Comment{},
 		&models.Reaction{},
 		&models.Connection{},
+		&models.UserBlock{},
+		&models.UserSettings{},
 		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.NotificationSettings{},
+		&models.DigestDelivery{},
+		&models.PushSubscription{},
+		&models.Conversation{},
+		&models.ConversationParticipant{},
 		&models.Message{},
+		&models.MessageReaction{},
+		&models.MessageHide{},
 		&models.Group{},
 		&models.GroupMember{},
+		&models.GroupBan{},
+		&models.GroupJoinRequest{},
+		&models.GroupInvitation{},
+		&models.GroupInviteLink{},
+		&models.GroupEvent{},
+		&models.EventRSVP{},
+		&models.SkillCategory{},
 		&models.Skill{},
+		&models.SkillSynonym{},
+		&models.SkillTarget{},
 		&models.UserSkill{},
 		&models.Endorsement{},
+		&models.SkillAssessor{},
+		&models.SkillAssessment{},
+		&models.LearningGoal{},
+		&models.GoalCheckIn{},
+		&models.MentorOffer{},
+		&models.Mentorship{},
 		&models.File{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := backfillConversations(db); err != nil {
+		return err
+	}
+	if err := backfillSkillCategories(db); err != nil {
+		return err
+	}
+	return backfillNotifications(db)
+}
+
+// backfillSkillCategories creates category entities for the free-text
+// categories skills had before categories existed and links the skills to
+// them. It only touches skills without a category ID, so running it again is
+// a no-op.
+func backfillSkillCategories(db *database.DB) error {
+	statements := []string{
+		`INSERT INTO skill_categories (name, description, created_at, updated_at)
+		SELECT MIN(TRIM(category)), '', NOW(), NOW()
+		FROM skills
+		WHERE category_id IS NULL AND TRIM(category) <> ''
+			AND NOT EXISTS (SELECT 1 FROM skill_categories c WHERE LOWER(c.name) = LOWER(TRIM(skills.category)))
+		GROUP BY LOWER(TRIM(category))`,
+
+		`UPDATE skills s SET category_id = c.id, category = c.name
+		FROM skill_categories c
+		WHERE s.category_id IS NULL AND LOWER(c.name) = LOWER(TRIM(s.category))`,
+	}
+
+	return db.Transaction(func(tx *database.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to backfill skill categories: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// backfillNotifications sets updated_at on notifications created before the
+// column existed, so they sort by their creation time.
+func backfillNotifications(db *database.DB) error {
+	if err := db.Exec(`UPDATE notifications SET updated_at = created_at WHERE updated_at IS NULL`).Error; err != nil {
+		return fmt.Errorf("failed to backfill notifications: %w", err)
+	}
+	return nil
+}
+
+// backfillConversations moves 1:1 messages written before conversations
+// existed into direct conversations. Read cursors start at the latest message
+// each participant sent or has read. It only touches messages without a
+// conversation, so running it again is a no-op.
+func backfillConversations(db *database.DB) error {
+	statements := []string{
+		`INSERT INTO conversations (type, direct_key, creator_id, created_at, updated_at)
+		SELECT 'direct',
+			LEAST(sender_id, receiver_id) || ':' || GREATEST(sender_id, receiver_id),
+			(ARRAY_AGG(sender_id ORDER BY id))[1],
+			MIN(created_at), MAX(created_at)
+		FROM messages
+		WHERE conversation_id IS NULL AND receiver_id IS NOT NULL
+		GROUP BY LEAST(sender_id, receiver_id), GREATEST(sender_id, receiver_id)
+		ON CONFLICT (direct_key) DO NOTHING`,
+
+		`UPDATE messages m SET conversation_id = c.id
+		FROM conversations c
+		WHERE m.conversation_id IS NULL AND m.receiver_id IS NOT NULL
+			AND c.direct_key = LEAST(m.sender_id, m.receiver_id) || ':' || GREATEST(m.sender_id, m.receiver_id)`,
+
+		`INSERT INTO conversation_participants (conversation_id, user_id, role, last_read_message_id, joined_at)
+		SELECT c.id, u.user_id, 'member',
+			COALESCE((SELECT MAX(m.id) FROM messages m
+				WHERE m.conversation_id = c.id AND (m.sender_id = u.user_id OR m.read_at IS NOT NULL)), 0),
+			c.created_at
+		FROM conversations c
+		CROSS JOIN LATERAL (VALUES
+			(SPLIT_PART(c.direct_key, ':', 1)::bigint),
+			(SPLIT_PART(c.direct_key, ':', 2)::bigint)) AS u(user_id)
+		WHERE c.type = 'direct'
+		ON CONFLICT (conversation_id, user_id) DO NOTHING`,
+
+		`UPDATE conversations c SET last_message_id = x.last_id, last_message_at = x.last_at
+		FROM (SELECT conversation_id, MAX(id) AS last_id, MAX(created_at) AS last_at
+			FROM messages WHERE deleted_at IS NULL AND conversation_id IS NOT NULL
+			GROUP BY conversation_id) x
+		WHERE c.id = x.conversation_id AND c.last_message_id IS NULL`,
+	}
+
+	return db.Transaction(func(tx *database.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to backfill conversations: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
 func rollbackMigrations(db *database.DB) error {
 	models := []interface{}{
 		&models.File{},
+		&models.Mentorship{},
+		&models.MentorOffer{},
+		&models.GoalCheckIn{},
+		&models.LearningGoal{},
+		&models.SkillAssessment{},
+		&models.SkillAssessor{},
 		&models.Endorsement{},
 		&models.UserSkill{},
+		&models.SkillTarget{},
+		&models.SkillSynonym{},
 		&models.Skill{},
+		&models.SkillCategory{},
+		&models.EventRSVP{},
+		&models.GroupEvent{},
+		&models.GroupInviteLink{},
+		&models.GroupInvitation{},
+		&models.GroupJoinRequest{},
+		&models.GroupBan{},
 		&models.GroupMember{},
 		&models.Group{},
+		&models.MessageHide{},
+		&models.MessageReaction{},
+		"message_attachments",
 		&models.Message{},
+		&models.ConversationParticipant{},
+		&models.Conversation{},
+		&models.PushSubscription{},
+		&models.DigestDelivery{},
+		&models.NotificationSettings{},
+		&models.NotificationPreference{},
 		&models.Notification{},
+		&models.UserSettings{},
+		&models.UserBlock{},
 		&models.Connection{},
 		&models.Reaction{},
 		&models.Comment{},
+		&models.PinnedPost{},
 		&models.Post{},
 		&models.Profile{},
 		&models.User{},
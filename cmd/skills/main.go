@@ -0,0 +1,151 @@
+// Command skills imports a skill catalog from CSV or JSON, or exports the
+// catalog in the same formats.
+//
+//	skills import [-dry-run] [-format csv|json] FILE
+//	skills export [-format csv|json] [-o FILE]
+//
+// The format defaults to the file extension; FILE "-" reads standard input.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vern/skillflow/internal/config"
+	"github.com/vern/skillflow/internal/repository"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/database"
+	"github.com/vern/skillflow/pkg/logger"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const usage = `Usage:
+  skills import [-dry-run] [-format csv|json] FILE
+  skills export [-format csv|json] [-o FILE]`
+
+func main() {
+	log := logger.New()
+
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+	command := os.Args[1]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	format := flags.String("format", "", "catalog format: csv or json")
+	dryRun := flags.Bool("dry-run", false, "report the changes without applying them")
+	output := flags.String("o", "-", "export file")
+	flags.Parse(os.Args[2:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration", "error", err)
+	}
+
+	db, err := database.NewPostgresDB(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database", "error", err)
+	}
+	defer database.Close(db)
+	// GORM logs SQL to standard output, which carries the report or export.
+	db.Logger = gormlogger.Default.LogMode(gormlogger.Silent)
+
+	skills := service.NewSkillService(service.ServicesDeps{
+		Repos:  repository.NewRepositories(db),
+		Config: cfg,
+		Logger: log,
+	})
+	ctx := context.Background()
+
+	switch command {
+	case "import":
+		if flags.NArg() != 1 {
+			log.Fatal(usage)
+		}
+		if err := runImport(ctx, skills, flags.Arg(0), *format, *dryRun); err != nil {
+			log.Fatal("Import failed", "error", err)
+		}
+	case "export":
+		if err := runExport(ctx, skills, *output, *format); err != nil {
+			log.Fatal("Export failed", "error", err)
+		}
+	default:
+		log.Fatal("Unknown command", "command", command)
+	}
+}
+
+func runImport(ctx context.Context, skills *service.SkillService, path, format string, dryRun bool) error {
+	format, err := catalogFormat(path, format)
+	if err != nil {
+		return err
+	}
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	report, err := skills.ImportCatalog(ctx, in, format, dryRun)
+	if err != nil {
+		return err
+	}
+	printReport(os.Stdout, report)
+	return nil
+}
+
+func runExport(ctx context.Context, skills *service.SkillService, path, format string) error {
+	format, err := catalogFormat(path, format)
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		return skills.ExportCatalog(ctx, os.Stdout, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := skills.ExportCatalog(ctx, f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// catalogFormat returns the -format flag, or else the file extension, or
+// else JSON.
+func catalogFormat(path, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	if format == "" {
+		format = service.CatalogJSON
+	}
+	return service.ParseCatalogFormat(format)
+}
+
+func printReport(w io.Writer, report *repository.CatalogReport) {
+	for _, change := range report.Changes {
+		line := fmt.Sprintf("%s %s %q", change.Action, change.Type, change.Name)
+		if len(change.Changes) > 0 {
+			line += ": " + strings.Join(change.Changes, "; ")
+		}
+		fmt.Fprintln(w, line)
+	}
+	for _, conflict := range report.Conflicts {
+		fmt.Fprintln(w, "conflict:", conflict)
+	}
+	fmt.Fprintf(w, "%d changed, %d unchanged, %d conflicts\n", len(report.Changes), report.Unchanged, len(report.Conflicts))
+	if report.DryRun {
+		fmt.Fprintln(w, "dry run: no changes were applied")
+	}
+}
@@ -0,0 +1,20 @@
+// Command vapid generates a VAPID key pair for the webpush configuration.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vern/skillflow/pkg/webpush"
+)
+
+func main() {
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to generate keys:", err)
+		os.Exit(1)
+	}
+	fmt.Println("webpush:")
+	fmt.Printf("  public_key: %s\n", publicKey)
+	fmt.Printf("  private_key: %s\n", privateKey)
+}
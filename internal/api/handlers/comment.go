@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type CommentHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewCommentHandler(services *service.Services, log *logger.Logger) *CommentHandler {
+	return &CommentHandler{services: services, logger: log}
+}
+
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.CreateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.PostID = postID
+	input.UserID = userID
+
+	comment, err := h.services.Comment.Create(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to create comment")
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (h *CommentHandler) GetPostComments(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	comments, err := h.services.Comment.ListByPost(c.Request.Context(), postID, userID, page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get comments")
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.UpdateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.services.Comment.Update(c.Request.Context(), id, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to update comment")
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Comment.Delete(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to delete comment")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
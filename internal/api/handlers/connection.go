@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type ConnectionHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewConnectionHandler(services *service.Services, log *logger.Logger) *ConnectionHandler {
+	return &ConnectionHandler{services: services, logger: log}
+}
+
+type ConnectionRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+func (h *ConnectionHandler) SendConnectionRequest(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var req ConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	connection, err := h.services.Connection.Send(c.Request.Context(), userID, req.TargetID)
+	if err != nil {
+		respondError(c, err, "Failed to send connection request")
+		return
+	}
+	c.JSON(http.StatusCreated, connection)
+}
+
+func (h *ConnectionHandler) GetConnections(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	connections, err := h.services.Connection.List(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get connections"})
+		return
+	}
+	c.JSON(http.StatusOK, connections)
+}
+
+func (h *ConnectionHandler) GetPendingRequests(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	connections, err := h.services.Connection.ListPending(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pending requests"})
+		return
+	}
+	c.JSON(http.StatusOK, connections)
+}
+
+func (h *ConnectionHandler) AcceptConnection(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	connection, err := h.services.Connection.Accept(c.Request.Context(), id, userID)
+	if err != nil {
+		respondError(c, err, "Failed to accept connection")
+		return
+	}
+	c.JSON(http.StatusOK, connection)
+}
+
+func (h *ConnectionHandler) RejectConnection(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Connection.Reject(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to reject connection")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Connection rejected"})
+}
+
+func (h *ConnectionHandler) RemoveConnection(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Connection.Remove(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to remove connection")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Connection removed"})
+}
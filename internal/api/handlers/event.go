@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type EventHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewEventHandler(services *service.Services, log *logger.Logger) *EventHandler {
+	return &EventHandler{services: services, logger: log}
+}
+
+type RSVPRequest struct {
+	Status string `json:"status" binding:"required,oneof=going maybe not_going"`
+}
+
+func (h *EventHandler) CreateEvent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	groupID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.CreateEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.GroupID = groupID
+	input.UserID = userID
+
+	event, err := h.services.Event.Create(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to create event")
+		return
+	}
+	c.JSON(http.StatusCreated, event)
+}
+
+func (h *EventHandler) GetGroupEvents(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	groupID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	from := time.Now()
+	var to time.Time
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from time"})
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to time"})
+			return
+		}
+		to = t
+	}
+
+	events, err := h.services.Event.ListByGroup(c.Request.Context(), groupID, userID, from, to, page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get events")
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+func (h *EventHandler) GetEvent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	event, err := h.services.Event.GetByID(c.Request.Context(), id, userID)
+	if err != nil {
+		respondError(c, err, "Failed to get event")
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+func (h *EventHandler) UpdateEvent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.UpdateEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := h.services.Event.Update(c.Request.Context(), id, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to update event")
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+func (h *EventHandler) DeleteEvent(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Event.Delete(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to delete event")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Event cancelled"})
+}
+
+func (h *EventHandler) RSVP(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req RSVPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rsvp, err := h.services.Event.RSVP(c.Request.Context(), id, userID, req.Status)
+	if err != nil {
+		respondError(c, err, "Failed to RSVP")
+		return
+	}
+	c.JSON(http.StatusOK, rsvp)
+}
+
+func (h *EventHandler) GetAttendees(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attendees, err := h.services.Event.GetAttendees(c.Request.Context(), id, userID)
+	if err != nil {
+		respondError(c, err, "Failed to get attendees")
+		return
+	}
+	c.JSON(http.StatusOK, attendees)
+}
+
+// GetGroupCalendar serves the group's iCalendar feed. Calendar clients
+// cannot send a bearer token, so the feed is authenticated by the user's
+// calendar token in ?token= instead.
+func (h *EventHandler) GetGroupCalendar(c *gin.Context) {
+	userID, ok := h.calendarUser(c)
+	if !ok {
+		return
+	}
+	groupID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.services.Event.WriteGroupCalendar(c.Request.Context(), &buf, groupID, userID); err != nil {
+		respondError(c, err, "Failed to export calendar")
+		return
+	}
+	c.Data(http.StatusOK, calendarContentType, buf.Bytes())
+}
+
+// GetMyCalendar serves the personal iCalendar feed of the user whose
+// calendar token is in ?token=.
+func (h *EventHandler) GetMyCalendar(c *gin.Context) {
+	userID, ok := h.calendarUser(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.services.Event.WriteUserCalendar(c.Request.Context(), &buf, userID); err != nil {
+		respondError(c, err, "Failed to export calendar")
+		return
+	}
+	c.Data(http.StatusOK, calendarContentType, buf.Bytes())
+}
+
+// RotateCalendarToken issues the current user a new calendar token,
+// invalidating the feed URLs built from the previous one.
+func (h *EventHandler) RotateCalendarToken(c *gin.Context) {
+	token, err := h.services.Event.RotateCalendarToken(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to create calendar token")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+func (h *EventHandler) RevokeCalendarToken(c *gin.Context) {
+	if err := h.services.Event.RevokeCalendarToken(c.Request.Context(), c.GetUint("user_id")); err != nil {
+		respondError(c, err, "Failed to revoke calendar token")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar token revoked"})
+}
+
+// calendarUser resolves the calendar token in ?token= to its user, writing a
+// 401 response if it is missing or revoked.
+func (h *EventHandler) calendarUser(c *gin.Context) (uint, bool) {
+	userID, err := h.services.Event.CalendarTokenUser(c.Request.Context(), c.Query("token"))
+	if errors.Is(err, service.ErrCalendarToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid calendar token"})
+		return 0, false
+	}
+	if err != nil {
+		respondError(c, err, "Failed to export calendar")
+		return 0, false
+	}
+	return userID, true
+}
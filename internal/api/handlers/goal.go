@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type GoalHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewGoalHandler(services *service.Services, log *logger.Logger) *GoalHandler {
+	return &GoalHandler{services: services, logger: log}
+}
+
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	var input service.CreateGoalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.UserID = c.GetUint("user_id")
+
+	goal, err := h.services.Goal.Create(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to create goal")
+		return
+	}
+	c.JSON(http.StatusCreated, goal)
+}
+
+// GetGoals lists the current user's goals, optionally with one ?status=.
+func (h *GoalHandler) GetGoals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	goals, err := h.services.Goal.List(c.Request.Context(), c.GetUint("user_id"), c.Query("status"), page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get goals")
+		return
+	}
+	c.JSON(http.StatusOK, goals)
+}
+
+func (h *GoalHandler) GetMentoringGoals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	goals, err := h.services.Goal.ListMentoring(c.Request.Context(), c.GetUint("user_id"), c.Query("status"), page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get goals")
+		return
+	}
+	c.JSON(http.StatusOK, goals)
+}
+
+func (h *GoalHandler) GetReportGoals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	goals, err := h.services.Goal.ListReports(c.Request.Context(), c.GetUint("user_id"), c.Query("status"), page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get goals")
+		return
+	}
+	c.JSON(http.StatusOK, goals)
+}
+
+func (h *GoalHandler) GetGoal(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	goal, err := h.services.Goal.Get(c.Request.Context(), id, c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get goal")
+		return
+	}
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.UpdateGoalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, err := h.services.Goal.Update(c.Request.Context(), id, c.GetUint("user_id"), input)
+	if err != nil {
+		respondError(c, err, "Failed to update goal")
+		return
+	}
+	c.JSON(http.StatusOK, goal)
+}
+
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Goal.Delete(c.Request.Context(), id, c.GetUint("user_id")); err != nil {
+		respondError(c, err, "Failed to delete goal")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted"})
+}
+
+func (h *GoalHandler) RemoveMentor(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Goal.RemoveMentor(c.Request.Context(), id, c.GetUint("user_id")); err != nil {
+		respondError(c, err, "Failed to remove mentor")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Mentor removed"})
+}
+
+func (h *GoalHandler) CheckIn(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.GoalCheckInInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	checkIn, err := h.services.Goal.CheckIn(c.Request.Context(), id, c.GetUint("user_id"), input)
+	if err != nil {
+		respondError(c, err, "Failed to check in")
+		return
+	}
+	c.JSON(http.StatusCreated, checkIn)
+}
@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type GroupHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewGroupHandler(services *service.Services, log *logger.Logger) *GroupHandler {
+	return &GroupHandler{services: services, logger: log}
+}
+
+type JoinGroupRequest struct {
+	Message string `json:"message" binding:"max=500"`
+}
+
+type InviteToGroupRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+type ChangeGroupRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin moderator member"`
+}
+
+type BanGroupMemberRequest struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+type TransferGroupOwnershipRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var input service.CreateGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.UserID = userID
+
+	group, err := h.services.Group.Create(c.Request.Context(), input)
+	if err != nil {
+		h.logger.Error("Failed to create group", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+		return
+	}
+	c.JSON(http.StatusCreated, group)
+}
+
+func (h *GroupHandler) GetGroups(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	mine, _ := strconv.ParseBool(c.DefaultQuery("mine", "false"))
+
+	groups, err := h.services.Group.List(c.Request.Context(), service.ListGroupsInput{
+		ViewerID: userID,
+		Query:    c.Query("q"),
+		Mine:     mine,
+		Page:     page,
+		Limit:    limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get groups"})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+func (h *GroupHandler) GetGroupByID(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	group, err := h.services.Group.GetByID(c.Request.Context(), id, userID)
+	if err != nil {
+		respondError(c, err, "Failed to get group")
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.UpdateGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, err := h.services.Group.Update(c.Request.Context(), id, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to update group")
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.Delete(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to delete group")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted"})
+}
+
+func (h *GroupHandler) JoinGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req JoinGroupRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	joinRequest, err := h.services.Group.Join(c.Request.Context(), id, userID, req.Message)
+	if err != nil {
+		respondError(c, err, "Failed to join group")
+		return
+	}
+	if joinRequest != nil {
+		c.JSON(http.StatusAccepted, joinRequest)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Joined group"})
+}
+
+func (h *GroupHandler) LeaveGroup(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.Leave(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to leave group")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Left group"})
+}
+
+func (h *GroupHandler) GetGroupMembers(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	members, err := h.services.Group.GetMembers(c.Request.Context(), id, userID, page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get group members")
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *GroupHandler) ChangeMemberRole(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	var req ChangeGroupRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.services.Group.ChangeRole(c.Request.Context(), id, userID, targetID, req.Role)
+	if err != nil {
+		respondError(c, err, "Failed to change member role")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *GroupHandler) KickMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.Kick(c.Request.Context(), id, userID, targetID); err != nil {
+		respondError(c, err, "Failed to remove member")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+func (h *GroupHandler) BanMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req BanGroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Group.Ban(c.Request.Context(), id, userID, req.UserID, req.Reason); err != nil {
+		respondError(c, err, "Failed to ban member")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member banned"})
+}
+
+func (h *GroupHandler) UnbanMember(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.Unban(c.Request.Context(), id, userID, targetID); err != nil {
+		respondError(c, err, "Failed to unban member")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member unbanned"})
+}
+
+func (h *GroupHandler) TransferOwnership(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req TransferGroupOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Group.TransferOwnership(c.Request.Context(), id, userID, req.UserID); err != nil {
+		respondError(c, err, "Failed to transfer ownership")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred"})
+}
+
+func (h *GroupHandler) GetJoinRequests(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	reqs, err := h.services.Group.ListJoinRequests(c.Request.Context(), id, userID, page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get join requests")
+		return
+	}
+	c.JSON(http.StatusOK, reqs)
+}
+
+func (h *GroupHandler) ApproveJoinRequest(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	requestID, ok := parseIDParam(c, "request_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.ApproveJoinRequest(c.Request.Context(), id, requestID, userID); err != nil {
+		respondError(c, err, "Failed to approve join request")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Join request approved"})
+}
+
+func (h *GroupHandler) DeclineJoinRequest(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	requestID, ok := parseIDParam(c, "request_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.DeclineJoinRequest(c.Request.Context(), id, requestID, userID); err != nil {
+		respondError(c, err, "Failed to decline join request")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Join request declined"})
+}
+
+func (h *GroupHandler) InviteUser(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req InviteToGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv, err := h.services.Group.InviteUser(c.Request.Context(), id, userID, req.UserID)
+	if err != nil {
+		respondError(c, err, "Failed to invite user")
+		return
+	}
+	c.JSON(http.StatusCreated, inv)
+}
+
+func (h *GroupHandler) GetMyInvitations(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	invs, err := h.services.Group.ListInvitations(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get invitations"})
+		return
+	}
+	c.JSON(http.StatusOK, invs)
+}
+
+func (h *GroupHandler) AcceptInvitation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "invitation_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.AcceptInvitation(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to accept invitation")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted"})
+}
+
+func (h *GroupHandler) DeclineInvitation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "invitation_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.DeclineInvitation(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to decline invitation")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+func (h *GroupHandler) CreateInviteLink(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input service.CreateInviteLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.services.Group.CreateInviteLink(c.Request.Context(), id, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to create invite link")
+		return
+	}
+	c.JSON(http.StatusCreated, link)
+}
+
+func (h *GroupHandler) GetInviteLinks(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	links, err := h.services.Group.ListInviteLinks(c.Request.Context(), id, userID)
+	if err != nil {
+		respondError(c, err, "Failed to get invite links")
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+func (h *GroupHandler) RevokeInviteLink(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	linkID, ok := parseIDParam(c, "link_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Group.RevokeInviteLink(c.Request.Context(), id, linkID, userID); err != nil {
+		respondError(c, err, "Failed to revoke invite link")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invite link revoked"})
+}
+
+func (h *GroupHandler) JoinWithInviteLink(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	group, err := h.services.Group.JoinWithLink(c.Request.Context(), c.Param("token"), userID)
+	if err != nil {
+		respondError(c, err, "Failed to join group")
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
 	"github.com/vern/skillflow/internal/config"
+	"github.com/vern/skillflow/internal/realtime"
 	"github.com/vern/skillflow/internal/service"
 	"github.com/vern/skillflow/pkg/logger"
+	"gorm.io/gorm"
 )
 
 type Handlers struct {
@@ -16,13 +24,17 @@ type Handlers struct {
 	Notification *NotificationHandler
 	Message      *MessageHandler
 	Group        *GroupHandler
+	Event        *EventHandler
 	Skill        *SkillHandler
+	Goal         *GoalHandler
+	Mentorship   *MentorshipHandler
 	File         *FileHandler
 	WebSocket    *WebSocketHandler
+	Stream       *StreamHandler
 	Admin        *AdminHandler
 }
 
-func NewHandlers(services *service.Services, cfg *config.Config, log *logger.Logger) *Handlers {
+func NewHandlers(services *service.Services, hub *realtime.Hub, cfg *config.Config, log *logger.Logger) *Handlers {
 	return &Handlers{
 		Auth:         NewAuthHandler(services, cfg, log),
 		User:         NewUserHandler(services, log),
@@ -33,9 +45,65 @@ func NewHandlers(services *service.Services, cfg *config.Config, log *logger.Log
 		Notification: NewNotificationHandler(services, log),
 		Message:      NewMessageHandler(services, log),
 		Group:        NewGroupHandler(services, log),
+		Event:        NewEventHandler(services, log),
 		Skill:        NewSkillHandler(services, log),
+		Goal:         NewGoalHandler(services, log),
+		Mentorship:   NewMentorshipHandler(services, log),
 		File:         NewFileHandler(services, log),
-		WebSocket:    NewWebSocketHandler(services, log),
+		WebSocket:    NewWebSocketHandler(services, hub, log),
+		Stream:       NewStreamHandler(hub, log),
 		Admin:        NewAdminHandler(services, log),
 	}
 }
+
+// parseIDParam reads a numeric path parameter and writes a 400 response when
+// it is malformed.
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseIDList reads a comma-separated list of IDs from the query string and
+// writes a 400 response when it is missing or malformed.
+func parseIDList(c *gin.Context, name string) ([]uint, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " is required"})
+		return nil, false
+	}
+	parts := strings.Split(raw, ",")
+	ids := make([]uint, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+			return nil, false
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, true
+}
+
+// respondError maps service errors to HTTP status codes. Internal errors are
+// hidden behind the fallback message.
+func respondError(c *gin.Context, err error, fallback string) {
+	var status int
+	switch {
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, service.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrInvalidInput):
+		status = http.StatusBadRequest
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type MentorshipHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewMentorshipHandler(services *service.Services, log *logger.Logger) *MentorshipHandler {
+	return &MentorshipHandler{services: services, logger: log}
+}
+
+func (h *MentorshipHandler) GetOffers(c *gin.Context) {
+	offers, err := h.services.Mentorship.ListOffers(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get mentor offers")
+		return
+	}
+	c.JSON(http.StatusOK, offers)
+}
+
+// SetOffer opts the current user in as a mentor for :skill_id or updates
+// the offer.
+func (h *MentorshipHandler) SetOffer(c *gin.Context) {
+	skillID, ok := parseIDParam(c, "skill_id")
+	if !ok {
+		return
+	}
+	var input service.MentorOfferInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, err := h.services.Mentorship.SetOffer(c.Request.Context(), c.GetUint("user_id"), skillID, input)
+	if err != nil {
+		respondError(c, err, "Failed to save mentor offer")
+		return
+	}
+	c.JSON(http.StatusOK, offer)
+}
+
+func (h *MentorshipHandler) DeleteOffer(c *gin.Context) {
+	skillID, ok := parseIDParam(c, "skill_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Mentorship.DeleteOffer(c.Request.Context(), c.GetUint("user_id"), skillID); err != nil {
+		respondError(c, err, "Failed to delete mentor offer")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Mentor offer deleted"})
+}
+
+// GetSuggestions ranks mentors for ?skill_id=.
+func (h *MentorshipHandler) GetSuggestions(c *gin.Context) {
+	skillID, err := strconv.ParseUint(c.Query("skill_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill_id"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	suggestions, err := h.services.Mentorship.Suggest(c.Request.Context(), c.GetUint("user_id"), uint(skillID), page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to suggest mentors")
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (h *MentorshipHandler) RequestMentorship(c *gin.Context) {
+	var input service.RequestMentorshipInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mentorship, err := h.services.Mentorship.Request(c.Request.Context(), c.GetUint("user_id"), input)
+	if err != nil {
+		respondError(c, err, "Failed to request mentorship")
+		return
+	}
+	c.JSON(http.StatusCreated, mentorship)
+}
+
+// GetMentorships lists the current user's mentorships, optionally filtered
+// by ?role=mentor|mentee and ?status=.
+func (h *MentorshipHandler) GetMentorships(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	mentorships, err := h.services.Mentorship.List(c.Request.Context(), c.GetUint("user_id"),
+		c.Query("role"), c.Query("status"), page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get mentorships")
+		return
+	}
+	c.JSON(http.StatusOK, mentorships)
+}
+
+func (h *MentorshipHandler) GetMentorship(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	mentorship, err := h.services.Mentorship.Get(c.Request.Context(), id, c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get mentorship")
+		return
+	}
+	c.JSON(http.StatusOK, mentorship)
+}
+
+func (h *MentorshipHandler) AcceptMentorship(c *gin.Context) {
+	h.changeStatus(c, h.services.Mentorship.Accept, "Failed to accept mentorship")
+}
+
+func (h *MentorshipHandler) DeclineMentorship(c *gin.Context) {
+	h.changeStatus(c, h.services.Mentorship.Decline, "Failed to decline mentorship")
+}
+
+func (h *MentorshipHandler) PauseMentorship(c *gin.Context) {
+	h.changeStatus(c, h.services.Mentorship.Pause, "Failed to pause mentorship")
+}
+
+func (h *MentorshipHandler) ResumeMentorship(c *gin.Context) {
+	h.changeStatus(c, h.services.Mentorship.Resume, "Failed to resume mentorship")
+}
+
+func (h *MentorshipHandler) EndMentorship(c *gin.Context) {
+	h.changeStatus(c, h.services.Mentorship.End, "Failed to end mentorship")
+}
+
+// changeStatus applies a status change to mentorship :id on behalf of the
+// current user.
+func (h *MentorshipHandler) changeStatus(c *gin.Context,
+	change func(ctx context.Context, id, userID uint) (*models.Mentorship, error), fallback string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	mentorship, err := change(c.Request.Context(), id, c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, fallback)
+		return
+	}
+	c.JSON(http.StatusOK, mentorship)
+}
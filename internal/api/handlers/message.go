@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type MessageHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewMessageHandler(services *service.Services, log *logger.Logger) *MessageHandler {
+	return &MessageHandler{services: services, logger: log}
+}
+
+type MarkConversationReadRequest struct {
+	MessageID uint `json:"message_id"`
+}
+
+type AddParticipantsRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1"`
+}
+
+type MessageReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=32"`
+}
+
+type ChangeParticipantRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member"`
+}
+
+func (h *MessageHandler) SendMessage(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var input service.SendMessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.SenderID = userID
+
+	message, err := h.services.Message.Send(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to send message")
+		return
+	}
+	c.JSON(http.StatusCreated, message)
+}
+
+func (h *MessageHandler) GetConversations(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	conversations, err := h.services.Message.GetConversations(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get conversations"})
+		return
+	}
+	c.JSON(http.StatusOK, conversations)
+}
+
+func (h *MessageHandler) GetConversation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	peerID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+	beforeID, _ := strconv.ParseUint(c.DefaultQuery("before_id", "0"), 10, 32)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	messages, err := h.services.Message.GetConversation(c.Request.Context(), userID, peerID, uint(beforeID), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get conversation"})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+func (h *MessageHandler) MarkAsRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Message.MarkAsRead(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to mark message as read")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Message marked as read"})
+}
+
+func (h *MessageHandler) EditMessage(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.EditMessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := h.services.Message.Edit(c.Request.Context(), id, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to edit message")
+		return
+	}
+	c.JSON(http.StatusOK, message)
+}
+
+// DeleteMessage deletes a message for everyone, or only for the caller with
+// ?for=me.
+func (h *MessageHandler) DeleteMessage(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var err error
+	switch c.DefaultQuery("for", "everyone") {
+	case "everyone":
+		err = h.services.Message.Delete(c.Request.Context(), id, userID)
+	case "me":
+		err = h.services.Message.DeleteForMe(c.Request.Context(), id, userID)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "for must be me or everyone"})
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Failed to delete message")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted"})
+}
+
+func (h *MessageHandler) AddReaction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req MessageReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Message.AddReaction(c.Request.Context(), id, userID, req.Emoji); err != nil {
+		respondError(c, err, "Failed to add reaction")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction added"})
+}
+
+func (h *MessageHandler) RemoveReaction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	emoji := c.Query("emoji")
+	if emoji == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "emoji is required"})
+		return
+	}
+
+	if err := h.services.Message.RemoveReaction(c.Request.Context(), id, userID, emoji); err != nil {
+		respondError(c, err, "Failed to remove reaction")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction removed"})
+}
+
+func (h *MessageHandler) CreateConversation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var input service.CreateConversationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.CreatorID = userID
+
+	conversation, err := h.services.Message.CreateConversation(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to create conversation")
+		return
+	}
+	c.JSON(http.StatusCreated, conversation)
+}
+
+func (h *MessageHandler) GetConversationByID(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	conversation, err := h.services.Message.GetConversationByID(c.Request.Context(), id, userID)
+	if err != nil {
+		respondError(c, err, "Failed to get conversation")
+		return
+	}
+	c.JSON(http.StatusOK, conversation)
+}
+
+func (h *MessageHandler) UpdateConversation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.UpdateConversationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conversation, err := h.services.Message.UpdateConversation(c.Request.Context(), id, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to update conversation")
+		return
+	}
+	c.JSON(http.StatusOK, conversation)
+}
+
+func (h *MessageHandler) GetConversationMessages(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	beforeID, _ := strconv.ParseUint(c.DefaultQuery("before_id", "0"), 10, 32)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	messages, err := h.services.Message.GetMessages(c.Request.Context(), id, userID, uint(beforeID), limit)
+	if err != nil {
+		respondError(c, err, "Failed to get messages")
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+func (h *MessageHandler) PostToConversation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.PostMessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := h.services.Message.PostToConversation(c.Request.Context(), id, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to send message")
+		return
+	}
+	c.JSON(http.StatusCreated, message)
+}
+
+func (h *MessageHandler) MarkConversationRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req MarkConversationReadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := h.services.Message.MarkConversationRead(c.Request.Context(), id, userID, req.MessageID); err != nil {
+		respondError(c, err, "Failed to mark conversation as read")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Conversation marked as read"})
+}
+
+func (h *MessageHandler) AddParticipants(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req AddParticipantsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conversation, err := h.services.Message.AddParticipants(c.Request.Context(), id, userID, req.UserIDs)
+	if err != nil {
+		respondError(c, err, "Failed to add participants")
+		return
+	}
+	c.JSON(http.StatusOK, conversation)
+}
+
+func (h *MessageHandler) RemoveParticipant(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Message.RemoveParticipant(c.Request.Context(), id, userID, targetID); err != nil {
+		respondError(c, err, "Failed to remove participant")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Participant removed"})
+}
+
+func (h *MessageHandler) ChangeParticipantRole(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+	var req ChangeParticipantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.services.Message.ChangeParticipantRole(c.Request.Context(), id, userID, targetID, req.Role); err != nil {
+		respondError(c, err, "Failed to change participant role")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Participant role updated"})
+}
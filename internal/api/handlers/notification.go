@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type NotificationHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewNotificationHandler(services *service.Services, log *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{services: services, logger: log}
+}
+
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	unreadOnly, _ := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+
+	notifications, err := h.services.Notification.List(c.Request.Context(), userID, unreadOnly, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Notification.MarkRead(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to mark notification as read")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.services.Notification.MarkAllRead(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
+}
+
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	count, err := h.services.Notification.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unread count"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	preferences, err := h.services.Notification.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var input service.UpdatePreferencesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := h.services.Notification.UpdatePreferences(c.Request.Context(), userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to update notification preferences")
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) GetPushKey(c *gin.Context) {
+	key, err := h.services.Notification.PushPublicKey()
+	if err != nil {
+		respondError(c, err, "Failed to get push key")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"public_key": key})
+}
+
+func (h *NotificationHandler) GetPushSubscriptions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	subscriptions, err := h.services.Notification.ListSubscriptions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get push subscriptions"})
+		return
+	}
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *NotificationHandler) Subscribe(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var input service.PushSubscriptionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.services.Notification.Subscribe(c.Request.Context(), userID, input, c.Request.UserAgent())
+	if err != nil {
+		respondError(c, err, "Failed to save push subscription")
+		return
+	}
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *NotificationHandler) Unsubscribe(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Notification.Unsubscribe(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err, "Failed to delete push subscription")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Push subscription deleted"})
+}
@@ -8,217 +8,6 @@ import (
 	"github.com/vern/skillflow/pkg/logger"
 )
 
-type CommentHandler struct {
-	services *service.Services
-	logger   *logger.Logger
-}
-
-func NewCommentHandler(services *service.Services, log *logger.Logger) *CommentHandler {
-	return &CommentHandler{services: services, logger: log}
-}
-
-func (h *CommentHandler) CreateComment(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Create comment"})
-}
-
-func (h *CommentHandler) GetPostComments(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get post comments"})
-}
-
-func (h *CommentHandler) UpdateComment(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Update comment"})
-}
-
-func (h *CommentHandler) DeleteComment(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Delete comment"})
-}
-
-type ReactionHandler struct {
-	services *service.Services
-	logger   *logger.Logger
-}
-
-func NewReactionHandler(services *service.Services, log *logger.Logger) *ReactionHandler {
-	return &ReactionHandler{services: services, logger: log}
-}
-
-func (h *ReactionHandler) AddReaction(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Add reaction"})
-}
-
-func (h *ReactionHandler) RemoveReaction(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Remove reaction"})
-}
-
-func (h *ReactionHandler) GetReactions(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get reactions"})
-}
-
-func (h *ReactionHandler) AddCommentReaction(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Add comment reaction"})
-}
-
-func (h *ReactionHandler) RemoveCommentReaction(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Remove comment reaction"})
-}
-
-type ConnectionHandler struct {
-	services *service.Services
-	logger   *logger.Logger
-}
-
-func NewConnectionHandler(services *service.Services, log *logger.Logger) *ConnectionHandler {
-	return &ConnectionHandler{services: services, logger: log}
-}
-
-func (h *ConnectionHandler) SendConnectionRequest(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Send connection request"})
-}
-
-func (h *ConnectionHandler) GetConnections(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get connections"})
-}
-
-func (h *ConnectionHandler) GetPendingRequests(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get pending requests"})
-}
-
-func (h *ConnectionHandler) AcceptConnection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Accept connection"})
-}
-
-func (h *ConnectionHandler) RejectConnection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Reject connection"})
-}
-
-func (h *ConnectionHandler) RemoveConnection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Remove connection"})
-}
-
-type NotificationHandler struct {
-	services *service.Services
-	logger   *logger.Logger
-}
-
-func NewNotificationHandler(services *service.Services, log *logger.Logger) *NotificationHandler {
-	return &NotificationHandler{services: services, logger: log}
-}
-
-func (h *NotificationHandler) GetNotifications(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get notifications"})
-}
-
-func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Mark as read"})
-}
-
-func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Mark all as read"})
-}
-
-func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get unread count"})
-}
-
-type MessageHandler struct {
-	services *service.Services
-	logger   *logger.Logger
-}
-
-func NewMessageHandler(services *service.Services, log *logger.Logger) *MessageHandler {
-	return &MessageHandler{services: services, logger: log}
-}
-
-func (h *MessageHandler) SendMessage(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Send message"})
-}
-
-func (h *MessageHandler) GetConversations(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get conversations"})
-}
-
-func (h *MessageHandler) GetConversation(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get conversation"})
-}
-
-func (h *MessageHandler) MarkAsRead(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Mark message as read"})
-}
-
-type GroupHandler struct {
-	services *service.Services
-	logger   *logger.Logger
-}
-
-func NewGroupHandler(services *service.Services, log *logger.Logger) *GroupHandler {
-	return &GroupHandler{services: services, logger: log}
-}
-
-func (h *GroupHandler) CreateGroup(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Create group"})
-}
-
-func (h *GroupHandler) GetGroups(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get groups"})
-}
-
-func (h *GroupHandler) GetGroupByID(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get group by ID"})
-}
-
-func (h *GroupHandler) UpdateGroup(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Update group"})
-}
-
-func (h *GroupHandler) DeleteGroup(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Delete group"})
-}
-
-func (h *GroupHandler) JoinGroup(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Join group"})
-}
-
-func (h *GroupHandler) LeaveGroup(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Leave group"})
-}
-
-func (h *GroupHandler) GetGroupMembers(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get group members"})
-}
-
-type SkillHandler struct {
-	services *service.Services
-	logger   *logger.Logger
-}
-
-func NewSkillHandler(services *service.Services, log *logger.Logger) *SkillHandler {
-	return &SkillHandler{services: services, logger: log}
-}
-
-func (h *SkillHandler) GetSkills(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Get skills"})
-}
-
-func (h *SkillHandler) CreateSkill(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Create skill"})
-}
-
-func (h *SkillHandler) AddUserSkill(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Add user skill"})
-}
-
-func (h *SkillHandler) RemoveUserSkill(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Remove user skill"})
-}
-
-func (h *SkillHandler) UpdateUserSkill(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Update user skill"})
-}
-
-func (h *SkillHandler) EndorseSkill(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Endorse skill"})
-}
-
 type FileHandler struct {
 	services *service.Services
 	logger   *logger.Logger
@@ -240,19 +29,6 @@ func (h *FileHandler) DeleteFile(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Delete file"})
 }
 
-type WebSocketHandler struct {
-	services *service.Services
-	logger   *logger.Logger
-}
-
-func NewWebSocketHandler(services *service.Services, log *logger.Logger) *WebSocketHandler {
-	return &WebSocketHandler{services: services, logger: log}
-}
-
-func (h *WebSocketHandler) HandleConnection(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "WebSocket connection"})
-}
-
 type AdminHandler struct {
 	services *service.Services
 	logger   *logger.Logger
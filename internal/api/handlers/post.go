@@ -29,7 +29,7 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 
 	post, err := h.services.Post.Create(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
+		respondError(c, err, "Failed to create post")
 		return
 	}
 	c.JSON(http.StatusCreated, post)
@@ -55,7 +55,7 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 		return
 	}
 
-	post, err := h.services.Post.GetByID(c.Request.Context(), uint(id))
+	post, err := h.services.Post.GetByID(c.Request.Context(), uint(id), c.GetUint("user_id"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
@@ -97,7 +97,7 @@ func (h *PostHandler) GetUserPosts(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	posts, err := h.services.Post.GetUserPosts(c.Request.Context(), uint(userID), page, limit)
+	posts, err := h.services.Post.GetUserPosts(c.Request.Context(), uint(userID), c.GetUint("user_id"), page, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get posts"})
 		return
@@ -106,14 +106,79 @@ func (h *PostHandler) GetUserPosts(c *gin.Context) {
 }
 
 func (h *PostHandler) GetGroupPosts(c *gin.Context) {
+	userID := c.GetUint("user_id")
 	groupID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	posts, err := h.services.Post.GetGroupPosts(c.Request.Context(), uint(groupID), page, limit)
+	posts, err := h.services.Post.GetGroupPosts(c.Request.Context(), uint(groupID), userID, page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get group posts"})
+		respondError(c, err, "Failed to get group posts")
 		return
 	}
 	c.JSON(http.StatusOK, posts)
 }
+
+func (h *PostHandler) PinPost(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Post.PinToProfile(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to pin post")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Post pinned"})
+}
+
+func (h *PostHandler) UnpinPost(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Post.UnpinFromProfile(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to unpin post")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Post unpinned"})
+}
+
+func (h *PostHandler) PinGroupPost(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	groupID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	postID, ok := parseIDParam(c, "post_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Post.PinToGroup(c.Request.Context(), groupID, postID, userID); err != nil {
+		respondError(c, err, "Failed to pin post")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Post pinned"})
+}
+
+func (h *PostHandler) UnpinGroupPost(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	groupID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	postID, ok := parseIDParam(c, "post_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Post.UnpinFromGroup(c.Request.Context(), groupID, postID, userID); err != nil {
+		respondError(c, err, "Failed to unpin post")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Post unpinned"})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type ReactionHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewReactionHandler(services *service.Services, log *logger.Logger) *ReactionHandler {
+	return &ReactionHandler{services: services, logger: log}
+}
+
+func (h *ReactionHandler) AddReaction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.ReactInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reaction, err := h.services.Reaction.ReactToPost(c.Request.Context(), postID, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to add reaction")
+		return
+	}
+	c.JSON(http.StatusOK, reaction)
+}
+
+func (h *ReactionHandler) RemoveReaction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Reaction.RemoveFromPost(c.Request.Context(), postID, userID); err != nil {
+		respondError(c, err, "Failed to remove reaction")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction removed"})
+}
+
+func (h *ReactionHandler) GetReactions(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	postID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	summary, err := h.services.Reaction.ListForPost(c.Request.Context(), postID, userID)
+	if err != nil {
+		respondError(c, err, "Failed to get reactions")
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *ReactionHandler) AddCommentReaction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	commentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.ReactInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reaction, err := h.services.Reaction.ReactToComment(c.Request.Context(), commentID, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to add reaction")
+		return
+	}
+	c.JSON(http.StatusOK, reaction)
+}
+
+func (h *ReactionHandler) RemoveCommentReaction(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	commentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Reaction.RemoveFromComment(c.Request.Context(), commentID, userID); err != nil {
+		respondError(c, err, "Failed to remove reaction")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reaction removed"})
+}
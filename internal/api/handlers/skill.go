@@ -0,0 +1,652 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+// maxCatalogSize bounds the body of a skill catalog import.
+const maxCatalogSize = 10 << 20
+
+type SkillHandler struct {
+	services *service.Services
+	logger   *logger.Logger
+}
+
+func NewSkillHandler(services *service.Services, log *logger.Logger) *SkillHandler {
+	return &SkillHandler{services: services, logger: log}
+}
+
+func (h *SkillHandler) GetSkills(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	input := service.ListSkillsInput{
+		Category: c.Query("category"),
+		Query:    c.Query("q"),
+		Page:     page,
+		Limit:    limit,
+	}
+	if v := c.Query("category_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category_id"})
+			return
+		}
+		input.CategoryID = uint(id)
+	}
+	if v := c.Query("parent_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent_id"})
+			return
+		}
+		parentID := uint(id)
+		input.ParentID = &parentID
+	}
+
+	skills, err := h.services.Skill.List(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get skills"})
+		return
+	}
+	c.JSON(http.StatusOK, skills)
+}
+
+func (h *SkillHandler) GetSkill(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	skill, err := h.services.Skill.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err, "Failed to get skill")
+		return
+	}
+	c.JSON(http.StatusOK, skill)
+}
+
+func (h *SkillHandler) Autocomplete(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	suggestions, err := h.services.Skill.Autocomplete(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search skills"})
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// FindExperts searches people by skill, e.g.
+// ?skills=Kubernetes,Go&match=all&min_level=advanced&location=Berlin.
+func (h *SkillHandler) FindExperts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	minYears, _ := strconv.Atoi(c.Query("min_years"))
+	minEndorsements, _ := strconv.Atoi(c.Query("min_endorsements"))
+	verifiedOnly, _ := strconv.ParseBool(c.DefaultQuery("verified_only", "false"))
+
+	input := service.FindExpertsInput{
+		ViewerID:        c.GetUint("user_id"),
+		Match:           c.Query("match"),
+		MinLevel:        c.Query("min_level"),
+		VerifiedOnly:    verifiedOnly,
+		MinYears:        minYears,
+		MinEndorsements: minEndorsements,
+		Department:      c.Query("department"),
+		Location:        c.Query("location"),
+		Page:            page,
+		Limit:           limit,
+	}
+	if v := c.Query("skills"); v != "" {
+		input.Skills = strings.Split(v, ",")
+	}
+	if c.Query("skill_ids") != "" {
+		ids, ok := parseIDList(c, "skill_ids")
+		if !ok {
+			return
+		}
+		input.SkillIDs = ids
+	}
+
+	experts, err := h.services.Skill.FindExperts(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to find experts")
+		return
+	}
+	c.JSON(http.StatusOK, experts)
+}
+
+// GetMatrix returns the skill matrix of ?department= or ?group_id=, as JSON
+// or with ?format=csv as a CSV attachment.
+func (h *SkillHandler) GetMatrix(c *gin.Context) {
+	scope, ok := parseMatrixScope(c)
+	if !ok {
+		return
+	}
+	input := service.SkillMatrixInput{SkillMatrixScope: scope, ViewerID: c.GetUint("user_id")}
+	if c.Query("skill_ids") != "" {
+		ids, ok := parseIDList(c, "skill_ids")
+		if !ok {
+			return
+		}
+		input.SkillIDs = ids
+	}
+	if v := c.Query("category_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category_id"})
+			return
+		}
+		input.CategoryID = uint(id)
+	}
+
+	if c.Query("format") == "csv" {
+		var buf bytes.Buffer
+		if err := h.services.Skill.WriteMatrixCSV(c.Request.Context(), &buf, input); err != nil {
+			respondError(c, err, "Failed to export skill matrix")
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="skill-matrix.csv"`)
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+
+	matrix, err := h.services.Skill.Matrix(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to get skill matrix")
+		return
+	}
+	c.JSON(http.StatusOK, matrix)
+}
+
+func (h *SkillHandler) GetTargets(c *gin.Context) {
+	scope, ok := parseMatrixScope(c)
+	if !ok {
+		return
+	}
+
+	targets, err := h.services.Skill.ListTargets(c.Request.Context(), scope, c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get skill targets")
+		return
+	}
+	c.JSON(http.StatusOK, targets)
+}
+
+func (h *SkillHandler) SetTargets(c *gin.Context) {
+	scope, ok := parseMatrixScope(c)
+	if !ok {
+		return
+	}
+	var input service.SkillTargetsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targets, err := h.services.Skill.SetTargets(c.Request.Context(), scope, c.GetUint("user_id"), input)
+	if err != nil {
+		respondError(c, err, "Failed to set skill targets")
+		return
+	}
+	c.JSON(http.StatusOK, targets)
+}
+
+// parseMatrixScope reads ?department= or ?group_id=.
+func parseMatrixScope(c *gin.Context) (service.SkillMatrixScope, bool) {
+	scope := service.SkillMatrixScope{Department: c.Query("department")}
+	if v := c.Query("group_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group_id"})
+			return scope, false
+		}
+		scope.GroupID = uint(id)
+	}
+	return scope, true
+}
+
+func (h *SkillHandler) GetCategories(c *gin.Context) {
+	categories, err := h.services.Skill.ListCategories(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get skill categories"})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+func (h *SkillHandler) CreateSkill(c *gin.Context) {
+	var input service.CreateSkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skill, err := h.services.Skill.Create(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to create skill")
+		return
+	}
+	c.JSON(http.StatusCreated, skill)
+}
+
+func (h *SkillHandler) GetUserSkills(c *gin.Context) {
+	userID, ok := parseIDParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	skills, err := h.services.Skill.ListForUser(c.Request.Context(), userID, c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get user skills")
+		return
+	}
+	c.JSON(http.StatusOK, skills)
+}
+
+func (h *SkillHandler) AddUserSkill(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var input service.AddUserSkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.UserID = userID
+
+	userSkill, err := h.services.Skill.AddUserSkill(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to add skill")
+		return
+	}
+	c.JSON(http.StatusCreated, userSkill)
+}
+
+func (h *SkillHandler) RemoveUserSkill(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Skill.RemoveUserSkill(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err, "Failed to remove skill")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Skill removed"})
+}
+
+func (h *SkillHandler) UpdateUserSkill(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.UpdateUserSkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userSkill, err := h.services.Skill.UpdateUserSkill(c.Request.Context(), id, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to update skill")
+		return
+	}
+	c.JSON(http.StatusOK, userSkill)
+}
+
+func (h *SkillHandler) EndorseSkill(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	userSkillID, ok := parseIDParam(c, "user_skill_id")
+	if !ok {
+		return
+	}
+	var input service.EndorseSkillInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	endorsement, err := h.services.Skill.Endorse(c.Request.Context(), userSkillID, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to endorse skill")
+		return
+	}
+	c.JSON(http.StatusCreated, endorsement)
+}
+
+// RevokeEndorsement withdraws the current user's endorsement of
+// :user_skill_id.
+func (h *SkillHandler) RevokeEndorsement(c *gin.Context) {
+	userSkillID, ok := parseIDParam(c, "user_skill_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Skill.RevokeEndorsement(c.Request.Context(), userSkillID, c.GetUint("user_id")); err != nil {
+		respondError(c, err, "Failed to revoke endorsement")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Endorsement revoked"})
+}
+
+func (h *SkillHandler) GetEndorsements(c *gin.Context) {
+	userSkillID, ok := parseIDParam(c, "user_skill_id")
+	if !ok {
+		return
+	}
+
+	endorsements, err := h.services.Skill.ListEndorsements(c.Request.Context(), userSkillID, c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get endorsements")
+		return
+	}
+	c.JSON(http.StatusOK, endorsements)
+}
+
+// SetEndorsementVisibility hides an endorsement of the current user's skill
+// from their profile or shows it again.
+func (h *SkillHandler) SetEndorsementVisibility(c *gin.Context) {
+	id, ok := parseIDParam(c, "endorsement_id")
+	if !ok {
+		return
+	}
+	var input service.EndorsementVisibilityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	endorsement, err := h.services.Skill.SetEndorsementHidden(c.Request.Context(), id, c.GetUint("user_id"), *input.Hidden)
+	if err != nil {
+		respondError(c, err, "Failed to update endorsement")
+		return
+	}
+	c.JSON(http.StatusOK, endorsement)
+}
+
+func (h *SkillHandler) AssessSkill(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	userSkillID, ok := parseIDParam(c, "user_skill_id")
+	if !ok {
+		return
+	}
+	var input service.AssessSkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assessment, err := h.services.Skill.Assess(c.Request.Context(), userSkillID, userID, input)
+	if err != nil {
+		respondError(c, err, "Failed to assess skill")
+		return
+	}
+	c.JSON(http.StatusCreated, assessment)
+}
+
+func (h *SkillHandler) GetAssessments(c *gin.Context) {
+	userSkillID, ok := parseIDParam(c, "user_skill_id")
+	if !ok {
+		return
+	}
+
+	assessments, err := h.services.Skill.ListAssessments(c.Request.Context(), userSkillID, c.GetUint("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get assessments")
+		return
+	}
+	c.JSON(http.StatusOK, assessments)
+}
+
+// GetExpiringVerifications lists the verifications the current assessor may
+// renew that expire within ?days= (default 30).
+func (h *SkillHandler) GetExpiringVerifications(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	userSkills, err := h.services.Skill.ListExpiringVerifications(c.Request.Context(), c.GetUint("user_id"), days, page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get expiring verifications")
+		return
+	}
+	c.JSON(http.StatusOK, userSkills)
+}
+
+// Catalog administration
+
+func (h *SkillHandler) UpdateSkill(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.UpdateSkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skill, err := h.services.Skill.Update(c.Request.Context(), id, input)
+	if err != nil {
+		respondError(c, err, "Failed to update skill")
+		return
+	}
+	c.JSON(http.StatusOK, skill)
+}
+
+func (h *SkillHandler) AddSynonym(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.AddSynonymInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	synonym, err := h.services.Skill.AddSynonym(c.Request.Context(), id, input)
+	if err != nil {
+		respondError(c, err, "Failed to add synonym")
+		return
+	}
+	c.JSON(http.StatusCreated, synonym)
+}
+
+func (h *SkillHandler) RemoveSynonym(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	synonymID, ok := parseIDParam(c, "synonym_id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Skill.RemoveSynonym(c.Request.Context(), id, synonymID); err != nil {
+		respondError(c, err, "Failed to remove synonym")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Synonym removed"})
+}
+
+func (h *SkillHandler) MergeSkills(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.MergeSkillsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skill, err := h.services.Skill.Merge(c.Request.Context(), id, input)
+	if err != nil {
+		respondError(c, err, "Failed to merge skills")
+		return
+	}
+	c.JSON(http.StatusOK, skill)
+}
+
+func (h *SkillHandler) CreateCategory(c *gin.Context) {
+	var input service.SkillCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.services.Skill.CreateCategory(c.Request.Context(), input)
+	if err != nil {
+		respondError(c, err, "Failed to create skill category")
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+func (h *SkillHandler) UpdateCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input service.SkillCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.services.Skill.UpdateCategory(c.Request.Context(), id, input)
+	if err != nil {
+		respondError(c, err, "Failed to update skill category")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *SkillHandler) DeleteCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Skill.DeleteCategory(c.Request.Context(), id); err != nil {
+		respondError(c, err, "Failed to delete skill category")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Skill category deleted"})
+}
+
+// ImportCatalog merges a CSV or JSON catalog into the skill catalog. The
+// format comes from ?format or else the Content-Type; ?dry_run=true reports
+// the changes without applying them.
+func (h *SkillHandler) ImportCatalog(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	format := c.Query("format")
+	if format == "" {
+		format = service.CatalogJSON
+		if strings.Contains(c.ContentType(), "csv") {
+			format = service.CatalogCSV
+		}
+	}
+	format, err := service.ParseCatalogFormat(format)
+	if err != nil {
+		respondError(c, err, "Failed to import skill catalog")
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxCatalogSize)
+	report, err := h.services.Skill.ImportCatalog(c.Request.Context(), body, format, dryRun)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Catalog is too large"})
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Failed to import skill catalog")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *SkillHandler) ExportCatalog(c *gin.Context) {
+	format, err := service.ParseCatalogFormat(c.DefaultQuery("format", service.CatalogJSON))
+	if err != nil {
+		respondError(c, err, "Failed to export skill catalog")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.services.Skill.ExportCatalog(c.Request.Context(), &buf, format); err != nil {
+		respondError(c, err, "Failed to export skill catalog")
+		return
+	}
+	contentType := "application/json; charset=utf-8"
+	if format == service.CatalogCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Header("Content-Disposition", `attachment; filename="skills.`+format+`"`)
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// GetAssessors lists assessor grants, optionally filtered by ?user_id= and
+// ?skill_id=.
+func (h *SkillHandler) GetAssessors(c *gin.Context) {
+	var ids [2]uint
+	for i, name := range []string{"user_id", "skill_id"} {
+		if v := c.Query(name); v != "" {
+			id, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+				return
+			}
+			ids[i] = uint(id)
+		}
+	}
+
+	assessors, err := h.services.Skill.ListAssessors(c.Request.Context(), ids[0], ids[1])
+	if err != nil {
+		respondError(c, err, "Failed to get assessors")
+		return
+	}
+	c.JSON(http.StatusOK, assessors)
+}
+
+func (h *SkillHandler) GrantAssessor(c *gin.Context) {
+	var input service.GrantAssessorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assessor, err := h.services.Skill.GrantAssessor(c.Request.Context(), c.GetUint("user_id"), input)
+	if err != nil {
+		respondError(c, err, "Failed to grant assessor")
+		return
+	}
+	c.JSON(http.StatusCreated, assessor)
+}
+
+func (h *SkillHandler) RevokeAssessor(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.Skill.RevokeAssessor(c.Request.Context(), id); err != nil {
+		respondError(c, err, "Failed to revoke assessor")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Assessor revoked"})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/realtime"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+const (
+	// streamKeepalive is how often an idle stream sends a comment so proxies
+	// do not drop it.
+	streamKeepalive = 25 * time.Second
+	// streamRetry is the reconnect delay suggested to EventSource clients.
+	streamRetry = 3 * time.Second
+)
+
+// StreamHandler serves real-time events as Server-Sent Events, for clients
+// behind proxies that block WebSocket upgrades.
+type StreamHandler struct {
+	hub    *realtime.Hub
+	logger *logger.Logger
+}
+
+func NewStreamHandler(hub *realtime.Hub, log *logger.Logger) *StreamHandler {
+	return &StreamHandler{hub: hub, logger: log}
+}
+
+// StreamEvents streams the same events as the WebSocket. Clients resuming
+// with a Last-Event-ID header (or ?last_event_id=) first receive the events
+// they missed, as far as the replay buffer reaches.
+func (h *StreamHandler) StreamEvents(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub, backlog, err := h.hub.Subscribe(c.Request.Context(), userID, lastEventID)
+	if err != nil {
+		h.logger.Error("Failed to open event stream", "user_id", userID, "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Event stream unavailable"})
+		return
+	}
+	defer sub.Close()
+
+	// Streams outlive the server's write timeout.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("Failed to clear stream write deadline", "error", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry.Milliseconds()); err != nil {
+		return
+	}
+	for _, event := range backlog {
+		if err := writeEvent(c, event); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if !sub.Fresh(event) {
+				continue
+			}
+			if err := writeEvent(c, event); err != nil {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := c.Writer.WriteString(": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeEvent always JSON-encodes the data, as on the WebSocket, so clients
+// can parse every event the same way.
+func writeEvent(c *gin.Context, event realtime.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	return sse.Encode(c.Writer, sse.Event{Id: event.ID, Event: event.Type, Data: json.RawMessage(data)})
+}
@@ -104,7 +104,7 @@ func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
 
 	profile, err := h.services.User.UpdateProfile(c.Request.Context(), uint(id), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		respondError(c, err, "Failed to update profile")
 		return
 	}
 
@@ -126,3 +126,79 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 
 	c.JSON(http.StatusOK, users)
 }
+
+// GetPresence returns the presence of the users listed in ?ids=1,2,3.
+func (h *UserHandler) GetPresence(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	ids, ok := parseIDList(c, "ids")
+	if !ok {
+		return
+	}
+
+	presences, err := h.services.Presence.GetPresence(c.Request.Context(), userID, ids)
+	if err != nil {
+		respondError(c, err, "Failed to get presence")
+		return
+	}
+	c.JSON(http.StatusOK, presences)
+}
+
+func (h *UserHandler) GetSettings(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	settings, err := h.services.User.GetSettings(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *UserHandler) UpdateSettings(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var input service.UpdateSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.services.User.UpdateSettings(c.Request.Context(), userID, input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *UserHandler) BlockUser(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.User.Block(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err, "Failed to block user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked"})
+}
+
+func (h *UserHandler) UnblockUser(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.services.User.Unblock(c.Request.Context(), userID, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked"})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vern/skillflow/internal/realtime"
+	"github.com/vern/skillflow/internal/service"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+type WebSocketHandler struct {
+	services *service.Services
+	hub      *realtime.Hub
+	logger   *logger.Logger
+}
+
+// presenceTimeout bounds presence updates made from connection callbacks.
+const presenceTimeout = 5 * time.Second
+
+// NewWebSocketHandler also registers the handlers for events clients send
+// over the socket and tracks presence from the connection lifecycle.
+func NewWebSocketHandler(services *service.Services, hub *realtime.Hub, log *logger.Logger) *WebSocketHandler {
+	h := &WebSocketHandler{services: services, hub: hub, logger: log}
+	if hub != nil {
+		hub.Handle(realtime.EventTyping, h.typing)
+		hub.Handle(realtime.EventPresence, h.presence)
+		hub.SetLifecycle(realtime.Lifecycle{
+			Connected:    h.trackPresence("connect", services.Presence.Connect),
+			Heartbeat:    h.trackPresence("heartbeat", services.Presence.Heartbeat),
+			Disconnected: h.trackPresence("disconnect", services.Presence.Disconnect),
+		})
+	}
+	return h
+}
+
+func (h *WebSocketHandler) HandleConnection(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if err := h.hub.ServeWS(c.Writer, c.Request, userID); err != nil {
+		// The upgrader has already written an error response.
+		h.logger.Warn("WebSocket upgrade failed", "user_id", userID, "error", err)
+	}
+}
+
+// trackPresence adapts a presence update to a lifecycle callback, logging
+// failures since there is no one to report them to.
+func (h *WebSocketHandler) trackPresence(action string, update func(context.Context, uint, string) error) func(uint, string) {
+	return func(userID uint, connID string) {
+		ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+		defer cancel()
+		if err := update(ctx, userID, connID); err != nil {
+			h.logger.Error("Failed to update presence", "action", action, "user_id", userID, "error", err)
+		}
+	}
+}
+
+type presenceEvent struct {
+	Status string `json:"status"`
+}
+
+func (h *WebSocketHandler) presence(ctx context.Context, userID uint, data json.RawMessage) error {
+	var event presenceEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return errors.New("presence requires status")
+	}
+	return h.services.Presence.SetStatus(ctx, userID, event.Status)
+}
+
+type typingEvent struct {
+	ConversationID uint `json:"conversation_id"`
+}
+
+func (h *WebSocketHandler) typing(ctx context.Context, userID uint, data json.RawMessage) error {
+	var event typingEvent
+	if err := json.Unmarshal(data, &event); err != nil || event.ConversationID == 0 {
+		return errors.New("typing requires conversation_id")
+	}
+	return h.services.Message.Typing(ctx, event.ConversationID, userID)
+}
@@ -5,12 +5,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/vern/skillflow/internal/api/handlers"
 	"github.com/vern/skillflow/internal/config"
+	"github.com/vern/skillflow/internal/realtime"
 	"github.com/vern/skillflow/internal/service"
 	"github.com/vern/skillflow/pkg/logger"
 	"github.com/vern/skillflow/pkg/middleware"
 )
 
-func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logger) *gin.Engine {
+func NewRouter(services *service.Services, hub *realtime.Hub, cfg *config.Config, log *logger.Logger) *gin.Engine {
 	router := gin.New()
 
 	// Global middleware
@@ -30,7 +31,7 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 	}
 
 	// Initialize handlers
-	h := handlers.NewHandlers(services, cfg, log)
+	h := handlers.NewHandlers(services, hub, cfg, log)
 
 	// API v1
 	v1 := router.Group("/api/v1")
@@ -45,6 +46,10 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 			auth.GET("/oidc/callback", h.Auth.OIDCCallback)
 		}
 
+		// Calendar feeds, authenticated by the user's calendar token
+		v1.GET("/groups/:id/events.ics", h.Event.GetGroupCalendar)
+		v1.GET("/events/calendar.ics", h.Event.GetMyCalendar)
+
 		// Protected routes
 		protected := v1.Group("")
 		protected.Use(middleware.AuthMiddleware(cfg.Auth.JWTSecret))
@@ -58,6 +63,11 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 				users.GET("/:id/profile", h.User.GetUserProfile)
 				users.PUT("/:id/profile", h.User.UpdateUserProfile)
 				users.GET("/search", h.User.SearchUsers)
+				users.GET("/presence", h.User.GetPresence)
+				users.GET("/me/settings", h.User.GetSettings)
+				users.PUT("/me/settings", h.User.UpdateSettings)
+				users.POST("/:id/block", h.User.BlockUser)
+				users.DELETE("/:id/block", h.User.UnblockUser)
 			}
 
 			// Post routes
@@ -69,6 +79,8 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 				posts.PUT("/:id", h.Post.UpdatePost)
 				posts.DELETE("/:id", h.Post.DeletePost)
 				posts.GET("/user/:user_id", h.Post.GetUserPosts)
+				posts.POST("/:id/pin", h.Post.PinPost)
+				posts.DELETE("/:id/pin", h.Post.UnpinPost)
 
 				// Comments
 				posts.POST("/:id/comments", h.Comment.CreateComment)
@@ -107,6 +119,13 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 				notifications.PUT("/:id/read", h.Notification.MarkAsRead)
 				notifications.PUT("/read-all", h.Notification.MarkAllAsRead)
 				notifications.GET("/unread/count", h.Notification.GetUnreadCount)
+				notifications.GET("/preferences", h.Notification.GetPreferences)
+				notifications.PUT("/preferences", h.Notification.UpdatePreferences)
+				notifications.GET("/push/key", h.Notification.GetPushKey)
+				notifications.GET("/push/subscriptions", h.Notification.GetPushSubscriptions)
+				notifications.POST("/push/subscriptions", h.Notification.Subscribe)
+				notifications.DELETE("/push/subscriptions/:id", h.Notification.Unsubscribe)
+				notifications.GET("/stream", h.Stream.StreamEvents)
 			}
 
 			// Message routes
@@ -116,6 +135,25 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 				messages.GET("/conversations", h.Message.GetConversations)
 				messages.GET("/conversation/:user_id", h.Message.GetConversation)
 				messages.PUT("/:id/read", h.Message.MarkAsRead)
+				messages.PUT("/:id", h.Message.EditMessage)
+				messages.DELETE("/:id", h.Message.DeleteMessage)
+				messages.POST("/:id/reactions", h.Message.AddReaction)
+				messages.DELETE("/:id/reactions", h.Message.RemoveReaction)
+			}
+
+			// Conversation routes
+			conversations := protected.Group("/conversations")
+			{
+				conversations.POST("", h.Message.CreateConversation)
+				conversations.GET("", h.Message.GetConversations)
+				conversations.GET("/:id", h.Message.GetConversationByID)
+				conversations.PUT("/:id", h.Message.UpdateConversation)
+				conversations.GET("/:id/messages", h.Message.GetConversationMessages)
+				conversations.POST("/:id/messages", h.Message.PostToConversation)
+				conversations.PUT("/:id/read", h.Message.MarkConversationRead)
+				conversations.POST("/:id/participants", h.Message.AddParticipants)
+				conversations.DELETE("/:id/participants/:user_id", h.Message.RemoveParticipant)
+				conversations.PUT("/:id/participants/:user_id/role", h.Message.ChangeParticipantRole)
 			}
 
 			// Group routes
@@ -129,7 +167,39 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 				groups.POST("/:id/join", h.Group.JoinGroup)
 				groups.POST("/:id/leave", h.Group.LeaveGroup)
 				groups.GET("/:id/members", h.Group.GetGroupMembers)
+				groups.PUT("/:id/members/:user_id/role", h.Group.ChangeMemberRole)
+				groups.DELETE("/:id/members/:user_id", h.Group.KickMember)
+				groups.POST("/:id/bans", h.Group.BanMember)
+				groups.DELETE("/:id/bans/:user_id", h.Group.UnbanMember)
+				groups.POST("/:id/transfer", h.Group.TransferOwnership)
+				groups.GET("/:id/requests", h.Group.GetJoinRequests)
+				groups.POST("/:id/requests/:request_id/approve", h.Group.ApproveJoinRequest)
+				groups.POST("/:id/requests/:request_id/decline", h.Group.DeclineJoinRequest)
+				groups.POST("/:id/invitations", h.Group.InviteUser)
+				groups.POST("/:id/invite-links", h.Group.CreateInviteLink)
+				groups.GET("/:id/invite-links", h.Group.GetInviteLinks)
+				groups.DELETE("/:id/invite-links/:link_id", h.Group.RevokeInviteLink)
+				groups.GET("/invitations", h.Group.GetMyInvitations)
+				groups.POST("/invitations/:invitation_id/accept", h.Group.AcceptInvitation)
+				groups.POST("/invitations/:invitation_id/decline", h.Group.DeclineInvitation)
+				groups.POST("/join/:token", h.Group.JoinWithInviteLink)
 				groups.GET("/:id/posts", h.Post.GetGroupPosts)
+				groups.POST("/:id/posts/:post_id/pin", h.Post.PinGroupPost)
+				groups.DELETE("/:id/posts/:post_id/pin", h.Post.UnpinGroupPost)
+				groups.POST("/:id/events", h.Event.CreateEvent)
+				groups.GET("/:id/events", h.Event.GetGroupEvents)
+			}
+
+			// Event routes
+			events := protected.Group("/events")
+			{
+				events.POST("/calendar/token", h.Event.RotateCalendarToken)
+				events.DELETE("/calendar/token", h.Event.RevokeCalendarToken)
+				events.GET("/:id", h.Event.GetEvent)
+				events.PUT("/:id", h.Event.UpdateEvent)
+				events.DELETE("/:id", h.Event.DeleteEvent)
+				events.PUT("/:id/rsvp", h.Event.RSVP)
+				events.GET("/:id/attendees", h.Event.GetAttendees)
 			}
 
 			// Skill routes
@@ -137,10 +207,55 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 			{
 				skills.GET("", h.Skill.GetSkills)
 				skills.POST("", h.Skill.CreateSkill)
+				skills.GET("/autocomplete", h.Skill.Autocomplete)
+				skills.GET("/categories", h.Skill.GetCategories)
+				skills.GET("/experts", h.Skill.FindExperts)
+				skills.GET("/matrix", h.Skill.GetMatrix)
+				skills.GET("/matrix/targets", h.Skill.GetTargets)
+				skills.PUT("/matrix/targets", h.Skill.SetTargets)
+				skills.GET("/user/:user_id", h.Skill.GetUserSkills)
 				skills.POST("/user", h.Skill.AddUserSkill)
 				skills.DELETE("/user/:id", h.Skill.RemoveUserSkill)
 				skills.PUT("/user/:id", h.Skill.UpdateUserSkill)
 				skills.POST("/endorse/:user_skill_id", h.Skill.EndorseSkill)
+				skills.DELETE("/endorse/:user_skill_id", h.Skill.RevokeEndorsement)
+				skills.GET("/endorsements/:user_skill_id", h.Skill.GetEndorsements)
+				skills.PUT("/endorsements/:endorsement_id/visibility", h.Skill.SetEndorsementVisibility)
+				skills.GET("/assessments/expiring", h.Skill.GetExpiringVerifications)
+				skills.GET("/assessments/:user_skill_id", h.Skill.GetAssessments)
+				skills.POST("/assessments/:user_skill_id", h.Skill.AssessSkill)
+				skills.GET("/:id", h.Skill.GetSkill)
+			}
+
+			// Learning goal routes
+			goals := protected.Group("/goals")
+			{
+				goals.GET("", h.Goal.GetGoals)
+				goals.POST("", h.Goal.CreateGoal)
+				goals.GET("/mentoring", h.Goal.GetMentoringGoals)
+				goals.GET("/reports", h.Goal.GetReportGoals)
+				goals.GET("/:id", h.Goal.GetGoal)
+				goals.PUT("/:id", h.Goal.UpdateGoal)
+				goals.DELETE("/:id", h.Goal.DeleteGoal)
+				goals.DELETE("/:id/mentor", h.Goal.RemoveMentor)
+				goals.POST("/:id/check-ins", h.Goal.CheckIn)
+			}
+
+			// Mentorship routes
+			mentorships := protected.Group("/mentorships")
+			{
+				mentorships.GET("", h.Mentorship.GetMentorships)
+				mentorships.POST("", h.Mentorship.RequestMentorship)
+				mentorships.GET("/offers", h.Mentorship.GetOffers)
+				mentorships.PUT("/offers/:skill_id", h.Mentorship.SetOffer)
+				mentorships.DELETE("/offers/:skill_id", h.Mentorship.DeleteOffer)
+				mentorships.GET("/suggestions", h.Mentorship.GetSuggestions)
+				mentorships.GET("/:id", h.Mentorship.GetMentorship)
+				mentorships.PUT("/:id/accept", h.Mentorship.AcceptMentorship)
+				mentorships.PUT("/:id/decline", h.Mentorship.DeclineMentorship)
+				mentorships.PUT("/:id/pause", h.Mentorship.PauseMentorship)
+				mentorships.PUT("/:id/resume", h.Mentorship.ResumeMentorship)
+				mentorships.PUT("/:id/end", h.Mentorship.EndMentorship)
 			}
 
 			// File routes
@@ -166,6 +281,19 @@ func NewRouter(services *service.Services, cfg *config.Config, log *logger.Logge
 			admin.DELETE("/posts/:id", h.Admin.DeletePost)
 			admin.DELETE("/comments/:id", h.Admin.DeleteComment)
 			admin.GET("/stats", h.Admin.GetStats)
+
+			admin.POST("/skills/import", h.Skill.ImportCatalog)
+			admin.GET("/skills/export", h.Skill.ExportCatalog)
+			admin.POST("/skills/categories", h.Skill.CreateCategory)
+			admin.PUT("/skills/categories/:id", h.Skill.UpdateCategory)
+			admin.DELETE("/skills/categories/:id", h.Skill.DeleteCategory)
+			admin.GET("/skills/assessors", h.Skill.GetAssessors)
+			admin.POST("/skills/assessors", h.Skill.GrantAssessor)
+			admin.DELETE("/skills/assessors/:id", h.Skill.RevokeAssessor)
+			admin.PUT("/skills/:id", h.Skill.UpdateSkill)
+			admin.POST("/skills/:id/synonyms", h.Skill.AddSynonym)
+			admin.DELETE("/skills/:id/synonyms/:synonym_id", h.Skill.RemoveSynonym)
+			admin.POST("/skills/:id/merge", h.Skill.MergeSkills)
 		}
 	}
 
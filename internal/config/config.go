@@ -16,6 +16,9 @@ type Config struct {
 	Storage       StorageConfig
 	Elasticsearch ElasticsearchConfig
 	WebSocket     WebSocketConfig
+	Email         EmailConfig
+	WebPush       WebPushConfig
+	Skills        SkillsConfig
 	Monitoring    MonitoringConfig
 	Logging       LoggingConfig
 	CORS          CORSConfig
@@ -81,9 +84,41 @@ type ElasticsearchConfig struct {
 }
 
 type WebSocketConfig struct {
-	ReadBufferSize  int `mapstructure:"read_buffer_size"`
-	WriteBufferSize int `mapstructure:"write_buffer_size"`
-	MaxMessageSize  int `mapstructure:"max_message_size"`
+	ReadBufferSize  int    `mapstructure:"read_buffer_size"`
+	WriteBufferSize int    `mapstructure:"write_buffer_size"`
+	MaxMessageSize  int    `mapstructure:"max_message_size"`
+	Broker          string `mapstructure:"broker"` // redis (default) or local
+}
+
+type EmailConfig struct {
+	Transport  string     `mapstructure:"transport"` // smtp or file (default)
+	From       string     `mapstructure:"from"`
+	BaseURL    string     `mapstructure:"base_url"`    // web app URL used for links
+	FileDir    string     `mapstructure:"file_dir"`    // output directory of the file transport
+	DigestHour int        `mapstructure:"digest_hour"` // local hour at which digests are sent
+	SMTP       SMTPConfig `mapstructure:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// WebPushConfig holds the VAPID key pair (base64url, as printed by
+// cmd/vapid) used to sign Web Push requests. Web Push is disabled while no
+// key is set.
+type WebPushConfig struct {
+	PublicKey  string `mapstructure:"public_key"`
+	PrivateKey string `mapstructure:"private_key"`
+	Subject    string `mapstructure:"subject"` // mailto: or https: contact for push services
+	TTL        int    `mapstructure:"ttl"`     // seconds an undelivered message is kept
+}
+
+type SkillsConfig struct {
+	EndorseConnectionsOnly   bool `mapstructure:"endorse_connections_only"`   // only connections may endorse skills
+	VerificationValidityDays int  `mapstructure:"verification_validity_days"` // days a skill assessment stays valid; 0 never expires
 }
 
 type MonitoringConfig struct {
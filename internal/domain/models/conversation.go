@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Conversation types.
+const (
+	ConversationDirect = "direct"
+	ConversationGroup  = "group"
+)
+
+// Conversation participant roles.
+const (
+	ConversationRoleOwner  = "owner"
+	ConversationRoleAdmin  = "admin"
+	ConversationRoleMember = "member"
+)
+
+// Conversation groups messages between two (direct) or more (group)
+// participants. Direct conversations are unique per pair of users through
+// DirectKey.
+type Conversation struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Type          string         `gorm:"not null;default:'direct'" json:"type"`
+	Title         string         `json:"title"`
+	CreatorID     uint           `gorm:"not null" json:"creator_id"`
+	DirectKey     *string        `gorm:"uniqueIndex" json:"-"`
+	LastMessageID *uint          `json:"last_message_id,omitempty"`
+	LastMessageAt *time.Time     `gorm:"index" json:"last_message_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Participants []ConversationParticipant `gorm:"foreignKey:ConversationID" json:"participants,omitempty"`
+}
+
+// ConversationParticipant tracks membership and the participant's read
+// cursor: every message with an ID up to LastReadMessageID has been read.
+type ConversationParticipant struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	ConversationID    uint      `gorm:"not null;uniqueIndex:idx_conversation_participant" json:"conversation_id"`
+	UserID            uint      `gorm:"not null;uniqueIndex:idx_conversation_participant;index" json:"user_id"`
+	Role              string    `gorm:"not null;default:'member'" json:"role"`
+	LastReadMessageID uint      `gorm:"not null;default:0" json:"last_read_message_id"`
+	JoinedAt          time.Time `json:"joined_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// DirectConversationKey identifies the direct conversation between two users
+// regardless of argument order.
+func DirectConversationKey(a, b uint) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d:%d", a, b)
+}
+
+// MessageReaction is one user's emoji reaction to a message. A user can add
+// several different emoji to the same message.
+type MessageReaction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MessageID uint      `gorm:"not null;uniqueIndex:idx_message_reaction" json:"message_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_message_reaction" json:"user_id"`
+	Emoji     string    `gorm:"not null;uniqueIndex:idx_message_reaction" json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MessageHide records a message deleted for one participant only.
+type MessageHide struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MessageID uint      `gorm:"not null;uniqueIndex:idx_message_hide" json:"message_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_message_hide" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RSVP statuses. Users asking to attend a full event are waitlisted and
+// promoted in order when a spot frees up.
+const (
+	RSVPGoing      = "going"
+	RSVPMaybe      = "maybe"
+	RSVPNotGoing   = "not_going"
+	RSVPWaitlisted = "waitlisted"
+)
+
+// GroupEvent is a meetup organized by a group. StartsAt and EndsAt are
+// stored in UTC; Timezone is the IANA zone the organizer scheduled it in.
+type GroupEvent struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	GroupID        uint           `gorm:"not null;index" json:"group_id"`
+	CreatorID      uint           `gorm:"not null" json:"creator_id"`
+	Title          string         `gorm:"not null" json:"title"`
+	Description    string         `gorm:"type:text" json:"description"`
+	StartsAt       time.Time      `gorm:"not null;index" json:"starts_at"`
+	EndsAt         time.Time      `gorm:"not null" json:"ends_at"`
+	Timezone       string         `gorm:"not null;default:'UTC'" json:"timezone"`
+	Location       string         `json:"location"`
+	MeetingURL     string         `json:"meeting_url"`
+	Capacity       int            `gorm:"not null;default:0" json:"capacity"`
+	ReminderSentAt *time.Time     `json:"-"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Group   *Group      `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+	Creator *User       `gorm:"foreignKey:CreatorID" json:"creator,omitempty"`
+	RSVPs   []EventRSVP `gorm:"foreignKey:EventID" json:"rsvps,omitempty"`
+}
+
+type EventRSVP struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	EventID   uint      `gorm:"not null;uniqueIndex:idx_event_rsvp" json:"event_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_event_rsvp;index" json:"user_id"`
+	Status    string    `gorm:"not null" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Event *GroupEvent `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	User  *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
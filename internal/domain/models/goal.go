@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Learning goal statuses.
+const (
+	GoalStatusActive    = "active"
+	GoalStatusCompleted = "completed"
+	GoalStatusAbandoned = "abandoned"
+)
+
+// LearningGoal is a skill a user is working towards: reaching TargetLevel in
+// Skill by DueDate. Progress is the percentage of the latest check-in. A
+// mentor, if assigned, can follow the goal; a shared goal is also visible to
+// the user's manager. ReminderSentAt records the deadline reminder.
+type LearningGoal struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"not null;index" json:"user_id"`
+	SkillID        uint       `gorm:"not null;index" json:"skill_id"`
+	TargetLevel    string     `gorm:"not null" json:"target_level"`
+	DueDate        time.Time  `gorm:"not null;index" json:"due_date"`
+	Notes          string     `gorm:"type:text" json:"notes"`
+	Status         string     `gorm:"not null;default:'active';index" json:"status"`
+	Progress       int        `gorm:"not null;default:0" json:"progress"`
+	Shared         bool       `gorm:"not null;default:false" json:"shared"`
+	MentorID       *uint      `gorm:"index" json:"mentor_id,omitempty"`
+	ReminderSentAt *time.Time `json:"-"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	User     *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Skill    *Skill        `gorm:"foreignKey:SkillID" json:"skill,omitempty"`
+	Mentor   *User         `gorm:"foreignKey:MentorID" json:"mentor,omitempty"`
+	CheckIns []GoalCheckIn `gorm:"foreignKey:GoalID" json:"check_ins,omitempty"`
+}
+
+// GoalCheckIn is a progress update on a learning goal by its owner or
+// mentor. Progress is only set by the owner's check-ins.
+type GoalCheckIn struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GoalID    uint      `gorm:"not null;index" json:"goal_id"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	Progress  *int      `json:"progress,omitempty"`
+	Note      string    `gorm:"type:text" json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Statuses shared by GroupJoinRequest and GroupInvitation.
+const (
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusDeclined = "declined"
+)
+
+// GroupJoinRequest is created when a user asks to join a private group and
+// is approved or declined by a group admin.
+type GroupJoinRequest struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	GroupID      uint       `gorm:"not null;index" json:"group_id"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	Message      string     `gorm:"type:text" json:"message"`
+	Status       string     `gorm:"not null;default:'pending';index" json:"status"`
+	ReviewedByID *uint      `json:"reviewed_by_id,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Group *Group `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+	User  *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// GroupInvitation invites a specific user, who can accept or decline.
+type GroupInvitation struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	GroupID     uint       `gorm:"not null;index" json:"group_id"`
+	InviterID   uint       `gorm:"not null" json:"inviter_id"`
+	InviteeID   uint       `gorm:"not null;index" json:"invitee_id"`
+	Status      string     `gorm:"not null;default:'pending';index" json:"status"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Group   *Group `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+	Inviter *User  `gorm:"foreignKey:InviterID" json:"inviter,omitempty"`
+	Invitee *User  `gorm:"foreignKey:InviteeID" json:"invitee,omitempty"`
+}
+
+// GroupInviteLink lets anyone holding the token join the group until it
+// expires, runs out of uses (MaxUses 0 means unlimited) or is revoked.
+type GroupInviteLink struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	GroupID     uint       `gorm:"not null;index" json:"group_id"`
+	CreatedByID uint       `gorm:"not null" json:"created_by_id"`
+	Token       string     `gorm:"uniqueIndex;not null" json:"token"`
+	MaxUses     int        `gorm:"not null;default:0" json:"max_uses"`
+	Uses        int        `gorm:"not null;default:0" json:"uses"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Group *Group `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+}
+
+// Usable reports whether the link can still be redeemed at the given time.
+func (l *GroupInviteLink) Usable(now time.Time) bool {
+	if l.RevokedAt != nil {
+		return false
+	}
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return false
+	}
+	return l.MaxUses == 0 || l.Uses < l.MaxUses
+}
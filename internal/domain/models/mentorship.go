@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Mentorship statuses. A mentee's request is pending until the mentor
+// accepts or declines it; either side can then pause, resume or end it.
+const (
+	MentorshipStatusPending  = "pending"
+	MentorshipStatusActive   = "active"
+	MentorshipStatusPaused   = "paused"
+	MentorshipStatusEnded    = "ended"
+	MentorshipStatusDeclined = "declined"
+)
+
+// MentorOffer opts a user in as a mentor for a skill they hold at advanced
+// level or above. Capacity bounds their active and paused mentorships in
+// the skill; an unavailable offer takes no new requests.
+type MentorOffer struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_mentor_offer" json:"user_id"`
+	SkillID   uint      `gorm:"not null;uniqueIndex:idx_mentor_offer;index" json:"skill_id"`
+	Capacity  int       `gorm:"not null;default:1" json:"capacity"`
+	Available bool      `gorm:"not null;default:true" json:"available"`
+	Note      string    `gorm:"type:text" json:"note"`
+	Mentees   int       `gorm:"-" json:"mentees"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User  *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Skill *Skill `gorm:"foreignKey:SkillID" json:"skill,omitempty"`
+}
+
+// Mentorship pairs a mentee with a mentor for one skill. PausedByID records
+// who paused it.
+type Mentorship struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	MentorID   uint       `gorm:"not null;index" json:"mentor_id"`
+	MenteeID   uint       `gorm:"not null;index" json:"mentee_id"`
+	SkillID    uint       `gorm:"not null;index" json:"skill_id"`
+	Status     string     `gorm:"not null;default:'pending';index" json:"status"`
+	Message    string     `gorm:"type:text" json:"message"`
+	PausedByID *uint      `json:"paused_by_id,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Mentor *User  `gorm:"foreignKey:MentorID" json:"mentor,omitempty"`
+	Mentee *User  `gorm:"foreignKey:MenteeID" json:"mentee,omitempty"`
+	Skill  *Skill `gorm:"foreignKey:SkillID" json:"skill,omitempty"`
+}
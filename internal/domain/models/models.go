@@ -16,6 +16,7 @@ type User struct {
 	Role         string         `gorm:"default:'user'" json:"role"`
 	OIDCSubject  string         `gorm:"uniqueIndex" json:"-"`
 	LastLoginAt  *time.Time     `json:"last_login_at"`
+	LastSeenAt   *time.Time     `json:"-"` // exposed through presence, subject to privacy settings
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -28,6 +29,12 @@ type User struct {
 	Skills        []UserSkill    `gorm:"foreignKey:UserID" json:"skills,omitempty"`
 }
 
+// User roles. Admins can use the admin API.
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
 type Profile struct {
 	ID          uint       `gorm:"primaryKey" json:"id"`
 	UserID      uint       `gorm:"uniqueIndex;not null" json:"user_id"`
@@ -39,6 +46,7 @@ type Profile struct {
 	CoverURL    string     `json:"cover_url"`
 	Department  string     `json:"department"`
 	Position    string     `json:"position"`
+	ManagerID   *uint      `gorm:"index" json:"manager_id,omitempty"`
 	Location    string     `json:"location"`
 	Phone       string     `json:"phone"`
 	Birthday    *time.Time `json:"birthday"`
@@ -55,6 +63,7 @@ type Post struct {
 	MediaURLs  string         `gorm:"type:jsonb" json:"media_urls"`
 	Visibility string         `gorm:"default:'public'" json:"visibility"`
 	GroupID    *uint          `gorm:"index" json:"group_id,omitempty"`
+	Pinned     bool           `gorm:"->;-:migration" json:"pinned"`
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  time.Time      `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
@@ -65,6 +74,25 @@ type Post struct {
 	Group     *Group     `gorm:"foreignKey:GroupID" json:"group,omitempty"`
 }
 
+// Pin targets for PinnedPost.TargetType.
+const (
+	PinTargetGroup   = "group"
+	PinTargetProfile = "profile"
+)
+
+// PinnedPost keeps a post at the top of a group or profile timeline.
+type PinnedPost struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PostID     uint      `gorm:"not null;uniqueIndex:idx_pinned_target_post" json:"post_id"`
+	TargetType string    `gorm:"not null;uniqueIndex:idx_pinned_target_post;index:idx_pinned_target" json:"target_type"`
+	TargetID   uint      `gorm:"not null;uniqueIndex:idx_pinned_target_post;index:idx_pinned_target" json:"target_id"`
+	PinnedByID uint      `gorm:"not null" json:"pinned_by_id"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Post     *Post `gorm:"foreignKey:PostID" json:"post,omitempty"`
+	PinnedBy *User `gorm:"foreignKey:PinnedByID" json:"pinned_by,omitempty"`
+}
+
 type Comment struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	PostID    uint           `gorm:"not null;index" json:"post_id"`
@@ -95,6 +123,13 @@ type Reaction struct {
 	Comment *Comment `gorm:"foreignKey:CommentID" json:"comment,omitempty"`
 }
 
+// Connection statuses.
+const (
+	ConnectionStatusPending  = "pending"
+	ConnectionStatusAccepted = "accepted"
+	ConnectionStatusRejected = "rejected"
+)
+
 type Connection struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	UserID    uint      `gorm:"not null;index" json:"user_id"`
@@ -107,37 +142,140 @@ type Connection struct {
 	Target *User `gorm:"foreignKey:TargetID" json:"target,omitempty"`
 }
 
-type Notification struct {
+// UserBlock hides the two users from each other and prevents the blocked
+// user from contacting the blocker.
+type UserBlock struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `gorm:"not null;index" json:"user_id"`
-	ActorID   *uint     `gorm:"index" json:"actor_id,omitempty"`
-	Type      string    `gorm:"not null" json:"type"`
-	Title     string    `json:"title"`
-	Message   string    `gorm:"type:text" json:"message"`
-	Link      string    `json:"link"`
-	IsRead    bool      `gorm:"default:false" json:"is_read"`
-	Data      string    `gorm:"type:jsonb" json:"data"`
+	BlockerID uint      `gorm:"not null;uniqueIndex:idx_user_block" json:"blocker_id"`
+	BlockedID uint      `gorm:"not null;uniqueIndex:idx_user_block;index" json:"blocked_id"`
 	CreatedAt time.Time `json:"created_at"`
 
+	Blocked *User `gorm:"foreignKey:BlockedID" json:"blocked,omitempty"`
+}
+
+// Message privacy options for UserSettings.MessagePrivacy.
+const (
+	MessagePrivacyEveryone    = "everyone"
+	MessagePrivacyConnections = "connections"
+)
+
+// Last-seen visibility options for UserSettings.LastSeenVisibility.
+const (
+	LastSeenEveryone    = "everyone"
+	LastSeenConnections = "connections"
+	LastSeenNobody      = "nobody"
+)
+
+// UserSettings holds per-user privacy preferences. Users without a row use
+// DefaultUserSettings.
+type UserSettings struct {
+	ID                 uint      `gorm:"primaryKey" json:"-"`
+	UserID             uint      `gorm:"uniqueIndex;not null" json:"user_id"`
+	MessagePrivacy     string    `gorm:"not null;default:'everyone'" json:"message_privacy"`
+	LastSeenVisibility string    `gorm:"not null;default:'everyone'" json:"last_seen_visibility"`
+	CalendarToken      *string   `gorm:"uniqueIndex" json:"-"`
+	CreatedAt          time.Time `json:"-"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+func DefaultUserSettings(userID uint) *UserSettings {
+	return &UserSettings{
+		UserID:             userID,
+		MessagePrivacy:     MessagePrivacyEveryone,
+		LastSeenVisibility: LastSeenEveryone,
+	}
+}
+
+// Notification types.
+const (
+	NotificationTypeGroupJoinRequest        = "group_join_request"
+	NotificationTypeGroupJoinApproved       = "group_join_approved"
+	NotificationTypeGroupJoinDeclined       = "group_join_declined"
+	NotificationTypeGroupInvitation         = "group_invitation"
+	NotificationTypeGroupInvitationAnswered = "group_invitation_answered"
+	NotificationTypeEventReminder           = "event_reminder"
+	NotificationTypeEventPromoted           = "event_waitlist_promoted"
+	NotificationTypeEventCancelled          = "event_cancelled"
+	NotificationTypeComment                 = "comment"
+	NotificationTypeCommentReply            = "comment_reply"
+	NotificationTypeReaction                = "reaction"
+	NotificationTypeMention                 = "mention"
+	NotificationTypeConnectionRequest       = "connection_request"
+	NotificationTypeConnectionAccepted      = "connection_accepted"
+	NotificationTypeSkillEndorsed           = "skill_endorsed"
+	NotificationTypeSkillVerified           = "skill_verified"
+	NotificationTypeGoalMentorAssigned      = "goal_mentor_assigned"
+	NotificationTypeGoalCheckIn             = "goal_check_in"
+	NotificationTypeGoalDue                 = "goal_due"
+	NotificationTypeMentorshipRequested     = "mentorship_requested"
+	NotificationTypeMentorshipAccepted      = "mentorship_accepted"
+	NotificationTypeMentorshipUpdated       = "mentorship_updated"
+)
+
+// Notification is a single notification for UserID. Hidden notifications
+// are kept for email and digest delivery but not shown in the notification
+// centre; Email and Digest record the channels it still has to go out on.
+//
+// Notifications with a GroupKey aggregate activity of several actors on the
+// same target: while unread, later activity is folded into the row, ActorIDs
+// lists every actor (most recent first) and ActorID is the latest one.
+type Notification struct {
+	ID        uint                `gorm:"primaryKey" json:"id"`
+	UserID    uint                `gorm:"not null;index;index:idx_notifications_unread,priority:1;index:idx_notifications_group,priority:1" json:"user_id"`
+	ActorID   *uint               `gorm:"index" json:"actor_id,omitempty"`
+	ActorIDs  IDList              `gorm:"type:jsonb" json:"actor_ids,omitempty"`
+	Type      string              `gorm:"not null" json:"type"`
+	GroupKey  string              `gorm:"index:idx_notifications_group,priority:2" json:"-"`
+	Title     string              `json:"title"`
+	Message   string              `gorm:"type:text" json:"message"`
+	Link      string              `json:"link"`
+	IsRead    bool                `gorm:"default:false;index:idx_notifications_unread,priority:2" json:"is_read"`
+	Data      NotificationPayload `gorm:"type:jsonb" json:"data"`
+	Hidden    bool                `gorm:"not null;default:false" json:"-"`
+	Email     bool                `gorm:"not null;default:false" json:"-"`
+	Digest    bool                `gorm:"not null;default:false" json:"-"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+
 	User  *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
 }
 
+// Message belongs to a Conversation. ReceiverID is only set for messages in
+// direct conversations, where IsRead and ReadAt track the recipient's read
+// state; group conversations rely on participant read cursors.
 type Message struct {
-	ID         uint           `gorm:"primaryKey" json:"id"`
-	SenderID   uint           `gorm:"not null;index" json:"sender_id"`
-	ReceiverID uint           `gorm:"not null;index" json:"receiver_id"`
-	Content    string         `gorm:"type:text;not null" json:"content"`
-	IsRead     bool           `gorm:"default:false" json:"is_read"`
-	ReadAt     *time.Time     `json:"read_at"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uint           `gorm:"primaryKey;index:idx_messages_conversation,priority:2" json:"id"`
+	ConversationID *uint          `gorm:"index:idx_messages_conversation,priority:1" json:"conversation_id"`
+	SenderID       uint           `gorm:"not null;index;index:idx_messages_pair,priority:1" json:"sender_id"`
+	ReceiverID     *uint          `gorm:"index;index:idx_messages_pair,priority:2" json:"receiver_id,omitempty"`
+	ReplyToID      *uint          `gorm:"index" json:"reply_to_id,omitempty"`
+	Content        string         `gorm:"type:text;not null" json:"content"`
+	IsRead         bool           `gorm:"default:false" json:"is_read"`
+	ReadAt         *time.Time     `json:"read_at"`
+	IsEdited       bool           `gorm:"default:false" json:"is_edited"`
+	EditedAt       *time.Time     `json:"edited_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 
-	Sender   *User `gorm:"foreignKey:SenderID" json:"sender,omitempty"`
-	Receiver *User `gorm:"foreignKey:ReceiverID" json:"receiver,omitempty"`
+	Conversation *Conversation     `gorm:"foreignKey:ConversationID" json:"-"`
+	Sender       *User             `gorm:"foreignKey:SenderID" json:"sender,omitempty"`
+	Receiver     *User             `gorm:"foreignKey:ReceiverID" json:"receiver,omitempty"`
+	ReplyTo      *Message          `gorm:"foreignKey:ReplyToID" json:"reply_to,omitempty"`
+	Reactions    []MessageReaction `gorm:"foreignKey:MessageID" json:"reactions,omitempty"`
+	Attachments  []File            `gorm:"many2many:message_attachments" json:"attachments,omitempty"`
 }
 
+// Group visibility levels. Non-members can find private groups but cannot
+// see their posts or member lists; secret groups are hidden from
+// non-members entirely.
+const (
+	GroupVisibilityPublic  = "public"
+	GroupVisibilityPrivate = "private"
+	GroupVisibilitySecret  = "secret"
+)
+
 type Group struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	Name        string         `gorm:"not null" json:"name"`
@@ -155,10 +293,18 @@ type Group struct {
 	Posts   []Post        `gorm:"foreignKey:GroupID" json:"posts,omitempty"`
 }
 
+// Group member roles, from most to least privileged.
+const (
+	GroupRoleOwner     = "owner"
+	GroupRoleAdmin     = "admin"
+	GroupRoleModerator = "moderator"
+	GroupRoleMember    = "member"
+)
+
 type GroupMember struct {
 	ID       uint      `gorm:"primaryKey" json:"id"`
-	GroupID  uint      `gorm:"not null;index" json:"group_id"`
-	UserID   uint      `gorm:"not null;index" json:"user_id"`
+	GroupID  uint      `gorm:"not null;index;uniqueIndex:idx_group_member" json:"group_id"`
+	UserID   uint      `gorm:"not null;index;uniqueIndex:idx_group_member" json:"user_id"`
 	Role     string    `gorm:"default:'member'" json:"role"`
 	JoinedAt time.Time `json:"joined_at"`
 
@@ -166,37 +312,102 @@ type GroupMember struct {
 	User  *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
+// GroupBan prevents a user from joining a group again after removal.
+type GroupBan struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	GroupID    uint      `gorm:"not null;uniqueIndex:idx_group_ban" json:"group_id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_group_ban" json:"user_id"`
+	BannedByID uint      `gorm:"not null" json:"banned_by_id"`
+	Reason     string    `gorm:"type:text" json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	User     *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	BannedBy *User `gorm:"foreignKey:BannedByID" json:"banned_by,omitempty"`
+}
+
+// Skill is a canonical catalog skill. Skills form a hierarchy through
+// ParentID, e.g. "Go" under "Programming". Category holds the name of the
+// skill's SkillCategory so existing clients keep working; it is maintained
+// together with CategoryID.
 type Skill struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	Name        string         `gorm:"uniqueIndex;not null" json:"name"`
 	Category    string         `json:"category"`
+	CategoryID  *uint          `gorm:"index" json:"category_id,omitempty"`
+	ParentID    *uint          `gorm:"index" json:"parent_id,omitempty"`
 	Description string         `gorm:"type:text" json:"description"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
-	UserSkills []UserSkill `gorm:"foreignKey:SkillID" json:"user_skills,omitempty"`
+	Parent     *Skill         `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
+	Children   []Skill        `gorm:"foreignKey:ParentID" json:"children,omitempty"`
+	Synonyms   []SkillSynonym `gorm:"foreignKey:SkillID" json:"synonyms,omitempty"`
+	UserSkills []UserSkill    `gorm:"foreignKey:SkillID" json:"user_skills,omitempty"`
 }
 
+// Skill levels, from least to most experienced.
+const (
+	SkillLevelBeginner     = "beginner"
+	SkillLevelIntermediate = "intermediate"
+	SkillLevelAdvanced     = "advanced"
+	SkillLevelExpert       = "expert"
+)
+
+// UserSkill is a skill on a user's profile. Level is self-declared; the
+// Verified fields hold the latest assessment, see SkillAssessment.
+// Verification is filled in by the service layer from VerificationStatus.
 type UserSkill struct {
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	UserID     uint      `gorm:"not null;index" json:"user_id"`
-	SkillID    uint      `gorm:"not null;index" json:"skill_id"`
-	Level      string    `json:"level"`
-	YearsOfExp int       `json:"years_of_experience"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"not null;index;uniqueIndex:idx_user_skill" json:"user_id"`
+	SkillID       uint       `gorm:"not null;index;uniqueIndex:idx_user_skill" json:"skill_id"`
+	Level         string     `json:"level"`
+	YearsOfExp    int        `json:"years_of_experience"`
+	VerifiedLevel string     `json:"verified_level,omitempty"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"`
+	VerifiedUntil *time.Time `gorm:"index" json:"verified_until,omitempty"`
+	Verification  string     `gorm:"-" json:"verification"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	User         *User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Skill        *Skill            `gorm:"foreignKey:SkillID" json:"skill,omitempty"`
+	Endorsements []Endorsement     `gorm:"foreignKey:UserSkillID" json:"endorsements,omitempty"`
+	Assessments  []SkillAssessment `gorm:"foreignKey:UserSkillID" json:"assessments,omitempty"`
+}
+
+// VerificationStatus reports whether the user skill's level is verified at
+// the given time, was verified but has expired, or is only self-declared.
+func (us *UserSkill) VerificationStatus(now time.Time) string {
+	switch {
+	case us.VerifiedLevel == "":
+		return VerificationSelfDeclared
+	case us.VerifiedUntil != nil && !now.Before(*us.VerifiedUntil):
+		return VerificationExpired
+	}
+	return VerificationVerified
+}
 
-	User         *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Skill        *Skill        `gorm:"foreignKey:SkillID" json:"skill,omitempty"`
-	Endorsements []Endorsement `gorm:"foreignKey:UserSkillID" json:"endorsements,omitempty"`
+// EffectiveLevel is the verified level while the verification is valid and
+// the self-declared level otherwise.
+func (us *UserSkill) EffectiveLevel(now time.Time) string {
+	if us.VerificationStatus(now) == VerificationVerified {
+		return us.VerifiedLevel
+	}
+	return us.Level
 }
 
+// Endorsement vouches for another user's skill. Each user endorses a given
+// user skill at most once. The recipient can hide an endorsement from their
+// profile, which also leaves it out of rankings. Weight is set from the
+// endorser's own level in the skill; see EndorsementWeight.
 type Endorsement struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
-	UserSkillID uint      `gorm:"not null;index" json:"user_skill_id"`
-	EndorserID  uint      `gorm:"not null;index" json:"endorser_id"`
+	UserSkillID uint      `gorm:"not null;index;uniqueIndex:idx_endorsement" json:"user_skill_id"`
+	EndorserID  uint      `gorm:"not null;index;uniqueIndex:idx_endorsement" json:"endorser_id"`
 	Comment     string    `gorm:"type:text" json:"comment"`
+	Hidden      bool      `gorm:"not null;default:false" json:"hidden"`
+	Weight      float64   `gorm:"-" json:"weight,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 
 	UserSkill *UserSkill `gorm:"foreignKey:UserSkillID" json:"user_skill,omitempty"`
@@ -0,0 +1,474 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NotificationData is the typed payload of a notification. Every
+// notification type has exactly one payload struct, which reports its type
+// through NotificationType.
+type NotificationData interface {
+	NotificationType() string
+}
+
+type GroupJoinRequestData struct {
+	GroupID   uint `json:"group_id"`
+	RequestID uint `json:"request_id"`
+}
+
+// GroupJoinDecisionData is sent to the requester once an admin approves or
+// declines their join request.
+type GroupJoinDecisionData struct {
+	GroupID   uint `json:"group_id"`
+	RequestID uint `json:"request_id"`
+	Approved  bool `json:"approved"`
+}
+
+type GroupInvitationData struct {
+	GroupID      uint `json:"group_id"`
+	InvitationID uint `json:"invitation_id"`
+}
+
+type GroupInvitationAnsweredData struct {
+	GroupID      uint `json:"group_id"`
+	InvitationID uint `json:"invitation_id"`
+	Accepted     bool `json:"accepted"`
+}
+
+type EventReminderData struct {
+	GroupID uint `json:"group_id"`
+	EventID uint `json:"event_id"`
+}
+
+type EventPromotedData struct {
+	GroupID uint `json:"group_id"`
+	EventID uint `json:"event_id"`
+}
+
+type EventCancelledData struct {
+	GroupID uint `json:"group_id"`
+	EventID uint `json:"event_id"`
+}
+
+// CommentData is sent to a post's author when someone comments on it.
+type CommentData struct {
+	PostID    uint `json:"post_id"`
+	CommentID uint `json:"comment_id"`
+}
+
+// CommentReplyData is sent to a comment's author when someone replies to it.
+type CommentReplyData struct {
+	PostID    uint `json:"post_id"`
+	CommentID uint `json:"comment_id"`
+	ParentID  uint `json:"parent_id"`
+}
+
+// ReactionData is sent to the author of the reacted-to post or comment.
+// Exactly one of PostID and CommentID is set.
+type ReactionData struct {
+	PostID    *uint  `json:"post_id,omitempty"`
+	CommentID *uint  `json:"comment_id,omitempty"`
+	Reaction  string `json:"reaction"`
+}
+
+// MentionData is sent to users @mentioned in a post or comment. CommentID is
+// nil for mentions in the post itself.
+type MentionData struct {
+	PostID    uint  `json:"post_id"`
+	CommentID *uint `json:"comment_id,omitempty"`
+}
+
+type ConnectionRequestData struct {
+	ConnectionID uint `json:"connection_id"`
+}
+
+type ConnectionAcceptedData struct {
+	ConnectionID uint `json:"connection_id"`
+}
+
+type SkillEndorsedData struct {
+	UserSkillID   uint `json:"user_skill_id"`
+	SkillID       uint `json:"skill_id"`
+	EndorsementID uint `json:"endorsement_id"`
+}
+
+type SkillVerifiedData struct {
+	UserSkillID  uint   `json:"user_skill_id"`
+	SkillID      uint   `json:"skill_id"`
+	AssessmentID uint   `json:"assessment_id"`
+	Level        string `json:"level"`
+}
+
+type GoalMentorAssignedData struct {
+	GoalID  uint `json:"goal_id"`
+	SkillID uint `json:"skill_id"`
+}
+
+type GoalDueData struct {
+	GoalID  uint `json:"goal_id"`
+	SkillID uint `json:"skill_id"`
+}
+
+type GoalCheckInData struct {
+	GoalID    uint `json:"goal_id"`
+	CheckInID uint `json:"check_in_id"`
+}
+
+type MentorshipRequestedData struct {
+	MentorshipID uint `json:"mentorship_id"`
+	SkillID      uint `json:"skill_id"`
+}
+
+type MentorshipAcceptedData struct {
+	MentorshipID uint `json:"mentorship_id"`
+	SkillID      uint `json:"skill_id"`
+}
+
+// MentorshipUpdatedData reports a mentorship that was declined, paused,
+// resumed or ended; Status is its new status.
+type MentorshipUpdatedData struct {
+	MentorshipID uint   `json:"mentorship_id"`
+	SkillID      uint   `json:"skill_id"`
+	Status       string `json:"status"`
+}
+
+// AggregatableData is implemented by payloads whose notifications are
+// grouped per target. Notifications of the same type with the same
+// AggregationKey are folded into one while unread.
+type AggregatableData interface {
+	NotificationData
+	AggregationKey() string
+}
+
+func (d CommentData) AggregationKey() string      { return fmt.Sprintf("post:%d", d.PostID) }
+func (d CommentReplyData) AggregationKey() string { return fmt.Sprintf("comment:%d", d.ParentID) }
+
+func (d ReactionData) AggregationKey() string {
+	if d.CommentID != nil {
+		return fmt.Sprintf("comment:%d", *d.CommentID)
+	}
+	return fmt.Sprintf("post:%d", *d.PostID)
+}
+
+func (GroupJoinRequestData) NotificationType() string { return NotificationTypeGroupJoinRequest }
+
+func (d GroupJoinDecisionData) NotificationType() string {
+	if d.Approved {
+		return NotificationTypeGroupJoinApproved
+	}
+	return NotificationTypeGroupJoinDeclined
+}
+
+func (GroupInvitationData) NotificationType() string { return NotificationTypeGroupInvitation }
+
+func (GroupInvitationAnsweredData) NotificationType() string {
+	return NotificationTypeGroupInvitationAnswered
+}
+
+func (EventReminderData) NotificationType() string       { return NotificationTypeEventReminder }
+func (EventPromotedData) NotificationType() string       { return NotificationTypeEventPromoted }
+func (EventCancelledData) NotificationType() string      { return NotificationTypeEventCancelled }
+func (CommentData) NotificationType() string             { return NotificationTypeComment }
+func (CommentReplyData) NotificationType() string        { return NotificationTypeCommentReply }
+func (ReactionData) NotificationType() string            { return NotificationTypeReaction }
+func (MentionData) NotificationType() string             { return NotificationTypeMention }
+func (ConnectionRequestData) NotificationType() string   { return NotificationTypeConnectionRequest }
+func (ConnectionAcceptedData) NotificationType() string  { return NotificationTypeConnectionAccepted }
+func (SkillEndorsedData) NotificationType() string       { return NotificationTypeSkillEndorsed }
+func (SkillVerifiedData) NotificationType() string       { return NotificationTypeSkillVerified }
+func (GoalMentorAssignedData) NotificationType() string  { return NotificationTypeGoalMentorAssigned }
+func (GoalCheckInData) NotificationType() string         { return NotificationTypeGoalCheckIn }
+func (GoalDueData) NotificationType() string             { return NotificationTypeGoalDue }
+func (MentorshipRequestedData) NotificationType() string { return NotificationTypeMentorshipRequested }
+func (MentorshipAcceptedData) NotificationType() string  { return NotificationTypeMentorshipAccepted }
+func (MentorshipUpdatedData) NotificationType() string   { return NotificationTypeMentorshipUpdated }
+
+// newNotificationData returns an empty payload for the notification type.
+func newNotificationData(notificationType string) (NotificationData, error) {
+	switch notificationType {
+	case NotificationTypeGroupJoinRequest:
+		return &GroupJoinRequestData{}, nil
+	case NotificationTypeGroupJoinApproved, NotificationTypeGroupJoinDeclined:
+		return &GroupJoinDecisionData{}, nil
+	case NotificationTypeGroupInvitation:
+		return &GroupInvitationData{}, nil
+	case NotificationTypeGroupInvitationAnswered:
+		return &GroupInvitationAnsweredData{}, nil
+	case NotificationTypeEventReminder:
+		return &EventReminderData{}, nil
+	case NotificationTypeEventPromoted:
+		return &EventPromotedData{}, nil
+	case NotificationTypeEventCancelled:
+		return &EventCancelledData{}, nil
+	case NotificationTypeComment:
+		return &CommentData{}, nil
+	case NotificationTypeCommentReply:
+		return &CommentReplyData{}, nil
+	case NotificationTypeReaction:
+		return &ReactionData{}, nil
+	case NotificationTypeMention:
+		return &MentionData{}, nil
+	case NotificationTypeConnectionRequest:
+		return &ConnectionRequestData{}, nil
+	case NotificationTypeConnectionAccepted:
+		return &ConnectionAcceptedData{}, nil
+	case NotificationTypeSkillEndorsed:
+		return &SkillEndorsedData{}, nil
+	case NotificationTypeSkillVerified:
+		return &SkillVerifiedData{}, nil
+	case NotificationTypeGoalMentorAssigned:
+		return &GoalMentorAssignedData{}, nil
+	case NotificationTypeGoalCheckIn:
+		return &GoalCheckInData{}, nil
+	case NotificationTypeGoalDue:
+		return &GoalDueData{}, nil
+	case NotificationTypeMentorshipRequested:
+		return &MentorshipRequestedData{}, nil
+	case NotificationTypeMentorshipAccepted:
+		return &MentorshipAcceptedData{}, nil
+	case NotificationTypeMentorshipUpdated:
+		return &MentorshipUpdatedData{}, nil
+	}
+	return nil, fmt.Errorf("unknown notification type %q", notificationType)
+}
+
+// NotificationPayload is the JSON encoding of a NotificationData. It is
+// stored as jsonb and rendered as an object, not a string, in API responses.
+type NotificationPayload string
+
+// EncodeNotificationData serialises data for storage in Notification.Data.
+func EncodeNotificationData(data NotificationData) (NotificationPayload, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return NotificationPayload(raw), nil
+}
+
+func (p NotificationPayload) MarshalJSON() ([]byte, error) {
+	if p == "" {
+		return []byte("{}"), nil
+	}
+	return []byte(p), nil
+}
+
+func (p *NotificationPayload) UnmarshalJSON(raw []byte) error {
+	*p = NotificationPayload(raw)
+	return nil
+}
+
+// IDList is a list of IDs stored as a jsonb array.
+type IDList []uint
+
+func (l IDList) Contains(id uint) bool {
+	for _, v := range l {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (l IDList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal([]uint(l))
+	return string(raw), err
+}
+
+func (l *IDList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]uint)(l))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]uint)(l))
+	}
+	return errors.New("unsupported IDList source")
+}
+
+// Payload decodes the notification's data into the struct for its type.
+func (n *Notification) Payload() (NotificationData, error) {
+	data, err := newNotificationData(n.Type)
+	if err != nil {
+		return nil, err
+	}
+	if n.Data != "" {
+		if err := json.Unmarshal([]byte(n.Data), data); err != nil {
+			return nil, fmt.Errorf("decode %s notification data: %w", n.Type, err)
+		}
+	}
+	return data, nil
+}
+
+// Notification categories. Preferences are chosen per category rather than
+// per notification type.
+const (
+	NotificationCategoryComment     = "comment"
+	NotificationCategoryReaction    = "reaction"
+	NotificationCategoryMention     = "mention"
+	NotificationCategoryConnection  = "connection"
+	NotificationCategoryMessage     = "message"
+	NotificationCategoryEndorsement = "endorsement"
+	NotificationCategorySkill       = "skill"
+	NotificationCategoryGroup       = "group"
+)
+
+// NotificationCategories lists every category in display order.
+var NotificationCategories = []string{
+	NotificationCategoryComment,
+	NotificationCategoryReaction,
+	NotificationCategoryMention,
+	NotificationCategoryConnection,
+	NotificationCategoryMessage,
+	NotificationCategoryEndorsement,
+	NotificationCategorySkill,
+	NotificationCategoryGroup,
+}
+
+// NotificationCategory returns the preference category of a notification
+// type.
+func NotificationCategory(notificationType string) string {
+	switch notificationType {
+	case NotificationTypeComment, NotificationTypeCommentReply:
+		return NotificationCategoryComment
+	case NotificationTypeReaction:
+		return NotificationCategoryReaction
+	case NotificationTypeMention:
+		return NotificationCategoryMention
+	case NotificationTypeConnectionRequest, NotificationTypeConnectionAccepted:
+		return NotificationCategoryConnection
+	case NotificationTypeSkillEndorsed:
+		return NotificationCategoryEndorsement
+	case NotificationTypeSkillVerified, NotificationTypeGoalMentorAssigned, NotificationTypeGoalCheckIn, NotificationTypeGoalDue,
+		NotificationTypeMentorshipRequested, NotificationTypeMentorshipAccepted, NotificationTypeMentorshipUpdated:
+		return NotificationCategorySkill
+	}
+	return NotificationCategoryGroup
+}
+
+// NotificationPreference selects the channels a user receives one category
+// of notifications on: the in-app notification centre, live push over the
+// WebSocket, immediate email and the periodic email digest. Users without a
+// row for a category use DefaultNotificationPreference.
+type NotificationPreference struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_notification_preference" json:"-"`
+	Category  string    `gorm:"not null;uniqueIndex:idx_notification_preference" json:"category"`
+	InApp     bool      `gorm:"not null" json:"in_app"`
+	Push      bool      `gorm:"not null" json:"push"`
+	Email     bool      `gorm:"not null" json:"email"`
+	Digest    bool      `gorm:"not null" json:"digest"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func DefaultNotificationPreference(userID uint, category string) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:   userID,
+		Category: category,
+		InApp:    true,
+		Push:     true,
+		Digest:   true,
+	}
+}
+
+// Enabled reports whether any channel is on.
+func (p *NotificationPreference) Enabled() bool {
+	return p.InApp || p.Push || p.Email || p.Digest
+}
+
+// Digest frequencies for NotificationSettings.DigestFrequency.
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// NotificationSettings holds a user's do-not-disturb schedule and digest
+// frequency. Start and end are "HH:MM" wall-clock times in Timezone; a window
+// whose end is before its start runs over midnight. Users without a row use
+// DefaultNotificationSettings.
+type NotificationSettings struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	UserID          uint      `gorm:"uniqueIndex;not null" json:"-"`
+	DNDEnabled      bool      `gorm:"not null;default:false" json:"enabled"`
+	DNDStart        string    `gorm:"not null;default:'22:00'" json:"start"`
+	DNDEnd          string    `gorm:"not null;default:'07:00'" json:"end"`
+	Timezone        string    `gorm:"not null;default:'UTC'" json:"timezone"`
+	DigestFrequency string    `gorm:"not null;default:'weekly'" json:"-"`
+	UpdatedAt       time.Time `json:"-"`
+}
+
+func DefaultNotificationSettings(userID uint) *NotificationSettings {
+	return &NotificationSettings{
+		UserID:          userID,
+		DNDStart:        "22:00",
+		DNDEnd:          "07:00",
+		Timezone:        "UTC",
+		DigestFrequency: DigestWeekly,
+	}
+}
+
+// Location returns the user's time zone, falling back to UTC.
+func (s *NotificationSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// DigestDelivery records the digest of one period for a user. The unique
+// (user, period) pair is claimed before sending so that a restarted job does
+// not send the same digest twice; SentAt stays nil until delivery finished.
+type DigestDelivery struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_digest_delivery"`
+	Period    string    `gorm:"not null;uniqueIndex:idx_digest_delivery"` // e.g. daily:2024-01-15, weekly:2024-W03
+	ClaimedAt time.Time `gorm:"not null"`
+	SentAt    *time.Time
+	Items     int
+}
+
+// InQuietHours reports whether t falls inside the do-not-disturb window.
+// Invalid schedules never silence notifications.
+func (s *NotificationSettings) InQuietHours(t time.Time) bool {
+	if !s.DNDEnabled {
+		return false
+	}
+	start, err1 := time.Parse("15:04", s.DNDStart)
+	end, err2 := time.Parse("15:04", s.DNDEnd)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	local := t.In(s.Location())
+	now := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to
+}
+
+// PushSubscription is a browser's Web Push subscription. Each browser
+// profile has its own endpoint; subscribing again from the same browser
+// updates the existing row.
+type PushSubscription struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	Endpoint   string     `gorm:"type:text;not null;uniqueIndex" json:"endpoint"`
+	P256dh     string     `gorm:"not null" json:"-"`
+	Auth       string     `gorm:"not null" json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
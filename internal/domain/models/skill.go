@@ -0,0 +1,124 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// SkillCategory groups catalog skills, e.g. "Programming Languages".
+type SkillCategory struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SkillSynonym is an alternative name that resolves to a canonical skill,
+// e.g. "Golang" for "Go". NameKey is the normalised name; it is unique so a
+// synonym always resolves to exactly one skill.
+type SkillSynonym struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SkillID   uint      `gorm:"not null;index" json:"skill_id"`
+	Name      string    `gorm:"not null" json:"name"`
+	NameKey   string    `gorm:"not null;uniqueIndex" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SkillTarget is part of a department's or group's target skill profile: at
+// least MinPeople people should have the skill at MinLevel or above.
+// ScopeKey identifies the department or group.
+type SkillTarget struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ScopeKey   string    `gorm:"not null;uniqueIndex:idx_skill_target" json:"-"`
+	Department string    `json:"department,omitempty"`
+	GroupID    *uint     `gorm:"index" json:"group_id,omitempty"`
+	SkillID    uint      `gorm:"not null;uniqueIndex:idx_skill_target" json:"skill_id"`
+	MinLevel   string    `gorm:"not null" json:"min_level"`
+	MinPeople  int       `gorm:"not null" json:"min_people"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	Skill *Skill `gorm:"foreignKey:SkillID" json:"skill,omitempty"`
+}
+
+// Verification statuses of a user skill's level.
+const (
+	VerificationSelfDeclared = "self_declared"
+	VerificationVerified     = "verified"
+	VerificationExpired      = "expired"
+)
+
+// SkillAssessor allows a user to verify other users' skill levels. A grant
+// covers one skill, the skills of one category, or every skill when neither
+// is set, and in each case the descendants of those skills.
+type SkillAssessor struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	SkillID     *uint     `gorm:"index" json:"skill_id,omitempty"`
+	CategoryID  *uint     `gorm:"index" json:"category_id,omitempty"`
+	GrantedByID uint      `gorm:"not null" json:"granted_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	User     *User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Skill    *Skill         `gorm:"foreignKey:SkillID" json:"skill,omitempty"`
+	Category *SkillCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
+
+// SkillAssessment records an assessor verifying a user skill at Level on
+// AssessedAt. Assessments are never changed; the latest one sets the user
+// skill's verified level until ExpiresAt.
+type SkillAssessment struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserSkillID uint       `gorm:"not null;index" json:"user_skill_id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	SkillID     uint       `gorm:"not null;index" json:"skill_id"`
+	AssessorID  uint       `gorm:"not null;index" json:"assessor_id"`
+	Level       string     `gorm:"not null" json:"level"`
+	Evidence    string     `gorm:"type:text;not null" json:"evidence,omitempty"`
+	EvidenceURL string     `json:"evidence_url,omitempty"`
+	AssessedAt  time.Time  `gorm:"not null" json:"assessed_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Assessor *User `gorm:"foreignKey:AssessorID" json:"assessor,omitempty"`
+}
+
+// SkillKey normalises a skill or synonym name for case-insensitive
+// comparison.
+func SkillKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// SkillLevels lists the skill levels from least to most experienced.
+var SkillLevels = []string{SkillLevelBeginner, SkillLevelIntermediate, SkillLevelAdvanced, SkillLevelExpert}
+
+// SkillLevelRank orders skill levels from 1 (beginner) to 4 (expert); unknown
+// levels rank 0.
+func SkillLevelRank(level string) int {
+	for i, l := range SkillLevels {
+		if l == level {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// EndorsementWeights is how much an endorsement counts in rankings by the
+// endorser's own level in the endorsed skill. Endorsers without the skill
+// count as beginners.
+var EndorsementWeights = map[string]float64{
+	SkillLevelBeginner:     1,
+	SkillLevelIntermediate: 1.5,
+	SkillLevelAdvanced:     2,
+	SkillLevelExpert:       3,
+}
+
+// EndorsementWeight returns the weight of an endorsement by an endorser at
+// the given level.
+func EndorsementWeight(endorserLevel string) float64 {
+	if weight, ok := EndorsementWeights[endorserLevel]; ok {
+		return weight
+	}
+	return EndorsementWeights[SkillLevelBeginner]
+}
@@ -0,0 +1,127 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+// Broker kinds accepted by NewBroker.
+const (
+	BrokerRedis = "redis"
+	BrokerLocal = "local"
+)
+
+// brokerChannel is the Redis pub/sub channel shared by all API replicas.
+const brokerChannel = "skillflow:realtime"
+
+// Broker fans events out to every node. Publish may be called from any
+// node; Run delivers the events published anywhere to this node's
+// connections.
+type Broker interface {
+	Publisher
+	// Run delivers events until ctx is done.
+	Run(ctx context.Context) error
+}
+
+// NewBroker returns the broker of the given kind delivering to local, which
+// is normally this node's Hub. An empty kind selects Redis.
+func NewBroker(kind string, cache *redis.Client, local Publisher, log *logger.Logger) (Broker, error) {
+	switch kind {
+	case "", BrokerRedis:
+		return NewRedisBroker(cache, local, log), nil
+	case BrokerLocal:
+		return NewLocalBroker(local), nil
+	default:
+		return nil, fmt.Errorf("unknown realtime broker %q", kind)
+	}
+}
+
+// LocalBroker delivers events in-process. It suits single-node deployments
+// and tests.
+type LocalBroker struct {
+	local Publisher
+}
+
+func NewLocalBroker(local Publisher) *LocalBroker {
+	return &LocalBroker{local: local}
+}
+
+func (b *LocalBroker) Publish(ctx context.Context, userIDs []uint, event Event) error {
+	return b.local.Publish(ctx, userIDs, event)
+}
+
+// Run has nothing to receive; it blocks until ctx is done so callers can
+// treat all brokers alike.
+func (b *LocalBroker) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// RedisBroker fans events out through Redis pub/sub. Every replica,
+// including the publisher, receives each event and delivers it to its own
+// connections, so events are never delivered twice.
+type RedisBroker struct {
+	client *redis.Client
+	local  Publisher
+	log    *logger.Logger
+}
+
+func NewRedisBroker(client *redis.Client, local Publisher, log *logger.Logger) *RedisBroker {
+	return &RedisBroker{client: client, local: local, log: log}
+}
+
+// envelope is the wire format of an event on the Redis channel. Data stays
+// raw so it is forwarded to clients exactly as published.
+type envelope struct {
+	UserIDs []uint          `json:"user_ids"`
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, userIDs []uint, event Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(envelope{UserIDs: userIDs, ID: event.ID, Type: event.Type, Data: data})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, brokerChannel, payload).Err()
+}
+
+// Run subscribes to the channel and delivers events to local until ctx is
+// done. The Redis client reconnects the subscription on its own.
+func (b *RedisBroker) Run(ctx context.Context) error {
+	sub := b.client.Subscribe(ctx, brokerChannel)
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("subscribe to %s: %w", brokerChannel, err)
+	}
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			var env envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				b.log.Error("Failed to decode realtime event", "error", err)
+				continue
+			}
+			if err := b.local.Publish(ctx, env.UserIDs, Event{ID: env.ID, Type: env.Type, Data: env.Data}); err != nil {
+				b.log.Error("Failed to deliver realtime event", "type", env.Type, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/vern/skillflow/internal/config"
+	"github.com/vern/skillflow/pkg/logger"
+)
+
+const (
+	// writeWait is the time allowed to write a frame to the peer.
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed to read the next pong from the peer.
+	pongWait = 60 * time.Second
+	// pingPeriod must be shorter than pongWait.
+	pingPeriod = pongWait * 9 / 10
+	// sendBufferSize is how many events may queue for a slow client before
+	// it is disconnected.
+	sendBufferSize = 64
+	// handlerTimeout bounds the processing of one client event.
+	handlerTimeout = 10 * time.Second
+)
+
+// ErrHubClosed is returned by ServeWS once the hub has shut down.
+var ErrHubClosed = errors.New("realtime hub is closed")
+
+// Handler processes an event sent by a client.
+type Handler func(ctx context.Context, userID uint, data json.RawMessage) error
+
+// Lifecycle observes connections, each identified by a unique connID. The
+// callbacks run on the connection's own goroutines; nil callbacks are
+// skipped.
+type Lifecycle struct {
+	Connected    func(userID uint, connID string)
+	Heartbeat    func(userID uint, connID string) // on every pong
+	Disconnected func(userID uint, connID string)
+}
+
+// Hub tracks live WebSocket connections and event stream subscriptions,
+// several per user, and delivers events to them. It implements Publisher for
+// the local node.
+type Hub struct {
+	upgrader       websocket.Upgrader
+	maxMessageSize int64
+	replay         ReplayBuffer
+	log            *logger.Logger
+
+	mu            sync.RWMutex
+	clients       map[uint]map[*client]struct{}
+	subscriptions map[uint]map[*Subscription]struct{}
+	handlers      map[string]Handler
+	lifecycle     Lifecycle
+	closed        bool
+}
+
+// NewHub creates a hub. Upgrades are accepted from the allowed origins, or
+// from any origin when the list contains "*". Subscriptions resume from
+// replay, which may be nil.
+func NewHub(cfg config.WebSocketConfig, allowedOrigins []string, replay ReplayBuffer, log *logger.Logger) *Hub {
+	h := &Hub{
+		maxMessageSize: int64(cfg.MaxMessageSize),
+		replay:         replay,
+		log:            log,
+		clients:        make(map[uint]map[*client]struct{}),
+		subscriptions:  make(map[uint]map[*Subscription]struct{}),
+		handlers:       make(map[string]Handler),
+	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  cfg.ReadBufferSize,
+		WriteBufferSize: cfg.WriteBufferSize,
+		CheckOrigin:     originChecker(allowedOrigins),
+	}
+	return h
+}
+
+// Handle registers the handler for client events of the given type.
+func (h *Hub) Handle(eventType string, handler Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[eventType] = handler
+}
+
+// SetLifecycle registers the connection lifecycle callbacks.
+func (h *Hub) SetLifecycle(lifecycle Lifecycle) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lifecycle = lifecycle
+}
+
+// ServeWS upgrades the request and serves the connection for userID until
+// either side closes it.
+func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request, userID uint) error {
+	h.mu.RLock()
+	closed := h.closed
+	h.mu.RUnlock()
+	if closed {
+		http.Error(w, ErrHubClosed.Error(), http.StatusServiceUnavailable)
+		return ErrHubClosed
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	c := &client{hub: h, conn: conn, id: uuid.NewString(), userID: userID, send: make(chan []byte, sendBufferSize)}
+	if !h.register(c) {
+		conn.Close()
+		return ErrHubClosed
+	}
+	if fn := h.getLifecycle().Connected; fn != nil {
+		fn(c.userID, c.id)
+	}
+
+	go c.writePump()
+	go c.readPump()
+	return nil
+}
+
+// Subscribe opens an event stream for the user. When lastEventID is set,
+// the events buffered since then are returned as the backlog; live events
+// already in the backlog are skipped by Next.
+func (h *Hub) Subscribe(ctx context.Context, userID uint, lastEventID string) (*Subscription, []Event, error) {
+	sub := &Subscription{hub: h, userID: userID, events: make(chan Event, sendBufferSize)}
+
+	// Register before reading the backlog so no event falls in between.
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, nil, ErrHubClosed
+	}
+	if h.subscriptions[userID] == nil {
+		h.subscriptions[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscriptions[userID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	if lastEventID == "" || h.replay == nil {
+		return sub, nil, nil
+	}
+	backlog, err := h.replay.Since(ctx, userID, lastEventID)
+	if err != nil {
+		sub.Close()
+		return nil, nil, err
+	}
+	if len(backlog) > 0 {
+		sub.lastSeq = backlog[len(backlog)-1].Seq()
+	}
+	return sub, backlog, nil
+}
+
+// Publish delivers the event to every connection and subscription of the
+// given users on this node. Consumers too slow to keep up are disconnected.
+func (h *Hub) Publish(ctx context.Context, userIDs []uint, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var slow []*client
+	var slowSubs []*Subscription
+	h.mu.RLock()
+	for _, userID := range userIDs {
+		for c := range h.clients[userID] {
+			select {
+			case c.send <- payload:
+			default:
+				slow = append(slow, c)
+			}
+		}
+		for sub := range h.subscriptions[userID] {
+			select {
+			case sub.events <- event:
+			default:
+				slowSubs = append(slowSubs, sub)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range slow {
+		h.log.Warn("Disconnecting slow WebSocket client", "user_id", c.userID)
+		c.close()
+	}
+	for _, sub := range slowSubs {
+		h.log.Warn("Closing slow event stream", "user_id", sub.userID)
+		sub.Close()
+	}
+	return nil
+}
+
+// IsOnline reports whether the user has at least one connection to this node.
+func (h *Hub) IsOnline(userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID]) > 0
+}
+
+// Close disconnects every client with a going-away close frame, ends every
+// subscription and rejects new ones. It is meant to be registered with
+// http.Server.RegisterOnShutdown: Shutdown neither closes hijacked
+// connections nor interrupts streaming handlers.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	var all []*client
+	for _, conns := range h.clients {
+		for c := range conns {
+			all = append(all, c)
+		}
+	}
+	var subs []*Subscription
+	for _, userSubs := range h.subscriptions {
+		for sub := range userSubs {
+			subs = append(subs, sub)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, c := range all {
+		c.close()
+	}
+	for _, sub := range subs {
+		sub.Close()
+	}
+}
+
+func (h *Hub) register(c *client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	if h.clients[c.userID] == nil {
+		h.clients[c.userID] = make(map[*client]struct{})
+	}
+	h.clients[c.userID][c] = struct{}{}
+	return true
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
+
+func (h *Hub) getLifecycle() Lifecycle {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.lifecycle
+}
+
+func (h *Hub) handler(eventType string) Handler {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.handlers[eventType]
+}
+
+// Subscription is one user's live event stream, e.g. Server-Sent Events.
+type Subscription struct {
+	hub       *Hub
+	userID    uint
+	events    chan Event
+	lastSeq   uint64
+	closeOnce sync.Once
+}
+
+// Events yields live events. The channel is closed when the subscription
+// ends: on Close, when the consumer falls behind, or on hub shutdown.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Fresh reports whether the live event was not already delivered in the
+// backlog.
+func (s *Subscription) Fresh(event Event) bool {
+	return event.Seq() == 0 || event.Seq() > s.lastSeq
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.hub.mu.Lock()
+		delete(s.hub.subscriptions[s.userID], s)
+		if len(s.hub.subscriptions[s.userID]) == 0 {
+			delete(s.hub.subscriptions, s.userID)
+		}
+		s.hub.mu.Unlock()
+		close(s.events)
+	})
+}
+
+// client is one WebSocket connection. writePump is the only goroutine that
+// writes to conn; readPump is the only one that reads.
+type client struct {
+	hub       *Hub
+	conn      *websocket.Conn
+	id        string
+	userID    uint
+	send      chan []byte
+	closeOnce sync.Once
+}
+
+// close unregisters the client and closes its send channel, which makes
+// writePump send a close frame and close the connection.
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		c.hub.unregister(c)
+		close(c.send)
+		if fn := c.hub.getLifecycle().Disconnected; fn != nil {
+			fn(c.userID, c.id)
+		}
+	})
+}
+
+// inboundEvent is an event sent by a client. Data is decoded by the handler
+// registered for Type.
+type inboundEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+func (c *client) readPump() {
+	defer c.close()
+
+	if c.hub.maxMessageSize > 0 {
+		c.conn.SetReadLimit(c.hub.maxMessageSize)
+	}
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		if fn := c.hub.getLifecycle().Heartbeat; fn != nil {
+			fn(c.userID, c.id)
+		}
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, payload, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				c.hub.log.Warn("WebSocket read failed", "user_id", c.userID, "error", err)
+			}
+			return
+		}
+
+		var event inboundEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			c.sendError("invalid event")
+			continue
+		}
+		handler := c.hub.handler(event.Type)
+		if handler == nil {
+			c.sendError("unknown event type: " + event.Type)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), handlerTimeout)
+		err = handler(ctx, c.userID, event.Data)
+		cancel()
+		if err != nil {
+			c.sendError(err.Error())
+		}
+	}
+}
+
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendError reports a failed client event back to that connection only.
+func (c *client) sendError(message string) {
+	payload, err := json.Marshal(Event{Type: EventError, Data: map[string]string{"message": message}})
+	if err != nil {
+		return
+	}
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	if _, ok := c.hub.clients[c.userID][c]; !ok {
+		return
+	}
+	select {
+	case c.send <- payload:
+	default:
+	}
+}
+
+func originChecker(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, o := range allowed {
+			if o == "*" || o == origin {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,30 @@
+// Package realtime defines the events pushed to users' live connections.
+package realtime
+
+import "context"
+
+// Event types.
+const (
+	EventMessageCreated      = "message.created"
+	EventMessageUpdated      = "message.updated"
+	EventMessageDeleted      = "message.deleted"
+	EventMessageReaction     = "message.reaction"
+	EventNotificationCreated = "notification.created"
+	EventNotificationUpdated = "notification.updated"
+	EventTyping              = "typing"
+	EventPresence            = "presence"
+	EventError               = "error"
+)
+
+// Event is the envelope delivered to clients. ID is assigned when the event
+// is recorded for replay and lets clients resume after reconnecting.
+type Event struct {
+	ID   string      `json:"id,omitempty"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Publisher delivers events to every live connection of the given users.
+type Publisher interface {
+	Publish(ctx context.Context, userIDs []uint, event Event) error
+}
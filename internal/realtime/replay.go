@@ -0,0 +1,192 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// replaySize is how many recent events are kept per user.
+	replaySize = 100
+	// replayTTL is how long events stay available for resumption.
+	replayTTL = 5 * time.Minute
+)
+
+// ReplayBuffer keeps each user's most recent events so a client that lost
+// its connection can resume from the last event ID it received.
+type ReplayBuffer interface {
+	// Record assigns the event a new ID, increasing across all events, and
+	// buffers it for every user.
+	Record(ctx context.Context, userIDs []uint, event Event) (Event, error)
+	// Since returns the user's buffered events after lastID, oldest first.
+	Since(ctx context.Context, userID uint, lastID string) ([]Event, error)
+}
+
+// NewReplayBuffer returns the buffer matching the broker kind: shared in
+// Redis when events fan out across replicas, in memory otherwise.
+func NewReplayBuffer(brokerKind string, cache *redis.Client) ReplayBuffer {
+	if brokerKind == BrokerLocal {
+		return NewMemoryReplayBuffer()
+	}
+	return NewRedisReplayBuffer(cache)
+}
+
+// Seq returns the numeric sequence of the event ID, or 0 when it has none.
+func (e Event) Seq() uint64 {
+	seq, _ := strconv.ParseUint(e.ID, 10, 64)
+	return seq
+}
+
+// WithReplay records every event in the buffer before publishing it, so
+// events carry IDs clients can resume from.
+func WithReplay(next Publisher, buffer ReplayBuffer) Publisher {
+	return &replayPublisher{next: next, buffer: buffer}
+}
+
+type replayPublisher struct {
+	next   Publisher
+	buffer ReplayBuffer
+}
+
+// Publish delivers the event even when recording fails; it just cannot be
+// replayed then.
+func (p *replayPublisher) Publish(ctx context.Context, userIDs []uint, event Event) error {
+	recorded, recordErr := p.buffer.Record(ctx, userIDs, event)
+	if recordErr != nil {
+		recorded = event
+		recordErr = fmt.Errorf("record event for replay: %w", recordErr)
+	}
+	return errors.Join(recordErr, p.next.Publish(ctx, userIDs, recorded))
+}
+
+// RedisReplayBuffer shares the buffer between replicas: each user has a
+// capped Redis list of JSON-encoded events, and IDs come from a global
+// counter.
+type RedisReplayBuffer struct {
+	client *redis.Client
+}
+
+func NewRedisReplayBuffer(client *redis.Client) *RedisReplayBuffer {
+	return &RedisReplayBuffer{client: client}
+}
+
+const replaySeqKey = "realtime:replay:seq"
+
+func replayKey(userID uint) string { return fmt.Sprintf("realtime:replay:%d", userID) }
+
+func (b *RedisReplayBuffer) Record(ctx context.Context, userIDs []uint, event Event) (Event, error) {
+	seq, err := b.client.Incr(ctx, replaySeqKey).Result()
+	if err != nil {
+		return event, err
+	}
+	event.ID = strconv.FormatInt(seq, 10)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return event, err
+	}
+	pipe := b.client.Pipeline()
+	for _, userID := range userIDs {
+		key := replayKey(userID)
+		pipe.RPush(ctx, key, payload)
+		pipe.LTrim(ctx, key, -replaySize, -1)
+		pipe.Expire(ctx, key, replayTTL)
+	}
+	_, err = pipe.Exec(ctx)
+	return event, err
+}
+
+func (b *RedisReplayBuffer) Since(ctx context.Context, userID uint, lastID string) ([]Event, error) {
+	last := Event{ID: lastID}.Seq()
+	raw, err := b.client.LRange(ctx, replayKey(userID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, item := range raw {
+		var stored struct {
+			ID   string          `json:"id"`
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(item), &stored); err != nil {
+			continue
+		}
+		event := Event{ID: stored.ID, Type: stored.Type, Data: stored.Data}
+		if event.Seq() > last {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// MemoryReplayBuffer keeps the buffer in process, for single-node
+// deployments and tests.
+type MemoryReplayBuffer struct {
+	mu     sync.Mutex
+	seq    uint64
+	events map[uint][]bufferedEvent
+}
+
+type bufferedEvent struct {
+	event Event
+	at    time.Time
+}
+
+func NewMemoryReplayBuffer() *MemoryReplayBuffer {
+	return &MemoryReplayBuffer{events: make(map[uint][]bufferedEvent)}
+}
+
+func (b *MemoryReplayBuffer) Record(ctx context.Context, userIDs []uint, event Event) (Event, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.ID = strconv.FormatUint(b.seq, 10)
+	now := time.Now()
+	for _, userID := range userIDs {
+		buffered := b.prune(userID, now)
+		buffered = append(buffered, bufferedEvent{event: event, at: now})
+		if len(buffered) > replaySize {
+			buffered = buffered[len(buffered)-replaySize:]
+		}
+		b.events[userID] = buffered
+	}
+	return event, nil
+}
+
+func (b *MemoryReplayBuffer) Since(ctx context.Context, userID uint, lastID string) ([]Event, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	last := Event{ID: lastID}.Seq()
+	var events []Event
+	for _, buffered := range b.prune(userID, time.Now()) {
+		if buffered.event.Seq() > last {
+			events = append(events, buffered.event)
+		}
+	}
+	return events, nil
+}
+
+// prune drops the user's expired events. The caller must hold b.mu.
+func (b *MemoryReplayBuffer) prune(userID uint, now time.Time) []bufferedEvent {
+	buffered := b.events[userID]
+	i := 0
+	for i < len(buffered) && now.Sub(buffered[i].at) > replayTTL {
+		i++
+	}
+	buffered = buffered[i:]
+	if len(buffered) == 0 {
+		delete(b.events, userID)
+	}
+	return buffered
+}
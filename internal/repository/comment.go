@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// Comment repository methods
+func (r *CommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+func (r *CommentRepository) GetByID(ctx context.Context, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	err := r.db.WithContext(ctx).Preload("User.Profile").First(&comment, id).Error
+	return &comment, err
+}
+
+// ListByPost returns the post's top-level comments, oldest first, with their
+// replies.
+func (r *CommentRepository) ListByPost(ctx context.Context, postID uint, page, limit int) ([]models.Comment, error) {
+	var comments []models.Comment
+	offset := (page - 1) * limit
+	err := r.db.WithContext(ctx).
+		Where("post_id = ? AND parent_id IS NULL", postID).
+		Preload("User.Profile").
+		Preload("Reactions").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Replies.User.Profile").
+		Preload("Replies.Reactions").
+		Order("id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&comments).Error
+	return comments, err
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment *models.Comment) error {
+	return r.db.WithContext(ctx).Model(comment).Update("content", comment.Content).Error
+}
+
+// Delete removes the comment together with its replies.
+func (r *CommentRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Where("id = ? OR parent_id = ?", id, id).
+		Delete(&models.Comment{}).Error
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Connection repository methods
+func (r *ConnectionRepository) Create(ctx context.Context, connection *models.Connection) error {
+	return r.db.WithContext(ctx).Create(connection).Error
+}
+
+func (r *ConnectionRepository) GetByID(ctx context.Context, id uint) (*models.Connection, error) {
+	var connection models.Connection
+	err := r.db.WithContext(ctx).First(&connection, id).Error
+	return &connection, err
+}
+
+// GetBetween returns the connection between the two users, whichever of them
+// sent the request.
+func (r *ConnectionRepository) GetBetween(ctx context.Context, userID, otherID uint) (*models.Connection, error) {
+	var connection models.Connection
+	err := r.db.WithContext(ctx).
+		Where("(user_id = ? AND target_id = ?) OR (user_id = ? AND target_id = ?)",
+			userID, otherID, otherID, userID).
+		First(&connection).Error
+	return &connection, err
+}
+
+func (r *ConnectionRepository) Update(ctx context.Context, connection *models.Connection) error {
+	return r.db.WithContext(ctx).Save(connection).Error
+}
+
+func (r *ConnectionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Connection{}, id).Error
+}
+
+// ListAccepted returns the user's accepted connections, most recent first.
+func (r *ConnectionRepository) ListAccepted(ctx context.Context, userID uint, page, limit int) ([]models.Connection, error) {
+	var connections []models.Connection
+	offset := (page - 1) * limit
+	err := r.db.WithContext(ctx).
+		Where("(user_id = ? OR target_id = ?) AND status = ?", userID, userID, models.ConnectionStatusAccepted).
+		Preload("User.Profile").
+		Preload("Target.Profile").
+		Order("updated_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&connections).Error
+	return connections, err
+}
+
+// ListPending returns requests awaiting the user's answer, oldest first.
+func (r *ConnectionRepository) ListPending(ctx context.Context, targetID uint) ([]models.Connection, error) {
+	var connections []models.Connection
+	err := r.db.WithContext(ctx).
+		Where("target_id = ? AND status = ?", targetID, models.ConnectionStatusPending).
+		Preload("User.Profile").
+		Order("created_at ASC").
+		Find(&connections).Error
+	return connections, err
+}
+
+func (r *ConnectionRepository) AreConnected(ctx context.Context, userID, otherID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Connection{}).
+		Where("((user_id = ? AND target_id = ?) OR (user_id = ? AND target_id = ?)) AND status = ?",
+			userID, otherID, otherID, userID, models.ConnectionStatusAccepted).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ConnectionRepository) Block(ctx context.Context, blockerID, blockedID uint) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserBlock{BlockerID: blockerID, BlockedID: blockedID}).Error
+}
+
+func (r *ConnectionRepository) Unblock(ctx context.Context, blockerID, blockedID uint) error {
+	return r.db.WithContext(ctx).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&models.UserBlock{}).Error
+}
+
+func (r *ConnectionRepository) IsBlockedEitherWay(ctx context.Context, userID, otherID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+			userID, otherID, otherID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FilterConnected returns the candidates that have an accepted connection
+// with userID.
+func (r *ConnectionRepository) FilterConnected(ctx context.Context, userID uint, candidateIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(candidateIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT target_id FROM connections WHERE user_id = @user AND target_id IN @ids AND status = @status
+		UNION
+		SELECT user_id FROM connections WHERE target_id = @user AND user_id IN @ids AND status = @status`,
+		map[string]interface{}{"user": userID, "ids": candidateIDs, "status": models.ConnectionStatusAccepted},
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// User settings repository methods
+
+// GetByUserID returns the stored settings or the defaults when the user has
+// never changed them.
+func (r *UserSettingsRepository) GetByUserID(ctx context.Context, userID uint) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultUserSettings(userID), nil
+	}
+	return &settings, err
+}
+
+// GetByUserIDs returns settings keyed by user ID, with defaults for users
+// who have never changed them.
+func (r *UserSettingsRepository) GetByUserIDs(ctx context.Context, userIDs []uint) (map[uint]*models.UserSettings, error) {
+	result := make(map[uint]*models.UserSettings, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var stored []models.UserSettings
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	for i := range stored {
+		result[stored[i].UserID] = &stored[i]
+	}
+	for _, id := range userIDs {
+		if _, ok := result[id]; !ok {
+			result[id] = models.DefaultUserSettings(id)
+		}
+	}
+	return result, nil
+}
+
+func (r *UserSettingsRepository) GetByCalendarToken(ctx context.Context, token string) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	err := r.db.WithContext(ctx).Where("calendar_token = ?", token).First(&settings).Error
+	return &settings, err
+}
+
+func (r *UserSettingsRepository) Save(ctx context.Context, settings *models.UserSettings) error {
+	return r.db.WithContext(ctx).Save(settings).Error
+}
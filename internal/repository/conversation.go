@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConversationSummary describes a conversation from one participant's side.
+type ConversationSummary struct {
+	ConversationID uint
+	UnreadCount    int64
+}
+
+// Conversation repository methods
+func (r *ConversationRepository) Create(ctx context.Context, conversation *models.Conversation, participants []models.ConversationParticipant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Participants").Create(conversation).Error; err != nil {
+			return err
+		}
+		for i := range participants {
+			participants[i].ConversationID = conversation.ID
+		}
+		if len(participants) == 0 {
+			return nil
+		}
+		return tx.Create(&participants).Error
+	})
+}
+
+func (r *ConversationRepository) GetByID(ctx context.Context, id uint) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.db.WithContext(ctx).
+		Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("joined_at, id") }).
+		Preload("Participants.User.Profile").
+		First(&conversation, id).Error
+	return &conversation, err
+}
+
+func (r *ConversationRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Conversation, error) {
+	var conversations []models.Conversation
+	if len(ids) == 0 {
+		return conversations, nil
+	}
+	err := r.db.WithContext(ctx).
+		Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("joined_at, id") }).
+		Preload("Participants.User.Profile").
+		Where("id IN ?", ids).
+		Find(&conversations).Error
+	return conversations, err
+}
+
+// GetDirect returns the direct conversation between two users.
+func (r *ConversationRepository) GetDirect(ctx context.Context, userID, peerID uint) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.db.WithContext(ctx).
+		Where("direct_key = ?", models.DirectConversationKey(userID, peerID)).
+		First(&conversation).Error
+	return &conversation, err
+}
+
+func (r *ConversationRepository) Update(ctx context.Context, conversation *models.Conversation) error {
+	return r.db.WithContext(ctx).Omit("Participants").Save(conversation).Error
+}
+
+// ListForUser returns the user's conversations, most recently active first,
+// with the number of messages from others past the user's read cursor.
+func (r *ConversationRepository) ListForUser(ctx context.Context, userID uint, page, limit int) ([]ConversationSummary, error) {
+	var summaries []ConversationSummary
+	offset := (page - 1) * limit
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			c.id AS conversation_id,
+			(SELECT COUNT(*) FROM messages m
+				WHERE m.conversation_id = c.id AND m.id > p.last_read_message_id
+				AND m.sender_id <> @user AND m.deleted_at IS NULL) AS unread_count
+		FROM conversation_participants p
+		JOIN conversations c ON c.id = p.conversation_id
+		WHERE p.user_id = @user AND c.deleted_at IS NULL
+		ORDER BY c.last_message_at DESC NULLS LAST, c.id DESC
+		LIMIT @limit OFFSET @offset`,
+		map[string]interface{}{"user": userID, "limit": limit, "offset": offset},
+	).Scan(&summaries).Error
+	return summaries, err
+}
+
+func (r *ConversationRepository) GetParticipant(ctx context.Context, conversationID, userID uint) (*models.ConversationParticipant, error) {
+	var participant models.ConversationParticipant
+	err := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		First(&participant).Error
+	return &participant, err
+}
+
+// AddParticipants adds the participants, skipping users already in the
+// conversation.
+func (r *ConversationRepository) AddParticipants(ctx context.Context, participants []models.ConversationParticipant) error {
+	if len(participants) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&participants).Error
+}
+
+func (r *ConversationRepository) RemoveParticipant(ctx context.Context, conversationID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Delete(&models.ConversationParticipant{}).Error
+}
+
+func (r *ConversationRepository) UpdateParticipantRole(ctx context.Context, conversationID, userID uint, role string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("role", role).Error
+}
+
+// ListParticipantIDs returns the participants' user IDs in join order.
+func (r *ConversationRepository) ListParticipantIDs(ctx context.Context, conversationID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ?", conversationID).
+		Order("joined_at, id").
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// AdvanceReadCursor moves the participant's read cursor forward to
+// messageID. It never moves the cursor backwards.
+func (r *ConversationRepository) AdvanceReadCursor(ctx context.Context, conversationID, userID, messageID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ? AND last_read_message_id < ?", conversationID, userID, messageID).
+		Update("last_read_message_id", messageID).Error
+}
+
+// CountUnread returns the number of messages from others past the user's
+// read cursors across all of their conversations.
+func (r *ConversationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Joins("JOIN conversation_participants p ON p.conversation_id = messages.conversation_id").
+		Where("p.user_id = ? AND messages.id > p.last_read_message_id AND messages.sender_id <> ?", userID, userID).
+		Count(&count).Error
+	return count, err
+}
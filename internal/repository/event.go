@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Event repository methods
+func (r *EventRepository) Create(ctx context.Context, event *models.GroupEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *EventRepository) GetByID(ctx context.Context, id uint) (*models.GroupEvent, error) {
+	var event models.GroupEvent
+	err := r.db.WithContext(ctx).First(&event, id).Error
+	return &event, err
+}
+
+// ListByGroup returns events overlapping [from, to); a zero to means no upper
+// bound.
+func (r *EventRepository) ListByGroup(ctx context.Context, groupID uint, from, to time.Time, page, limit int) ([]models.GroupEvent, error) {
+	var events []models.GroupEvent
+	offset := (page - 1) * limit
+	query := r.db.WithContext(ctx).Where("group_id = ? AND ends_at >= ?", groupID, from)
+	if !to.IsZero() {
+		query = query.Where("starts_at < ?", to)
+	}
+	err := query.
+		Order("starts_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error
+	return events, err
+}
+
+// ListForUser returns up to limit events ending after from that the user has
+// not declined, soonest first, with the user's RSVP as their only RSVPs.
+func (r *EventRepository) ListForUser(ctx context.Context, userID uint, from time.Time, limit int) ([]models.GroupEvent, error) {
+	var events []models.GroupEvent
+	err := r.db.WithContext(ctx).
+		Preload("RSVPs", "user_id = ?", userID).
+		Joins("JOIN event_rsvps ON event_rsvps.event_id = group_events.id").
+		Where("event_rsvps.user_id = ? AND event_rsvps.status <> ?", userID, models.RSVPNotGoing).
+		Where("group_events.ends_at >= ?", from).
+		Order("group_events.starts_at ASC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// ListNeedingReminder returns upcoming events starting before the given time
+// whose reminder has not been sent yet.
+func (r *EventRepository) ListNeedingReminder(ctx context.Context, before time.Time) ([]models.GroupEvent, error) {
+	var events []models.GroupEvent
+	err := r.db.WithContext(ctx).
+		Where("reminder_sent_at IS NULL AND starts_at > ? AND starts_at <= ?", time.Now(), before).
+		Find(&events).Error
+	return events, err
+}
+
+// ClaimReminder marks the event's reminder as sent and reports whether the
+// caller did so, so only one replica sends it.
+func (r *EventRepository) ClaimReminder(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.GroupEvent{}).
+		Where("id = ? AND reminder_sent_at IS NULL", id).
+		Update("reminder_sent_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *EventRepository) Update(ctx context.Context, event *models.GroupEvent) error {
+	return r.db.WithContext(ctx).Save(event).Error
+}
+
+func (r *EventRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.GroupEvent{}, id).Error
+}
+
+// RSVP methods
+func (r *EventRepository) GetRSVP(ctx context.Context, eventID, userID uint) (*models.EventRSVP, error) {
+	var rsvp models.EventRSVP
+	err := r.db.WithContext(ctx).Where("event_id = ? AND user_id = ?", eventID, userID).First(&rsvp).Error
+	return &rsvp, err
+}
+
+// SetRSVP records the user's answer. The event row is locked so capacity is
+// enforced under concurrent RSVPs: a "going" answer beyond capacity is
+// stored as waitlisted, and a spot freed by a going user is handed to the
+// longest-waiting users, whose IDs are returned.
+func (r *EventRepository) SetRSVP(ctx context.Context, eventID, userID uint, status string) (*models.EventRSVP, []uint, error) {
+	var rsvp models.EventRSVP
+	var promoted []uint
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event models.GroupEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Where("event_id = ? AND user_id = ?", eventID, userID).First(&rsvp).Error
+		exists := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		wasGoing := exists && rsvp.Status == models.RSVPGoing
+
+		if status == models.RSVPGoing && !wasGoing && event.Capacity > 0 {
+			going, err := countRSVPs(tx, eventID, models.RSVPGoing)
+			if err != nil {
+				return err
+			}
+			status = admittedStatus(status, wasGoing, event.Capacity, going)
+		}
+
+		switch {
+		case !exists:
+			rsvp = models.EventRSVP{EventID: eventID, UserID: userID, Status: status}
+			if err := tx.Create(&rsvp).Error; err != nil {
+				return err
+			}
+		case rsvp.Status != status:
+			rsvp.Status = status
+			if err := tx.Save(&rsvp).Error; err != nil {
+				return err
+			}
+		}
+
+		if wasGoing && status != models.RSVPGoing {
+			promoted, err = promoteWaitlisted(tx, &event)
+		}
+		return err
+	})
+
+	return &rsvp, promoted, err
+}
+
+// PromoteWaitlisted fills free spots, e.g. after the capacity was raised.
+func (r *EventRepository) PromoteWaitlisted(ctx context.Context, eventID uint) ([]uint, error) {
+	var promoted []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event models.GroupEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+			return err
+		}
+		var err error
+		promoted, err = promoteWaitlisted(tx, &event)
+		return err
+	})
+	return promoted, err
+}
+
+func (r *EventRepository) ListRSVPs(ctx context.Context, eventID uint, statuses ...string) ([]models.EventRSVP, error) {
+	var rsvps []models.EventRSVP
+	err := r.db.WithContext(ctx).
+		Where("event_id = ? AND status IN ?", eventID, statuses).
+		Preload("User.Profile").
+		Order("updated_at ASC").
+		Find(&rsvps).Error
+	return rsvps, err
+}
+
+func (r *EventRepository) ListAttendeeIDs(ctx context.Context, eventID uint, statuses ...string) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.EventRSVP{}).
+		Where("event_id = ? AND status IN ?", eventID, statuses).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func countRSVPs(tx *gorm.DB, eventID uint, status string) (int64, error) {
+	var count int64
+	err := tx.Model(&models.EventRSVP{}).
+		Where("event_id = ? AND status = ?", eventID, status).
+		Count(&count).Error
+	return count, err
+}
+
+// admittedStatus is the status stored for an answer to an event with the
+// given capacity and number of going attendees: a new "going" answer to a
+// full event is waitlisted. A capacity of 0 means unlimited.
+func admittedStatus(status string, wasGoing bool, capacity int, going int64) string {
+	if status == models.RSVPGoing && !wasGoing && capacity > 0 && going >= int64(capacity) {
+		return models.RSVPWaitlisted
+	}
+	return status
+}
+
+// freeSpots is how many waitlisted users an event with a positive capacity
+// can take.
+func freeSpots(capacity int, going int64) int {
+	if free := int64(capacity) - going; free > 0 {
+		return int(free)
+	}
+	return 0
+}
+
+// promoteWaitlisted moves waitlisted users to going, in the order they were
+// waitlisted, until the event is full. The caller must hold the event lock.
+func promoteWaitlisted(tx *gorm.DB, event *models.GroupEvent) ([]uint, error) {
+	var waitlist []models.EventRSVP
+	query := tx.Where("event_id = ? AND status = ?", event.ID, models.RSVPWaitlisted).Order("updated_at ASC")
+	if event.Capacity > 0 {
+		going, err := countRSVPs(tx, event.ID, models.RSVPGoing)
+		if err != nil {
+			return nil, err
+		}
+		free := freeSpots(event.Capacity, going)
+		if free == 0 {
+			return nil, nil
+		}
+		query = query.Limit(free)
+	}
+	if err := query.Find(&waitlist).Error; err != nil {
+		return nil, err
+	}
+	if len(waitlist) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(waitlist))
+	rsvpIDs := make([]uint, 0, len(waitlist))
+	for _, w := range waitlist {
+		ids = append(ids, w.UserID)
+		rsvpIDs = append(rsvpIDs, w.ID)
+	}
+	err := tx.Model(&models.EventRSVP{}).
+		Where("id IN ?", rsvpIDs).
+		Updates(map[string]interface{}{"status": models.RSVPGoing, "updated_at": time.Now()}).Error
+	return ids, err
+}
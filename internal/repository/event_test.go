@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestAdmittedStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		wasGoing bool
+		capacity int
+		going    int64
+		want     string
+	}{
+		{"unlimited", models.RSVPGoing, false, 0, 100, models.RSVPGoing},
+		{"free spot", models.RSVPGoing, false, 3, 2, models.RSVPGoing},
+		{"full", models.RSVPGoing, false, 3, 3, models.RSVPWaitlisted},
+		{"over capacity after a decrease", models.RSVPGoing, false, 2, 3, models.RSVPWaitlisted},
+		{"already going keeps the spot", models.RSVPGoing, true, 3, 3, models.RSVPGoing},
+		{"maybe to a full event", models.RSVPMaybe, false, 3, 3, models.RSVPMaybe},
+		{"not going to a full event", models.RSVPNotGoing, false, 3, 3, models.RSVPNotGoing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := admittedStatus(tt.status, tt.wasGoing, tt.capacity, tt.going); got != tt.want {
+				t.Errorf("admittedStatus(%q, %v, %d, %d) = %q, want %q",
+					tt.status, tt.wasGoing, tt.capacity, tt.going, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeSpots(t *testing.T) {
+	tests := []struct {
+		capacity int
+		going    int64
+		want     int
+	}{
+		{5, 0, 5},
+		{5, 3, 2},
+		{5, 5, 0},
+		{2, 4, 0},
+	}
+
+	for _, tt := range tests {
+		if got := freeSpots(tt.capacity, tt.going); got != tt.want {
+			t.Errorf("freeSpots(%d, %d) = %d, want %d", tt.capacity, tt.going, got, tt.want)
+		}
+	}
+}
+
+// TestEventWaitlist runs the RSVP waitlist against the Postgres database in
+// SKILLFLOW_TEST_DATABASE_DSN, and is skipped when it is unset.
+func TestEventWaitlist(t *testing.T) {
+	dsn := os.Getenv("SKILLFLOW_TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("SKILLFLOW_TEST_DATABASE_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.GroupEvent{}, &models.EventRSVP{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	ctx := context.Background()
+	repo := &EventRepository{db: db}
+	now := time.Now()
+	event := &models.GroupEvent{
+		GroupID:   1,
+		CreatorID: 1,
+		Title:     "Waitlist test",
+		StartsAt:  now.Add(time.Hour),
+		EndsAt:    now.Add(2 * time.Hour),
+		Capacity:  2,
+	}
+	if err := repo.Create(ctx, event); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("event_id = ?", event.ID).Delete(&models.EventRSVP{})
+		db.Unscoped().Delete(&models.GroupEvent{}, event.ID)
+	})
+
+	rsvp := func(userID uint, status, want string, wantPromoted []uint) {
+		t.Helper()
+		got, promoted, err := repo.SetRSVP(ctx, event.ID, userID, status)
+		if err != nil {
+			t.Fatalf("SetRSVP(%d, %q): %v", userID, status, err)
+		}
+		if got.Status != want {
+			t.Errorf("SetRSVP(%d, %q) status = %q, want %q", userID, status, got.Status, want)
+		}
+		if !reflect.DeepEqual(promoted, wantPromoted) {
+			t.Errorf("SetRSVP(%d, %q) promoted %v, want %v", userID, status, promoted, wantPromoted)
+		}
+	}
+
+	rsvp(101, models.RSVPGoing, models.RSVPGoing, nil)
+	rsvp(102, models.RSVPGoing, models.RSVPGoing, nil)
+	rsvp(103, models.RSVPGoing, models.RSVPWaitlisted, nil)
+	// Keep the waitlist order well defined: it is by updated_at.
+	time.Sleep(10 * time.Millisecond)
+	rsvp(104, models.RSVPGoing, models.RSVPWaitlisted, nil)
+	rsvp(105, models.RSVPMaybe, models.RSVPMaybe, nil)
+
+	// A going user stepping down frees their spot for the longest waiting.
+	rsvp(101, models.RSVPMaybe, models.RSVPMaybe, []uint{103})
+	// Saying going again does not jump the queue.
+	time.Sleep(10 * time.Millisecond)
+	rsvp(101, models.RSVPGoing, models.RSVPWaitlisted, nil)
+
+	// Raising the capacity promotes the rest of the waitlist in order.
+	event.Capacity = 4
+	if err := repo.Update(ctx, event); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+	promoted, err := repo.PromoteWaitlisted(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("PromoteWaitlisted: %v", err)
+	}
+	if want := []uint{104, 101}; !reflect.DeepEqual(promoted, want) {
+		t.Errorf("PromoteWaitlisted promoted %v, want %v", promoted, want)
+	}
+
+	going, err := repo.ListAttendeeIDs(ctx, event.ID, models.RSVPGoing)
+	if err != nil {
+		t.Fatalf("ListAttendeeIDs: %v", err)
+	}
+	if len(going) != 4 {
+		t.Errorf("%d users going, want 4", len(going))
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+)
+
+// ExpertFilter selects user skills for the expert finder. A user skill
+// matches a requested skill when it is that skill or one of its descendants.
+// MinLevel applies to the verified level of skills with a valid
+// verification and to the self-declared level of the others.
+type ExpertFilter struct {
+	SkillIDs        []uint
+	MinLevel        string
+	VerifiedOnly    bool
+	MinYears        int
+	MinEndorsements int
+	Department      string // exact, ignoring case
+	Location        string // substring, ignoring case
+	ViewerID        uint   // users blocking or blocked by the viewer are excluded
+	// MinMatches is how many of the requested skills a user must match.
+	MinMatches int
+	// VerifiedBonus is added to the score of a verified match, and
+	// RelatedWeight scales the score of a match through a descendant of the
+	// requested skill.
+	VerifiedBonus float64
+	RelatedWeight float64
+}
+
+// ExpertMatch is a user skill that satisfies an ExpertFilter. Verified is
+// set while the VerifiedLevel has not expired. Endorsements counts the
+// visible endorsements and EndorsementWeight sums their weights. Score
+// rates the match as described at expertScoreSQL.
+type ExpertMatch struct {
+	UserID            uint
+	UserSkillID       uint
+	SkillID           uint
+	SkillName         string
+	RequestedSkillID  uint
+	Level             string
+	VerifiedLevel     string
+	Verified          bool
+	YearsOfExp        int
+	Endorsements      int
+	EndorsementWeight float64
+	Score             float64
+}
+
+// verifiedSQL holds for a user skill us whose verification is valid at the
+// time given as its parameter.
+const verifiedSQL = `(us.verified_level <> '' AND (us.verified_until IS NULL OR us.verified_until > ?))`
+
+// expertScoreSQL rates a match m: ten points per level, a point per year of
+// experience up to 15, two per unit of endorsement weight up to 10 and the
+// verified bonus, scaled by the related weight for descendant skills. A
+// verified level counts instead of the self-declared one. It takes the
+// verified bonus and the related weight as its parameters.
+var expertScoreSQL = `((10 * ` + levelRank("(CASE WHEN m.verified THEN m.verified_level ELSE m.level END)") + `
+	+ LEAST(m.years_of_exp, 15) + 2 * LEAST(m.endorsement_weight, 10)
+	+ CASE WHEN m.verified THEN ?::float8 ELSE 0 END)
+	* CASE WHEN m.skill_id <> m.requested_skill_id THEN ?::float8 ELSE 1 END)`
+
+// SearchExperts ranks the active users with user skills matching at least
+// MinMatches of the requested skills and the filter's thresholds by the sum
+// of their best match's score per requested skill, and returns those best
+// matches for one page of users, best user first.
+func (r *UserSkillRepository) SearchExperts(ctx context.Context, filter ExpertFilter, page, limit int) ([]ExpertMatch, error) {
+	var matches []ExpertMatch
+	if len(filter.SkillIDs) == 0 {
+		return matches, nil
+	}
+
+	now := time.Now()
+	query := r.db.WithContext(ctx).
+		Table("user_skills us").
+		Select(`us.user_id, us.id AS user_skill_id, us.skill_id, s.name AS skill_name,
+			r.requested_id AS requested_skill_id, us.level, us.years_of_exp,
+			COALESCE(us.verified_level, '') AS verified_level, COALESCE(`+verifiedSQL+`, FALSE) AS verified,
+			`+visibleEndorsementsSQL("us")+` AS endorsements,
+			`+endorsementWeightSQL("us")+` AS endorsement_weight`, now, now).
+		Joins(`JOIN (
+			WITH RECURSIVE requested AS (
+				SELECT id AS requested_id, id AS skill_id, 0 AS depth FROM skills WHERE id IN ? AND deleted_at IS NULL
+				UNION
+				SELECT r.requested_id, s.id, r.depth + 1 FROM skills s JOIN requested r ON s.parent_id = r.skill_id
+				WHERE s.deleted_at IS NULL AND r.depth < 20
+			)
+			SELECT DISTINCT requested_id, skill_id FROM requested
+		) r ON r.skill_id = us.skill_id`, filter.SkillIDs).
+		Joins("JOIN skills s ON s.id = us.skill_id").
+		Joins("JOIN users u ON u.id = us.user_id AND u.deleted_at IS NULL AND u.is_active").
+		Joins("LEFT JOIN profiles p ON p.user_id = us.user_id")
+
+	if rank := models.SkillLevelRank(filter.MinLevel); rank > 0 {
+		level := "(CASE WHEN " + verifiedSQL + " THEN us.verified_level ELSE us.level END)"
+		query = query.Where(levelRank(level)+" >= ?", now, rank)
+	}
+	if filter.VerifiedOnly {
+		query = query.Where(verifiedSQL, now)
+	}
+	if filter.MinYears > 0 {
+		query = query.Where("us.years_of_exp >= ?", filter.MinYears)
+	}
+	if filter.MinEndorsements > 0 {
+		query = query.Where(visibleEndorsementsSQL("us")+" >= ?", filter.MinEndorsements)
+	}
+	if filter.Department != "" {
+		query = query.Where("LOWER(p.department) = LOWER(?)", filter.Department)
+	}
+	if filter.Location != "" {
+		query = query.Where("p.location ILIKE ?", "%"+filter.Location+"%")
+	}
+	if filter.ViewerID != 0 {
+		query = query.Where(`NOT EXISTS (SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = ? AND b.blocked_id = us.user_id) OR (b.blocker_id = us.user_id AND b.blocked_id = ?))`,
+			filter.ViewerID, filter.ViewerID)
+	}
+
+	offset := (page - 1) * limit
+	err := r.db.WithContext(ctx).Raw(`
+		WITH scored AS (
+			SELECT m.*, `+expertScoreSQL+` AS score FROM (?) m
+		), best AS (
+			SELECT DISTINCT ON (user_id, requested_skill_id) * FROM scored
+			ORDER BY user_id, requested_skill_id, score DESC, user_skill_id
+		), ranked AS (
+			SELECT user_id, SUM(score) AS total, COUNT(*) AS matched FROM best
+			GROUP BY user_id HAVING COUNT(*) >= ?
+			ORDER BY total DESC, matched DESC, user_id
+			LIMIT ? OFFSET ?
+		)
+		SELECT best.* FROM best JOIN ranked ON ranked.user_id = best.user_id
+		ORDER BY ranked.total DESC, ranked.matched DESC, best.user_id, best.requested_skill_id`,
+		filter.VerifiedBonus, filter.RelatedWeight, query, max(filter.MinMatches, 1), limit, offset).
+		Scan(&matches).Error
+	return matches, err
+}
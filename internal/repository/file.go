@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/vern/skillflow/internal/domain/models"
+)
+
+// File repository methods
+func (r *FileRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.File, error) {
+	var files []models.File
+	if len(ids) == 0 {
+		return files, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&files).Error
+	return files, err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// Learning goal repository methods
+func (r *GoalRepository) Create(ctx context.Context, goal *models.LearningGoal) error {
+	return r.db.WithContext(ctx).Omit("User", "Skill", "Mentor", "CheckIns").Create(goal).Error
+}
+
+// GetByID returns the goal with its owner, skill, mentor and check-ins,
+// oldest check-in first.
+func (r *GoalRepository) GetByID(ctx context.Context, id uint) (*models.LearningGoal, error) {
+	var goal models.LearningGoal
+	err := r.db.WithContext(ctx).
+		Preload("User.Profile").
+		Preload("Skill").
+		Preload("Mentor.Profile").
+		Preload("CheckIns", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		Preload("CheckIns.User.Profile").
+		First(&goal, id).Error
+	return &goal, err
+}
+
+// ListByUser returns the user's goals, optionally with one status, soonest
+// due first.
+func (r *GoalRepository) ListByUser(ctx context.Context, userID uint, status string, page, limit int) ([]models.LearningGoal, error) {
+	return listGoals(r.db.WithContext(ctx).Where("learning_goals.user_id = ?", userID), status, page, limit)
+}
+
+// ListByMentor returns the goals the user mentors.
+func (r *GoalRepository) ListByMentor(ctx context.Context, mentorID uint, status string, page, limit int) ([]models.LearningGoal, error) {
+	return listGoals(r.db.WithContext(ctx).Where("learning_goals.mentor_id = ?", mentorID), status, page, limit)
+}
+
+// ListSharedWithManager returns the shared goals of the users whose profile
+// names the manager.
+func (r *GoalRepository) ListSharedWithManager(ctx context.Context, managerID uint, status string, page, limit int) ([]models.LearningGoal, error) {
+	query := r.db.WithContext(ctx).
+		Joins("JOIN profiles p ON p.user_id = learning_goals.user_id").
+		Where("p.manager_id = ? AND learning_goals.shared", managerID)
+	return listGoals(query, status, page, limit)
+}
+
+// listGoals pages through the goals the query selects, soonest due first.
+func listGoals(query *gorm.DB, status string, page, limit int) ([]models.LearningGoal, error) {
+	var goals []models.LearningGoal
+	if status != "" {
+		query = query.Where("learning_goals.status = ?", status)
+	}
+	offset := (page - 1) * limit
+	err := query.
+		Preload("User.Profile").
+		Preload("Skill").
+		Preload("Mentor.Profile").
+		Order("learning_goals.due_date, learning_goals.id").
+		Limit(limit).
+		Offset(offset).
+		Find(&goals).Error
+	return goals, err
+}
+
+// ExistsActive reports whether the user has an active goal for the skill.
+func (r *GoalRepository) ExistsActive(ctx context.Context, userID, skillID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.LearningGoal{}).
+		Where("user_id = ? AND skill_id = ? AND status = ?", userID, skillID, models.GoalStatusActive).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GoalRepository) Update(ctx context.Context, goal *models.LearningGoal) error {
+	return r.db.WithContext(ctx).Omit("User", "Skill", "Mentor", "CheckIns").Save(goal).Error
+}
+
+// Delete removes the goal together with its check-ins.
+func (r *GoalRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", id).Delete(&models.GoalCheckIn{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.LearningGoal{}, id).Error
+	})
+}
+
+// AddCheckIn saves the check-in and the goal it updates in one transaction.
+func (r *GoalRepository) AddCheckIn(ctx context.Context, goal *models.LearningGoal, checkIn *models.GoalCheckIn) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User").Create(checkIn).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User", "Skill", "Mentor", "CheckIns").Save(goal).Error
+	})
+}
+
+// ListNeedingReminder returns active goals falling due before the given
+// time whose deadline reminder has not been sent yet.
+func (r *GoalRepository) ListNeedingReminder(ctx context.Context, before time.Time) ([]models.LearningGoal, error) {
+	var goals []models.LearningGoal
+	err := r.db.WithContext(ctx).
+		Preload("Skill").
+		Where("status = ? AND reminder_sent_at IS NULL AND due_date > ? AND due_date <= ?",
+			models.GoalStatusActive, time.Now(), before).
+		Find(&goals).Error
+	return goals, err
+}
+
+// ClaimReminder marks the goal's reminder as sent and reports whether the
+// caller did so, so only one replica sends it.
+func (r *GoalRepository) ClaimReminder(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.LearningGoal{}).
+		Where("id = ? AND reminder_sent_at IS NULL", id).
+		Update("reminder_sent_at", at)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// GroupFilter narrows GroupRepository.List results.
+type GroupFilter struct {
+	Query    string
+	MemberID uint // only groups this user belongs to
+	ViewerID uint // hide secret groups this user does not belong to
+}
+
+// Group repository methods
+func (r *GroupRepository) CreateWithOwner(ctx context.Context, group *models.Group, ownerID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.GroupMember{
+			GroupID:  group.ID,
+			UserID:   ownerID,
+			Role:     models.GroupRoleOwner,
+			JoinedAt: time.Now(),
+		}).Error
+	})
+}
+
+func (r *GroupRepository) GetByID(ctx context.Context, id uint) (*models.Group, error) {
+	var group models.Group
+	err := r.db.WithContext(ctx).Preload("Creator.Profile").First(&group, id).Error
+	return &group, err
+}
+
+func (r *GroupRepository) List(ctx context.Context, filter GroupFilter, page, limit int) ([]models.Group, error) {
+	var groups []models.Group
+	offset := (page - 1) * limit
+	query := r.db.WithContext(ctx).Model(&models.Group{})
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("groups.name ILIKE ? OR groups.description ILIKE ?", like, like)
+	}
+	if filter.MemberID != 0 {
+		query = query.Where("groups.id IN (SELECT group_id FROM group_members WHERE user_id = ?)", filter.MemberID)
+	}
+	if filter.ViewerID != 0 {
+		query = query.Where("groups.visibility <> ? OR groups.id IN (SELECT group_id FROM group_members WHERE user_id = ?)",
+			models.GroupVisibilitySecret, filter.ViewerID)
+	}
+	err := query.
+		Order("groups.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&groups).Error
+	return groups, err
+}
+
+func (r *GroupRepository) Update(ctx context.Context, group *models.Group) error {
+	return r.db.WithContext(ctx).Save(group).Error
+}
+
+func (r *GroupRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Group{}, id).Error
+}
+
+// Group member repository methods
+func (r *GroupMemberRepository) Create(ctx context.Context, member *models.GroupMember) error {
+	return r.db.WithContext(ctx).Create(member).Error
+}
+
+func (r *GroupMemberRepository) GetByGroupAndUser(ctx context.Context, groupID, userID uint) (*models.GroupMember, error) {
+	var member models.GroupMember
+	err := r.db.WithContext(ctx).Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error
+	return &member, err
+}
+
+// ListByGroup returns members ordered by role (owners first), then join date.
+func (r *GroupMemberRepository) ListByGroup(ctx context.Context, groupID uint, page, limit int) ([]models.GroupMember, error) {
+	var members []models.GroupMember
+	offset := (page - 1) * limit
+	err := r.db.WithContext(ctx).
+		Where("group_id = ?", groupID).
+		Preload("User.Profile").
+		Order(gorm.Expr("CASE role WHEN ? THEN 0 WHEN ? THEN 1 WHEN ? THEN 2 ELSE 3 END",
+			models.GroupRoleOwner, models.GroupRoleAdmin, models.GroupRoleModerator)).
+		Order("joined_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&members).Error
+	return members, err
+}
+
+func (r *GroupMemberRepository) CountByRole(ctx context.Context, groupID uint, role string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.GroupMember{}).
+		Where("group_id = ? AND role = ?", groupID, role).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *GroupMemberRepository) ListUserIDsByRoles(ctx context.Context, groupID uint, roles ...string) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.GroupMember{}).
+		Where("group_id = ? AND role IN ?", groupID, roles).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *GroupMemberRepository) UpdateRole(ctx context.Context, groupID, userID uint, role string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Update("role", role).Error
+}
+
+func (r *GroupMemberRepository) Delete(ctx context.Context, groupID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Delete(&models.GroupMember{}).Error
+}
+
+// TransferOwnership makes toUserID an owner and demotes fromUserID to admin
+// in a single transaction.
+func (r *GroupMemberRepository) TransferOwnership(ctx context.Context, groupID, fromUserID, toUserID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.GroupMember{}).
+			Where("group_id = ? AND user_id = ?", groupID, toUserID).
+			Update("role", models.GroupRoleOwner).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.GroupMember{}).
+			Where("group_id = ? AND user_id = ?", groupID, fromUserID).
+			Update("role", models.GroupRoleAdmin).Error
+	})
+}
+
+// Ban removes the user's membership, if any, and records the ban.
+func (r *GroupMemberRepository) Ban(ctx context.Context, ban *models.GroupBan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ? AND user_id = ?", ban.GroupID, ban.UserID).
+			Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(ban).Error
+	})
+}
+
+func (r *GroupMemberRepository) Unban(ctx context.Context, groupID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Delete(&models.GroupBan{}).Error
+}
+
+func (r *GroupMemberRepository) IsBanned(ctx context.Context, groupID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.GroupBan{}).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
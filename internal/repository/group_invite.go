@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// ErrInviteLinkExhausted is returned by RedeemLink when the link was used up,
+// expired or revoked concurrently.
+var ErrInviteLinkExhausted = errors.New("invite link is no longer valid")
+
+// ErrInviteNotPending is returned when a join request or invitation was
+// answered concurrently and is no longer pending.
+var ErrInviteNotPending = errors.New("join request or invitation is no longer pending")
+
+// Join request methods
+func (r *GroupInviteRepository) CreateJoinRequest(ctx context.Context, req *models.GroupJoinRequest) error {
+	return r.db.WithContext(ctx).Create(req).Error
+}
+
+func (r *GroupInviteRepository) GetJoinRequest(ctx context.Context, id uint) (*models.GroupJoinRequest, error) {
+	var req models.GroupJoinRequest
+	err := r.db.WithContext(ctx).First(&req, id).Error
+	return &req, err
+}
+
+func (r *GroupInviteRepository) GetPendingJoinRequest(ctx context.Context, groupID, userID uint) (*models.GroupJoinRequest, error) {
+	var req models.GroupJoinRequest
+	err := r.db.WithContext(ctx).
+		Where("group_id = ? AND user_id = ? AND status = ?", groupID, userID, models.InviteStatusPending).
+		First(&req).Error
+	return &req, err
+}
+
+func (r *GroupInviteRepository) ListJoinRequests(ctx context.Context, groupID uint, status string, page, limit int) ([]models.GroupJoinRequest, error) {
+	var reqs []models.GroupJoinRequest
+	offset := (page - 1) * limit
+	err := r.db.WithContext(ctx).
+		Where("group_id = ? AND status = ?", groupID, status).
+		Preload("User.Profile").
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&reqs).Error
+	return reqs, err
+}
+
+// ApproveJoinRequest marks the request approved and adds the requester as a
+// member in one transaction.
+func (r *GroupInviteRepository) ApproveJoinRequest(ctx context.Context, req *models.GroupJoinRequest, reviewerID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := reviewJoinRequest(tx, req, reviewerID, models.InviteStatusAccepted); err != nil {
+			return err
+		}
+		return addMember(tx, req.GroupID, req.UserID)
+	})
+}
+
+func (r *GroupInviteRepository) DeclineJoinRequest(ctx context.Context, req *models.GroupJoinRequest, reviewerID uint) error {
+	return reviewJoinRequest(r.db.WithContext(ctx), req, reviewerID, models.InviteStatusDeclined)
+}
+
+// Invitation methods
+func (r *GroupInviteRepository) CreateInvitation(ctx context.Context, inv *models.GroupInvitation) error {
+	return r.db.WithContext(ctx).Create(inv).Error
+}
+
+func (r *GroupInviteRepository) GetInvitation(ctx context.Context, id uint) (*models.GroupInvitation, error) {
+	var inv models.GroupInvitation
+	err := r.db.WithContext(ctx).First(&inv, id).Error
+	return &inv, err
+}
+
+func (r *GroupInviteRepository) GetPendingInvitation(ctx context.Context, groupID, inviteeID uint) (*models.GroupInvitation, error) {
+	var inv models.GroupInvitation
+	err := r.db.WithContext(ctx).
+		Where("group_id = ? AND invitee_id = ? AND status = ?", groupID, inviteeID, models.InviteStatusPending).
+		First(&inv).Error
+	return &inv, err
+}
+
+func (r *GroupInviteRepository) ListInvitationsForUser(ctx context.Context, inviteeID uint, status string) ([]models.GroupInvitation, error) {
+	var invs []models.GroupInvitation
+	err := r.db.WithContext(ctx).
+		Where("invitee_id = ? AND status = ?", inviteeID, status).
+		Preload("Group").
+		Preload("Inviter.Profile").
+		Order("created_at DESC").
+		Find(&invs).Error
+	return invs, err
+}
+
+func (r *GroupInviteRepository) AcceptInvitation(ctx context.Context, inv *models.GroupInvitation) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := answerInvitation(tx, inv, models.InviteStatusAccepted); err != nil {
+			return err
+		}
+		return addMember(tx, inv.GroupID, inv.InviteeID)
+	})
+}
+
+func (r *GroupInviteRepository) DeclineInvitation(ctx context.Context, inv *models.GroupInvitation) error {
+	return answerInvitation(r.db.WithContext(ctx), inv, models.InviteStatusDeclined)
+}
+
+// Invite link methods
+func (r *GroupInviteRepository) CreateLink(ctx context.Context, link *models.GroupInviteLink) error {
+	return r.db.WithContext(ctx).Create(link).Error
+}
+
+func (r *GroupInviteRepository) GetLinkByID(ctx context.Context, id uint) (*models.GroupInviteLink, error) {
+	var link models.GroupInviteLink
+	err := r.db.WithContext(ctx).First(&link, id).Error
+	return &link, err
+}
+
+func (r *GroupInviteRepository) GetLinkByToken(ctx context.Context, token string) (*models.GroupInviteLink, error) {
+	var link models.GroupInviteLink
+	err := r.db.WithContext(ctx).Where("token = ?", token).First(&link).Error
+	return &link, err
+}
+
+func (r *GroupInviteRepository) ListLinks(ctx context.Context, groupID uint) ([]models.GroupInviteLink, error) {
+	var links []models.GroupInviteLink
+	err := r.db.WithContext(ctx).
+		Where("group_id = ? AND revoked_at IS NULL", groupID).
+		Order("created_at DESC").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *GroupInviteRepository) RevokeLink(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.GroupInviteLink{}).
+		Where("id = ?", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RedeemLink consumes one use of the link and adds the user as a member. The
+// use counter is incremented with a conditional update so concurrent
+// redemptions cannot exceed MaxUses.
+func (r *GroupInviteRepository) RedeemLink(ctx context.Context, link *models.GroupInviteLink, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.GroupInviteLink{}).
+			Where("id = ? AND revoked_at IS NULL", link.ID).
+			Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+			Where("max_uses = 0 OR uses < max_uses").
+			Update("uses", gorm.Expr("uses + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInviteLinkExhausted
+		}
+		return addMember(tx, link.GroupID, userID)
+	})
+}
+
+// reviewJoinRequest and answerInvitation only change pending rows, so of two
+// concurrent answers the second gets ErrInviteNotPending.
+func reviewJoinRequest(tx *gorm.DB, req *models.GroupJoinRequest, reviewerID uint, status string) error {
+	now := time.Now()
+	result := tx.Model(&models.GroupJoinRequest{}).
+		Where("id = ? AND status = ?", req.ID, models.InviteStatusPending).
+		Updates(map[string]interface{}{"status": status, "reviewed_by_id": reviewerID, "reviewed_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInviteNotPending
+	}
+	req.Status = status
+	req.ReviewedByID = &reviewerID
+	req.ReviewedAt = &now
+	return nil
+}
+
+func answerInvitation(tx *gorm.DB, inv *models.GroupInvitation, status string) error {
+	now := time.Now()
+	result := tx.Model(&models.GroupInvitation{}).
+		Where("id = ? AND status = ?", inv.ID, models.InviteStatusPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInviteNotPending
+	}
+	inv.Status = status
+	inv.RespondedAt = &now
+	return nil
+}
+
+func addMember(tx *gorm.DB, groupID, userID uint) error {
+	return tx.Create(&models.GroupMember{
+		GroupID:  groupID,
+		UserID:   userID,
+		Role:     models.GroupRoleMember,
+		JoinedAt: time.Now(),
+	}).Error
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// openMentorshipStatuses are the statuses of mentorships that are still
+// running or awaiting an answer; takenMentorshipStatuses those that use up
+// the mentor's capacity.
+var (
+	openMentorshipStatuses  = []string{models.MentorshipStatusPending, models.MentorshipStatusActive, models.MentorshipStatusPaused}
+	takenMentorshipStatuses = []string{models.MentorshipStatusActive, models.MentorshipStatusPaused}
+)
+
+// ErrMentorshipChanged is returned by UpdateStatus and Accept when the
+// mentorship's status was changed concurrently.
+var ErrMentorshipChanged = errors.New("mentorship status changed concurrently")
+
+// menteesSQL counts the mentees taking up the capacity of mentor offer o.
+const menteesSQL = `(SELECT COUNT(*) FROM mentorships m
+	WHERE m.mentor_id = o.user_id AND m.skill_id = o.skill_id AND m.status IN ?)`
+
+// MentorFilter selects the mentor offers suggested to a mentee for a skill.
+// MinLevel applies like ExpertFilter's.
+type MentorFilter struct {
+	SkillID  uint
+	MenteeID uint
+	MinLevel string
+}
+
+// MentorCandidate is an available mentor offer with the mentor's skill, how
+// many mentees they have in it and their department.
+type MentorCandidate struct {
+	OfferID           uint
+	UserID            uint
+	UserSkillID       uint
+	Capacity          int
+	Mentees           int
+	Level             string
+	VerifiedLevel     string
+	Verified          bool
+	YearsOfExp        int
+	Endorsements      int
+	EndorsementWeight float64
+	Department        string
+}
+
+// Mentor offer methods
+func (r *MentorshipRepository) CreateOffer(ctx context.Context, offer *models.MentorOffer) error {
+	return r.db.WithContext(ctx).Omit("User", "Skill").Create(offer).Error
+}
+
+func (r *MentorshipRepository) GetOffer(ctx context.Context, userID, skillID uint) (*models.MentorOffer, error) {
+	var offer models.MentorOffer
+	err := r.db.WithContext(ctx).
+		Preload("Skill").
+		Where("user_id = ? AND skill_id = ?", userID, skillID).
+		First(&offer).Error
+	return &offer, err
+}
+
+// ListOffers returns the user's mentor offers by skill name, with the number
+// of mentees in each.
+func (r *MentorshipRepository) ListOffers(ctx context.Context, userID uint) ([]models.MentorOffer, error) {
+	var offers []models.MentorOffer
+	err := r.db.WithContext(ctx).
+		Preload("Skill").
+		Joins("JOIN skills ON skills.id = mentor_offers.skill_id").
+		Where("mentor_offers.user_id = ?", userID).
+		Order("skills.name").
+		Find(&offers).Error
+	if err != nil || len(offers) == 0 {
+		return offers, err
+	}
+
+	var counts []struct {
+		SkillID uint
+		Mentees int
+	}
+	err = r.db.WithContext(ctx).
+		Model(&models.Mentorship{}).
+		Select("skill_id, COUNT(*) AS mentees").
+		Where("mentor_id = ? AND status IN ?", userID, takenMentorshipStatuses).
+		Group("skill_id").
+		Scan(&counts).Error
+	for _, count := range counts {
+		for i := range offers {
+			if offers[i].SkillID == count.SkillID {
+				offers[i].Mentees = count.Mentees
+			}
+		}
+	}
+	return offers, err
+}
+
+func (r *MentorshipRepository) UpdateOffer(ctx context.Context, offer *models.MentorOffer) error {
+	return r.db.WithContext(ctx).Omit("User", "Skill").Save(offer).Error
+}
+
+// DeleteOffer withdraws the offer and declines the requests still pending
+// for it, which are returned. Running mentorships are kept.
+func (r *MentorshipRepository) DeleteOffer(ctx context.Context, offer *models.MentorOffer) ([]models.Mentorship, error) {
+	var declined []models.Mentorship
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.MentorOffer{}, offer.ID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&declined).
+			Clauses(clause.Returning{}).
+			Where("mentor_id = ? AND skill_id = ? AND status = ?", offer.UserID, offer.SkillID, models.MentorshipStatusPending).
+			Updates(map[string]interface{}{
+				"status":     models.MentorshipStatusDeclined,
+				"updated_at": time.Now(),
+			}).Error
+	})
+	return declined, err
+}
+
+// SearchMentors returns the available offers for the filter's skill whose
+// mentor has a free spot, holds the skill at MinLevel or above and has no
+// open mentorship with the mentee in it. Mentors blocking or blocked by the
+// mentee are left out.
+func (r *MentorshipRepository) SearchMentors(ctx context.Context, filter MentorFilter) ([]MentorCandidate, error) {
+	var candidates []MentorCandidate
+	now := time.Now()
+	query := r.db.WithContext(ctx).
+		Table("mentor_offers o").
+		Select(`o.id AS offer_id, o.user_id, o.capacity, `+menteesSQL+` AS mentees,
+			us.id AS user_skill_id, us.level, us.years_of_exp,
+			COALESCE(us.verified_level, '') AS verified_level, COALESCE(`+verifiedSQL+`, FALSE) AS verified,
+			`+visibleEndorsementsSQL("us")+` AS endorsements,
+			`+endorsementWeightSQL("us")+` AS endorsement_weight,
+			COALESCE(p.department, '') AS department`, takenMentorshipStatuses, now, now).
+		Joins("JOIN user_skills us ON us.user_id = o.user_id AND us.skill_id = o.skill_id").
+		Joins("JOIN users u ON u.id = o.user_id AND u.deleted_at IS NULL AND u.is_active").
+		Joins("LEFT JOIN profiles p ON p.user_id = o.user_id").
+		Where("o.skill_id = ? AND o.available AND o.user_id <> ?", filter.SkillID, filter.MenteeID).
+		Where(menteesSQL+" < o.capacity", takenMentorshipStatuses).
+		Where(`NOT EXISTS (SELECT 1 FROM mentorships m
+			WHERE m.mentor_id = o.user_id AND m.mentee_id = ? AND m.skill_id = o.skill_id AND m.status IN ?)`,
+			filter.MenteeID, openMentorshipStatuses).
+		Where(`NOT EXISTS (SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = ? AND b.blocked_id = o.user_id) OR (b.blocker_id = o.user_id AND b.blocked_id = ?))`,
+			filter.MenteeID, filter.MenteeID)
+
+	if rank := models.SkillLevelRank(filter.MinLevel); rank > 0 {
+		level := "(CASE WHEN " + verifiedSQL + " THEN us.verified_level ELSE us.level END)"
+		query = query.Where(levelRank(level)+" >= ?", now, rank)
+	}
+
+	err := query.Scan(&candidates).Error
+	return candidates, err
+}
+
+// Mentorship methods
+func (r *MentorshipRepository) Create(ctx context.Context, mentorship *models.Mentorship) error {
+	return r.db.WithContext(ctx).Omit("Mentor", "Mentee", "Skill").Create(mentorship).Error
+}
+
+func (r *MentorshipRepository) GetByID(ctx context.Context, id uint) (*models.Mentorship, error) {
+	var mentorship models.Mentorship
+	err := r.db.WithContext(ctx).
+		Preload("Mentor.Profile").
+		Preload("Mentee.Profile").
+		Preload("Skill").
+		First(&mentorship, id).Error
+	return &mentorship, err
+}
+
+// ExistsOpen reports whether the mentee has a pending, active or paused
+// mentorship with the mentor in the skill.
+func (r *MentorshipRepository) ExistsOpen(ctx context.Context, mentorID, menteeID, skillID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Mentorship{}).
+		Where("mentor_id = ? AND mentee_id = ? AND skill_id = ? AND status IN ?",
+			mentorID, menteeID, skillID, openMentorshipStatuses).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List returns the user's mentorships as mentor, as mentee, or both when
+// role is empty, optionally with one status, most recently updated first.
+func (r *MentorshipRepository) List(ctx context.Context, userID uint, role, status string, page, limit int) ([]models.Mentorship, error) {
+	var mentorships []models.Mentorship
+	query := r.db.WithContext(ctx)
+	switch role {
+	case "mentor":
+		query = query.Where("mentor_id = ?", userID)
+	case "mentee":
+		query = query.Where("mentee_id = ?", userID)
+	default:
+		query = query.Where("mentor_id = ? OR mentee_id = ?", userID, userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	offset := (page - 1) * limit
+	err := query.
+		Preload("Mentor.Profile").
+		Preload("Mentee.Profile").
+		Preload("Skill").
+		Order("updated_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&mentorships).Error
+	return mentorships, err
+}
+
+// UpdateStatus saves a status change of the mentorship if its status is
+// still from, and returns ErrMentorshipChanged otherwise.
+func (r *MentorshipRepository) UpdateStatus(ctx context.Context, mentorship *models.Mentorship, from string) error {
+	return updateMentorshipStatus(r.db.WithContext(ctx), mentorship, from)
+}
+
+// Accept activates a pending mentorship if the mentor's offer for the skill
+// has a free spot and reports whether it did. The offer row is locked so
+// capacity holds under concurrent accepts; a withdrawn offer yields
+// gorm.ErrRecordNotFound.
+func (r *MentorshipRepository) Accept(ctx context.Context, mentorship *models.Mentorship) (bool, error) {
+	accepted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var offer models.MentorOffer
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND skill_id = ?", mentorship.MentorID, mentorship.SkillID).
+			First(&offer).Error
+		if err != nil {
+			return err
+		}
+		var mentees int64
+		err = tx.Model(&models.Mentorship{}).
+			Where("mentor_id = ? AND skill_id = ? AND status IN ?", mentorship.MentorID, mentorship.SkillID, takenMentorshipStatuses).
+			Count(&mentees).Error
+		if err != nil || mentees >= int64(offer.Capacity) {
+			return err
+		}
+
+		now := time.Now()
+		mentorship.Status = models.MentorshipStatusActive
+		mentorship.AcceptedAt = &now
+		if err := updateMentorshipStatus(tx, mentorship, models.MentorshipStatusPending); err != nil {
+			return err
+		}
+		accepted = true
+		return nil
+	})
+	return accepted, err
+}
+
+func updateMentorshipStatus(tx *gorm.DB, mentorship *models.Mentorship, from string) error {
+	result := tx.Model(mentorship).
+		Where("status = ?", from).
+		Select("status", "paused_by_id", "accepted_at", "ended_at", "updated_at").
+		Updates(mentorship)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMentorshipChanged
+	}
+	return nil
+}
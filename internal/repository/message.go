@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Message repository methods
+// Create stores the message and, in the same transaction, makes it the
+// conversation's last message and advances the sender's read cursor past it.
+func (r *MessageRepository) Create(ctx context.Context, message *models.Message) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Attachments.*").Create(message).Error; err != nil {
+			return err
+		}
+		if message.ConversationID == nil {
+			return nil
+		}
+		if err := tx.Model(&models.Conversation{}).
+			Where("id = ?", *message.ConversationID).
+			Updates(map[string]interface{}{"last_message_id": message.ID, "last_message_at": message.CreatedAt}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ConversationParticipant{}).
+			Where("conversation_id = ? AND user_id = ?", *message.ConversationID, message.SenderID).
+			Update("last_read_message_id", message.ID).Error
+	})
+}
+
+func (r *MessageRepository) GetByID(ctx context.Context, id uint) (*models.Message, error) {
+	var message models.Message
+	err := r.withDetails(r.db.WithContext(ctx)).First(&message, id).Error
+	return &message, err
+}
+
+func (r *MessageRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Message, error) {
+	var messages []models.Message
+	if len(ids) == 0 {
+		return messages, nil
+	}
+	err := r.withDetails(r.db.WithContext(ctx)).Where("id IN ?", ids).Find(&messages).Error
+	return messages, err
+}
+
+// GetHistory returns up to limit messages of the conversation, newest first,
+// leaving out messages the viewer deleted for themselves. A non-zero beforeID
+// pages backwards from that message.
+func (r *MessageRepository) GetHistory(ctx context.Context, conversationID, viewerID, beforeID uint, limit int) ([]models.Message, error) {
+	var messages []models.Message
+	query := r.db.WithContext(ctx).
+		Where("conversation_id = ?", conversationID).
+		Where("id NOT IN (SELECT message_id FROM message_hides WHERE user_id = ?)", viewerID)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	err := r.withDetails(query).
+		Preload("Sender.Profile").
+		Order("id DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// MarkReadUpTo marks every unread message addressed to receiverID in the
+// conversation with an ID up to and including upToID as read.
+func (r *MessageRepository) MarkReadUpTo(ctx context.Context, conversationID, receiverID, upToID uint, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Where("conversation_id = ? AND receiver_id = ? AND id <= ? AND read_at IS NULL", conversationID, receiverID, upToID).
+		Updates(map[string]interface{}{"is_read": true, "read_at": at})
+	return result.RowsAffected, result.Error
+}
+
+// Edit replaces the message content and marks it as edited.
+func (r *MessageRepository) Edit(ctx context.Context, message *models.Message) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Message{ID: message.ID}).
+		Updates(map[string]interface{}{
+			"content":   message.Content,
+			"is_edited": message.IsEdited,
+			"edited_at": message.EditedAt,
+		}).Error
+}
+
+// Delete soft-deletes the message for everyone. If it was the conversation's
+// last message, the previous one takes its place.
+func (r *MessageRepository) Delete(ctx context.Context, message *models.Message) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Message{}, message.ID).Error; err != nil {
+			return err
+		}
+		if message.ConversationID == nil {
+			return nil
+		}
+		var previous models.Message
+		err := tx.Where("conversation_id = ?", *message.ConversationID).Order("id DESC").Take(&previous).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		updates := map[string]interface{}{"last_message_id": nil, "last_message_at": nil}
+		if err == nil {
+			updates = map[string]interface{}{"last_message_id": previous.ID, "last_message_at": previous.CreatedAt}
+		}
+		return tx.Model(&models.Conversation{}).
+			Where("id = ? AND last_message_id = ?", *message.ConversationID, message.ID).
+			Updates(updates).Error
+	})
+}
+
+// Hide deletes the message for one user only.
+func (r *MessageRepository) Hide(ctx context.Context, messageID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.MessageHide{MessageID: messageID, UserID: userID}).Error
+}
+
+// AddReaction stores the reaction unless the user already reacted with the
+// same emoji.
+func (r *MessageRepository) AddReaction(ctx context.Context, reaction *models.MessageReaction) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(reaction).Error
+}
+
+func (r *MessageRepository) RemoveReaction(ctx context.Context, messageID, userID uint, emoji string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Delete(&models.MessageReaction{})
+	return result.RowsAffected, result.Error
+}
+
+// withDetails preloads what clients render with a message: the quoted
+// message, reactions and attachments.
+func (r *MessageRepository) withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("ReplyTo").
+		Preload("Reactions", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Attachments")
+}
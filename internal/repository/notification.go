@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Notification repository methods
+func (r *NotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *NotificationRepository) GetByID(ctx context.Context, id uint) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.WithContext(ctx).First(&notification, id).Error
+	return &notification, err
+}
+
+// Merge locks the user's latest unread notification with the group key that
+// was created after since and passes it to merge, saving it if merge reports
+// a change. It returns nil when there is no such notification.
+func (r *NotificationRepository) Merge(ctx context.Context, userID uint, groupKey string, since time.Time, merge func(*models.Notification) bool) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND group_key = ? AND is_read = ? AND created_at > ?", userID, groupKey, false, since).
+			Order("id DESC").
+			First(&notification).Error
+		if err != nil {
+			return err
+		}
+		if !merge(&notification) {
+			return nil
+		}
+		return tx.Save(&notification).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// List returns the user's visible notifications, most recently active
+// first.
+func (r *NotificationRepository) List(ctx context.Context, userID uint, unreadOnly bool, page, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	offset := (page - 1) * limit
+	query := r.db.WithContext(ctx).
+		Preload("Actor.Profile").
+		Where("user_id = ? AND hidden = ?", userID, false)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	err := query.
+		Order("updated_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// MarkRead marks one of the user's notifications as read and reports whether
+// it was unread.
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND is_read = ?", id, userID, false).
+		Update("is_read", true)
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ? AND hidden = ?", userID, false, false).
+		Update("is_read", true)
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ? AND hidden = ?", userID, false, false).
+		Count(&count).Error
+	return count, err
+}
+
+// Notification preference methods
+
+// GetPreferences returns the user's preference for every category, using
+// the defaults for categories they never changed.
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error) {
+	var stored []models.NotificationPreference
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	byCategory := make(map[string]models.NotificationPreference, len(stored))
+	for _, p := range stored {
+		byCategory[p.Category] = p
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(models.NotificationCategories))
+	for _, category := range models.NotificationCategories {
+		p, ok := byCategory[category]
+		if !ok {
+			p = *models.DefaultNotificationPreference(userID, category)
+		}
+		preferences = append(preferences, p)
+	}
+	return preferences, nil
+}
+
+// GetPreferencesFor returns each user's preference for one category, keyed
+// by user ID.
+func (r *NotificationRepository) GetPreferencesFor(ctx context.Context, userIDs []uint, category string) (map[uint]*models.NotificationPreference, error) {
+	result := make(map[uint]*models.NotificationPreference, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var stored []models.NotificationPreference
+	if err := r.db.WithContext(ctx).
+		Where("user_id IN ? AND category = ?", userIDs, category).
+		Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	for i := range stored {
+		result[stored[i].UserID] = &stored[i]
+	}
+	for _, id := range userIDs {
+		if _, ok := result[id]; !ok {
+			result[id] = models.DefaultNotificationPreference(id, category)
+		}
+	}
+	return result, nil
+}
+
+func (r *NotificationRepository) SavePreferences(ctx context.Context, preferences []models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "category"}},
+			DoUpdates: clause.AssignmentColumns([]string{"in_app", "push", "email", "digest", "updated_at"}),
+		}).
+		Create(&preferences).Error
+}
+
+// GetSettings returns the stored settings or the defaults when the user has
+// never changed them.
+func (r *NotificationRepository) GetSettings(ctx context.Context, userID uint) (*models.NotificationSettings, error) {
+	var settings models.NotificationSettings
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationSettings(userID), nil
+	}
+	return &settings, err
+}
+
+func (r *NotificationRepository) GetSettingsFor(ctx context.Context, userIDs []uint) (map[uint]*models.NotificationSettings, error) {
+	result := make(map[uint]*models.NotificationSettings, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var stored []models.NotificationSettings
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	for i := range stored {
+		result[stored[i].UserID] = &stored[i]
+	}
+	for _, id := range userIDs {
+		if _, ok := result[id]; !ok {
+			result[id] = models.DefaultNotificationSettings(id)
+		}
+	}
+	return result, nil
+}
+
+func (r *NotificationRepository) SaveSettings(ctx context.Context, settings *models.NotificationSettings) error {
+	return r.db.WithContext(ctx).Save(settings).Error
+}
+
+// Delivery methods
+
+// ListForDigest returns the user's unread notifications still waiting for a
+// digest that were active since the given time, oldest first.
+func (r *NotificationRepository) ListForDigest(ctx context.Context, userID uint, since time.Time, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.db.WithContext(ctx).
+		Preload("Actor.Profile").
+		Where("user_id = ? AND digest = ? AND is_read = ? AND updated_at >= ?", userID, true, false, since).
+		Order("updated_at, id").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// ClearDigest marks the notifications as delivered through a digest.
+func (r *NotificationRepository) ClearDigest(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("id IN ?", ids).
+		Update("digest", false).Error
+}
+
+// ListPendingEmail returns notifications still waiting for an e-mail, in ID
+// order starting after afterID.
+func (r *NotificationRepository) ListPendingEmail(ctx context.Context, afterID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Actor.Profile").
+		Where("id > ? AND email = ?", afterID, true).
+		Order("id").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// ClaimEmail clears the notification's pending e-mail flag and reports
+// whether this call cleared it, so only one sender delivers it.
+func (r *NotificationRepository) ClaimEmail(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("id = ? AND email = ?", id, true).
+		Update("email", false)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ClaimDigest records that the user's digest for the period is being sent
+// and reports whether the caller owns it. A claim that never completed is
+// taken over once it is older than staleBefore.
+func (r *NotificationRepository) ClaimDigest(ctx context.Context, userID uint, period string, now, staleBefore time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO digest_deliveries (user_id, period, claimed_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id, period) DO UPDATE SET claimed_at = EXCLUDED.claimed_at
+		WHERE digest_deliveries.sent_at IS NULL AND digest_deliveries.claimed_at < ?`,
+		userID, period, now, staleBefore)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *NotificationRepository) MarkDigestSent(ctx context.Context, userID uint, period string, items int, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DigestDelivery{}).
+		Where("user_id = ? AND period = ?", userID, period).
+		Updates(map[string]interface{}{"sent_at": at, "items": items}).Error
+}
+
+// Push subscription methods
+
+// SavePushSubscription stores the subscription, taking over an existing row
+// with the same endpoint, e.g. when another user signs in on the browser.
+func (r *NotificationRepository) SavePushSubscription(ctx context.Context, subscription *models.PushSubscription) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "endpoint"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "user_agent"}),
+		}).
+		Create(subscription).Error
+}
+
+func (r *NotificationRepository) ListPushSubscriptions(ctx context.Context, userID uint) ([]models.PushSubscription, error) {
+	var subscriptions []models.PushSubscription
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *NotificationRepository) DeletePushSubscription(ctx context.Context, id, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&models.PushSubscription{})
+	return result.RowsAffected, result.Error
+}
+
+// DeletePushSubscriptionByEndpoint removes a subscription the push service
+// reported as gone.
+func (r *NotificationRepository) DeletePushSubscriptionByEndpoint(ctx context.Context, endpoint string) error {
+	return r.db.WithContext(ctx).
+		Where("endpoint = ?", endpoint).
+		Delete(&models.PushSubscription{}).Error
+}
+
+func (r *NotificationRepository) TouchPushSubscription(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PushSubscription{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
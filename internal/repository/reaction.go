@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// ReactionTarget is the post or comment a reaction belongs to. Exactly one of
+// the fields is set.
+type ReactionTarget struct {
+	PostID    *uint
+	CommentID *uint
+}
+
+func (t ReactionTarget) scope(db *gorm.DB) *gorm.DB {
+	if t.CommentID != nil {
+		return db.Where("comment_id = ?", *t.CommentID)
+	}
+	return db.Where("post_id = ? AND comment_id IS NULL", *t.PostID)
+}
+
+// Reaction repository methods
+
+// Upsert sets the user's reaction on the target, replacing any earlier
+// reaction type. It reports whether the reaction is new.
+func (r *ReactionRepository) Upsert(ctx context.Context, userID uint, target ReactionTarget, reactionType string) (*models.Reaction, bool, error) {
+	var reaction models.Reaction
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := target.scope(tx.Where("user_id = ?", userID)).First(&reaction).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			reaction = models.Reaction{
+				UserID:    userID,
+				PostID:    target.PostID,
+				CommentID: target.CommentID,
+				Type:      reactionType,
+			}
+			created = true
+			return tx.Create(&reaction).Error
+		}
+		if err != nil {
+			return err
+		}
+		reaction.Type = reactionType
+		return tx.Model(&reaction).Update("type", reactionType).Error
+	})
+	return &reaction, created, err
+}
+
+func (r *ReactionRepository) Delete(ctx context.Context, userID uint, target ReactionTarget) (int64, error) {
+	result := target.scope(r.db.WithContext(ctx).Where("user_id = ?", userID)).Delete(&models.Reaction{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *ReactionRepository) ListByTarget(ctx context.Context, target ReactionTarget) ([]models.Reaction, error) {
+	var reactions []models.Reaction
+	err := target.scope(r.db.WithContext(ctx)).
+		Preload("User.Profile").
+		Order("id ASC").
+		Find(&reactions).Error
+	return reactions, err
+}
@@ -2,44 +2,61 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/vern/skillflow/internal/domain/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repositories struct {
-	User         UserRepositoryInterface
-	Profile      ProfileRepositoryInterface
-	Post         PostRepositoryInterface
-	Comment      CommentRepositoryInterface
-	Reaction     ReactionRepositoryInterface
-	Connection   ConnectionRepositoryInterface
-	Notification NotificationRepositoryInterface
-	Message      MessageRepositoryInterface
-	Group        GroupRepositoryInterface
-	GroupMember  GroupMemberRepositoryInterface
-	Skill        SkillRepositoryInterface
-	UserSkill    UserSkillRepositoryInterface
-	Endorsement  EndorsementRepositoryInterface
-	File         FileRepositoryInterface
+	User            UserRepositoryInterface
+	Profile         ProfileRepositoryInterface
+	Post            PostRepositoryInterface
+	Comment         CommentRepositoryInterface
+	Reaction        ReactionRepositoryInterface
+	Connection      ConnectionRepositoryInterface
+	UserSettings    UserSettingsRepositoryInterface
+	Notification    NotificationRepositoryInterface
+	Message         MessageRepositoryInterface
+	Conversation    ConversationRepositoryInterface
+	Group           GroupRepositoryInterface
+	GroupMember     GroupMemberRepositoryInterface
+	GroupInvite     GroupInviteRepositoryInterface
+	Event           EventRepositoryInterface
+	Skill           SkillRepositoryInterface
+	UserSkill       UserSkillRepositoryInterface
+	SkillAssessment SkillAssessmentRepositoryInterface
+	Goal            GoalRepositoryInterface
+	Mentorship      MentorshipRepositoryInterface
+	Endorsement     EndorsementRepositoryInterface
+	File            FileRepositoryInterface
 }
 
 func NewRepositories(db *gorm.DB) *Repositories {
 	return &Repositories{
-		User:         &UserRepository{db: db},
-		Profile:      &ProfileRepository{db: db},
-		Post:         &PostRepository{db: db},
-		Comment:      &CommentRepository{db: db},
-		Reaction:     &ReactionRepository{db: db},
-		Connection:   &ConnectionRepository{db: db},
-		Notification: &NotificationRepository{db: db},
-		Message:      &MessageRepository{db: db},
-		Group:        &GroupRepository{db: db},
-		GroupMember:  &GroupMemberRepository{db: db},
-		Skill:        &SkillRepository{db: db},
-		UserSkill:    &UserSkillRepository{db: db},
-		Endorsement:  &EndorsementRepository{db: db},
-		File:         &FileRepository{db: db},
+		User:            &UserRepository{db: db},
+		Profile:         &ProfileRepository{db: db},
+		Post:            &PostRepository{db: db},
+		Comment:         &CommentRepository{db: db},
+		Reaction:        &ReactionRepository{db: db},
+		Connection:      &ConnectionRepository{db: db},
+		UserSettings:    &UserSettingsRepository{db: db},
+		Notification:    &NotificationRepository{db: db},
+		Message:         &MessageRepository{db: db},
+		Conversation:    &ConversationRepository{db: db},
+		Group:           &GroupRepository{db: db},
+		GroupMember:     &GroupMemberRepository{db: db},
+		GroupInvite:     &GroupInviteRepository{db: db},
+		Event:           &EventRepository{db: db},
+		Skill:           &SkillRepository{db: db},
+		UserSkill:       &UserSkillRepository{db: db},
+		SkillAssessment: &SkillAssessmentRepository{db: db},
+		Goal:            &GoalRepository{db: db},
+		Mentorship:      &MentorshipRepository{db: db},
+		Endorsement:     &EndorsementRepository{db: db},
+		File:            &FileRepository{db: db},
 	}
 }
 
@@ -52,6 +69,11 @@ type UserRepositoryInterface interface {
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id uint) error
 	Search(ctx context.Context, query string) ([]models.User, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]models.User, error)
+	GetByUsernames(ctx context.Context, usernames []string) ([]models.User, error)
+	UpdateLastSeen(ctx context.Context, id uint, at time.Time) error
+	ListActive(ctx context.Context, afterID uint, limit int) ([]models.User, error)
+	ListByDepartment(ctx context.Context, department string) ([]models.User, error)
 }
 
 type ProfileRepositoryInterface interface {
@@ -64,23 +86,252 @@ type PostRepositoryInterface interface {
 	Create(ctx context.Context, post *models.Post) error
 	GetByID(ctx context.Context, id uint) (*models.Post, error)
 	GetFeed(ctx context.Context, userID uint, page, limit int) ([]models.Post, error)
-	GetByUserID(ctx context.Context, userID uint, page, limit int) ([]models.Post, error)
+	GetByUserID(ctx context.Context, userID, viewerID uint, page, limit int) ([]models.Post, error)
 	GetByGroupID(ctx context.Context, groupID uint, page, limit int) ([]models.Post, error)
 	Update(ctx context.Context, post *models.Post) error
 	Delete(ctx context.Context, id uint) error
+	Pin(ctx context.Context, pin *models.PinnedPost, max int64) error
+	Unpin(ctx context.Context, postID uint, targetType string, targetID uint) error
+	IsPinned(ctx context.Context, postID uint, targetType string, targetID uint) (bool, error)
+	TopForUser(ctx context.Context, userID uint, since time.Time, limit int) ([]models.Post, error)
 }
 
-type CommentRepositoryInterface interface{}
-type ReactionRepositoryInterface interface{}
-type ConnectionRepositoryInterface interface{}
-type NotificationRepositoryInterface interface{}
-type MessageRepositoryInterface interface{}
-type GroupRepositoryInterface interface{}
-type GroupMemberRepositoryInterface interface{}
-type SkillRepositoryInterface interface{}
-type UserSkillRepositoryInterface interface{}
-type EndorsementRepositoryInterface interface{}
-type FileRepositoryInterface interface{}
+type CommentRepositoryInterface interface {
+	Create(ctx context.Context, comment *models.Comment) error
+	GetByID(ctx context.Context, id uint) (*models.Comment, error)
+	ListByPost(ctx context.Context, postID uint, page, limit int) ([]models.Comment, error)
+	Update(ctx context.Context, comment *models.Comment) error
+	Delete(ctx context.Context, id uint) error
+}
+type ReactionRepositoryInterface interface {
+	Upsert(ctx context.Context, userID uint, target ReactionTarget, reactionType string) (*models.Reaction, bool, error)
+	Delete(ctx context.Context, userID uint, target ReactionTarget) (int64, error)
+	ListByTarget(ctx context.Context, target ReactionTarget) ([]models.Reaction, error)
+}
+type ConnectionRepositoryInterface interface {
+	Create(ctx context.Context, connection *models.Connection) error
+	GetByID(ctx context.Context, id uint) (*models.Connection, error)
+	GetBetween(ctx context.Context, userID, otherID uint) (*models.Connection, error)
+	Update(ctx context.Context, connection *models.Connection) error
+	Delete(ctx context.Context, id uint) error
+	ListAccepted(ctx context.Context, userID uint, page, limit int) ([]models.Connection, error)
+	ListPending(ctx context.Context, targetID uint) ([]models.Connection, error)
+	AreConnected(ctx context.Context, userID, otherID uint) (bool, error)
+	Block(ctx context.Context, blockerID, blockedID uint) error
+	Unblock(ctx context.Context, blockerID, blockedID uint) error
+	IsBlockedEitherWay(ctx context.Context, userID, otherID uint) (bool, error)
+	FilterConnected(ctx context.Context, userID uint, candidateIDs []uint) ([]uint, error)
+}
+
+type UserSettingsRepositoryInterface interface {
+	GetByUserID(ctx context.Context, userID uint) (*models.UserSettings, error)
+	GetByUserIDs(ctx context.Context, userIDs []uint) (map[uint]*models.UserSettings, error)
+	GetByCalendarToken(ctx context.Context, token string) (*models.UserSettings, error)
+	Save(ctx context.Context, settings *models.UserSettings) error
+}
+type NotificationRepositoryInterface interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	GetByID(ctx context.Context, id uint) (*models.Notification, error)
+	Merge(ctx context.Context, userID uint, groupKey string, since time.Time, merge func(*models.Notification) bool) (*models.Notification, error)
+	List(ctx context.Context, userID uint, unreadOnly bool, page, limit int) ([]models.Notification, error)
+	MarkRead(ctx context.Context, id, userID uint) (int64, error)
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+
+	GetPreferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error)
+	GetPreferencesFor(ctx context.Context, userIDs []uint, category string) (map[uint]*models.NotificationPreference, error)
+	SavePreferences(ctx context.Context, preferences []models.NotificationPreference) error
+	GetSettings(ctx context.Context, userID uint) (*models.NotificationSettings, error)
+	GetSettingsFor(ctx context.Context, userIDs []uint) (map[uint]*models.NotificationSettings, error)
+	SaveSettings(ctx context.Context, settings *models.NotificationSettings) error
+
+	ListForDigest(ctx context.Context, userID uint, since time.Time, limit int) ([]models.Notification, error)
+	ClearDigest(ctx context.Context, ids []uint) error
+	ListPendingEmail(ctx context.Context, afterID uint, limit int) ([]models.Notification, error)
+	ClaimEmail(ctx context.Context, id uint) (bool, error)
+	ClaimDigest(ctx context.Context, userID uint, period string, now, staleBefore time.Time) (bool, error)
+	MarkDigestSent(ctx context.Context, userID uint, period string, items int, at time.Time) error
+
+	SavePushSubscription(ctx context.Context, subscription *models.PushSubscription) error
+	ListPushSubscriptions(ctx context.Context, userID uint) ([]models.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, id, userID uint) (int64, error)
+	DeletePushSubscriptionByEndpoint(ctx context.Context, endpoint string) error
+	TouchPushSubscription(ctx context.Context, id uint, at time.Time) error
+}
+type MessageRepositoryInterface interface {
+	Create(ctx context.Context, message *models.Message) error
+	GetByID(ctx context.Context, id uint) (*models.Message, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]models.Message, error)
+	GetHistory(ctx context.Context, conversationID, viewerID, beforeID uint, limit int) ([]models.Message, error)
+	MarkReadUpTo(ctx context.Context, conversationID, receiverID, upToID uint, at time.Time) (int64, error)
+	Edit(ctx context.Context, message *models.Message) error
+	Delete(ctx context.Context, message *models.Message) error
+	Hide(ctx context.Context, messageID, userID uint) error
+	AddReaction(ctx context.Context, reaction *models.MessageReaction) error
+	RemoveReaction(ctx context.Context, messageID, userID uint, emoji string) (int64, error)
+}
+type ConversationRepositoryInterface interface {
+	Create(ctx context.Context, conversation *models.Conversation, participants []models.ConversationParticipant) error
+	GetByID(ctx context.Context, id uint) (*models.Conversation, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]models.Conversation, error)
+	GetDirect(ctx context.Context, userID, peerID uint) (*models.Conversation, error)
+	Update(ctx context.Context, conversation *models.Conversation) error
+	ListForUser(ctx context.Context, userID uint, page, limit int) ([]ConversationSummary, error)
+	GetParticipant(ctx context.Context, conversationID, userID uint) (*models.ConversationParticipant, error)
+	AddParticipants(ctx context.Context, participants []models.ConversationParticipant) error
+	RemoveParticipant(ctx context.Context, conversationID, userID uint) error
+	UpdateParticipantRole(ctx context.Context, conversationID, userID uint, role string) error
+	ListParticipantIDs(ctx context.Context, conversationID uint) ([]uint, error)
+	AdvanceReadCursor(ctx context.Context, conversationID, userID, messageID uint) error
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+}
+type GroupRepositoryInterface interface {
+	CreateWithOwner(ctx context.Context, group *models.Group, ownerID uint) error
+	GetByID(ctx context.Context, id uint) (*models.Group, error)
+	List(ctx context.Context, filter GroupFilter, page, limit int) ([]models.Group, error)
+	Update(ctx context.Context, group *models.Group) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type GroupMemberRepositoryInterface interface {
+	Create(ctx context.Context, member *models.GroupMember) error
+	GetByGroupAndUser(ctx context.Context, groupID, userID uint) (*models.GroupMember, error)
+	ListByGroup(ctx context.Context, groupID uint, page, limit int) ([]models.GroupMember, error)
+	CountByRole(ctx context.Context, groupID uint, role string) (int64, error)
+	ListUserIDsByRoles(ctx context.Context, groupID uint, roles ...string) ([]uint, error)
+	UpdateRole(ctx context.Context, groupID, userID uint, role string) error
+	Delete(ctx context.Context, groupID, userID uint) error
+	TransferOwnership(ctx context.Context, groupID, fromUserID, toUserID uint) error
+	Ban(ctx context.Context, ban *models.GroupBan) error
+	Unban(ctx context.Context, groupID, userID uint) error
+	IsBanned(ctx context.Context, groupID, userID uint) (bool, error)
+}
+type GroupInviteRepositoryInterface interface {
+	CreateJoinRequest(ctx context.Context, req *models.GroupJoinRequest) error
+	GetJoinRequest(ctx context.Context, id uint) (*models.GroupJoinRequest, error)
+	GetPendingJoinRequest(ctx context.Context, groupID, userID uint) (*models.GroupJoinRequest, error)
+	ListJoinRequests(ctx context.Context, groupID uint, status string, page, limit int) ([]models.GroupJoinRequest, error)
+	ApproveJoinRequest(ctx context.Context, req *models.GroupJoinRequest, reviewerID uint) error
+	DeclineJoinRequest(ctx context.Context, req *models.GroupJoinRequest, reviewerID uint) error
+
+	CreateInvitation(ctx context.Context, inv *models.GroupInvitation) error
+	GetInvitation(ctx context.Context, id uint) (*models.GroupInvitation, error)
+	GetPendingInvitation(ctx context.Context, groupID, inviteeID uint) (*models.GroupInvitation, error)
+	ListInvitationsForUser(ctx context.Context, inviteeID uint, status string) ([]models.GroupInvitation, error)
+	AcceptInvitation(ctx context.Context, inv *models.GroupInvitation) error
+	DeclineInvitation(ctx context.Context, inv *models.GroupInvitation) error
+
+	CreateLink(ctx context.Context, link *models.GroupInviteLink) error
+	GetLinkByID(ctx context.Context, id uint) (*models.GroupInviteLink, error)
+	GetLinkByToken(ctx context.Context, token string) (*models.GroupInviteLink, error)
+	ListLinks(ctx context.Context, groupID uint) ([]models.GroupInviteLink, error)
+	RevokeLink(ctx context.Context, id uint) error
+	RedeemLink(ctx context.Context, link *models.GroupInviteLink, userID uint) error
+}
+
+type EventRepositoryInterface interface {
+	Create(ctx context.Context, event *models.GroupEvent) error
+	GetByID(ctx context.Context, id uint) (*models.GroupEvent, error)
+	ListByGroup(ctx context.Context, groupID uint, from, to time.Time, page, limit int) ([]models.GroupEvent, error)
+	ListForUser(ctx context.Context, userID uint, from time.Time, limit int) ([]models.GroupEvent, error)
+	ListNeedingReminder(ctx context.Context, before time.Time) ([]models.GroupEvent, error)
+	ClaimReminder(ctx context.Context, id uint, at time.Time) (bool, error)
+	Update(ctx context.Context, event *models.GroupEvent) error
+	Delete(ctx context.Context, id uint) error
+
+	GetRSVP(ctx context.Context, eventID, userID uint) (*models.EventRSVP, error)
+	SetRSVP(ctx context.Context, eventID, userID uint, status string) (*models.EventRSVP, []uint, error)
+	PromoteWaitlisted(ctx context.Context, eventID uint) ([]uint, error)
+	ListRSVPs(ctx context.Context, eventID uint, statuses ...string) ([]models.EventRSVP, error)
+	ListAttendeeIDs(ctx context.Context, eventID uint, statuses ...string) ([]uint, error)
+}
+
+type SkillRepositoryInterface interface {
+	Create(ctx context.Context, skill *models.Skill) error
+	GetByID(ctx context.Context, id uint) (*models.Skill, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]models.Skill, error)
+	GetByName(ctx context.Context, name string) (*models.Skill, error)
+	Resolve(ctx context.Context, name string) (*models.Skill, error)
+	List(ctx context.Context, filter SkillFilter, page, limit int) ([]models.Skill, error)
+	ListAll(ctx context.Context) ([]models.Skill, error)
+	Autocomplete(ctx context.Context, query string, limit int) ([]SkillSuggestion, error)
+	Update(ctx context.Context, skill *models.Skill) error
+	ListAncestorIDs(ctx context.Context, id uint) ([]uint, error)
+	Merge(ctx context.Context, canonicalID uint, duplicateIDs []uint) error
+	CreateCategory(ctx context.Context, category *models.SkillCategory) error
+	GetCategory(ctx context.Context, id uint) (*models.SkillCategory, error)
+	GetCategoryByName(ctx context.Context, name string) (*models.SkillCategory, error)
+	ListCategories(ctx context.Context) ([]models.SkillCategory, error)
+	UpdateCategory(ctx context.Context, category *models.SkillCategory) error
+	DeleteCategory(ctx context.Context, id uint) error
+	AddSynonym(ctx context.Context, synonym *models.SkillSynonym) error
+	GetSynonymByKey(ctx context.Context, key string) (*models.SkillSynonym, error)
+	DeleteSynonym(ctx context.Context, id, skillID uint) (int64, error)
+	ImportCatalog(ctx context.Context, categories []CatalogCategory, skills []CatalogSkill, dryRun bool) (*CatalogReport, error)
+	ListTargets(ctx context.Context, scopeKey string) ([]models.SkillTarget, error)
+	ReplaceTargets(ctx context.Context, scopeKey string, targets []models.SkillTarget) error
+}
+type UserSkillRepositoryInterface interface {
+	Create(ctx context.Context, userSkill *models.UserSkill) error
+	GetByID(ctx context.Context, id uint) (*models.UserSkill, error)
+	GetByUserAndSkill(ctx context.Context, userID, skillID uint) (*models.UserSkill, error)
+	ListByUser(ctx context.Context, userID, viewerID uint) ([]models.UserSkill, error)
+	ListByUsers(ctx context.Context, userIDs []uint) ([]models.UserSkill, error)
+	ListBySkillAndUsers(ctx context.Context, skillID uint, userIDs []uint) ([]models.UserSkill, error)
+	SearchExperts(ctx context.Context, filter ExpertFilter, page, limit int) ([]ExpertMatch, error)
+	Update(ctx context.Context, userSkill *models.UserSkill) error
+	Delete(ctx context.Context, id uint) error
+}
+type SkillAssessmentRepositoryInterface interface {
+	CreateAssessor(ctx context.Context, assessor *models.SkillAssessor) error
+	GetAssessor(ctx context.Context, id uint) (*models.SkillAssessor, error)
+	ListAssessors(ctx context.Context, userID, skillID uint) ([]models.SkillAssessor, error)
+	DeleteAssessor(ctx context.Context, id uint) error
+	CanAssess(ctx context.Context, userID, skillID uint) (bool, error)
+	Create(ctx context.Context, assessment *models.SkillAssessment) error
+	ListByUserSkill(ctx context.Context, userSkillID uint) ([]models.SkillAssessment, error)
+	ListExpiring(ctx context.Context, assessorID uint, before time.Time, page, limit int) ([]models.UserSkill, error)
+}
+type GoalRepositoryInterface interface {
+	Create(ctx context.Context, goal *models.LearningGoal) error
+	GetByID(ctx context.Context, id uint) (*models.LearningGoal, error)
+	ListByUser(ctx context.Context, userID uint, status string, page, limit int) ([]models.LearningGoal, error)
+	ListByMentor(ctx context.Context, mentorID uint, status string, page, limit int) ([]models.LearningGoal, error)
+	ListSharedWithManager(ctx context.Context, managerID uint, status string, page, limit int) ([]models.LearningGoal, error)
+	ExistsActive(ctx context.Context, userID, skillID uint) (bool, error)
+	Update(ctx context.Context, goal *models.LearningGoal) error
+	Delete(ctx context.Context, id uint) error
+	AddCheckIn(ctx context.Context, goal *models.LearningGoal, checkIn *models.GoalCheckIn) error
+	ListNeedingReminder(ctx context.Context, before time.Time) ([]models.LearningGoal, error)
+	ClaimReminder(ctx context.Context, id uint, at time.Time) (bool, error)
+}
+type MentorshipRepositoryInterface interface {
+	CreateOffer(ctx context.Context, offer *models.MentorOffer) error
+	GetOffer(ctx context.Context, userID, skillID uint) (*models.MentorOffer, error)
+	ListOffers(ctx context.Context, userID uint) ([]models.MentorOffer, error)
+	UpdateOffer(ctx context.Context, offer *models.MentorOffer) error
+	DeleteOffer(ctx context.Context, offer *models.MentorOffer) ([]models.Mentorship, error)
+	SearchMentors(ctx context.Context, filter MentorFilter) ([]MentorCandidate, error)
+	Create(ctx context.Context, mentorship *models.Mentorship) error
+	GetByID(ctx context.Context, id uint) (*models.Mentorship, error)
+	ExistsOpen(ctx context.Context, mentorID, menteeID, skillID uint) (bool, error)
+	List(ctx context.Context, userID uint, role, status string, page, limit int) ([]models.Mentorship, error)
+	UpdateStatus(ctx context.Context, mentorship *models.Mentorship, from string) error
+	Accept(ctx context.Context, mentorship *models.Mentorship) (bool, error)
+}
+type EndorsementRepositoryInterface interface {
+	Create(ctx context.Context, endorsement *models.Endorsement) error
+	Exists(ctx context.Context, userSkillID, endorserID uint) (bool, error)
+	GetByID(ctx context.Context, id uint) (*models.Endorsement, error)
+	GetByUserSkillAndEndorser(ctx context.Context, userSkillID, endorserID uint) (*models.Endorsement, error)
+	ListByUserSkill(ctx context.Context, userSkillID uint, includeHidden bool) ([]models.Endorsement, error)
+	SetHidden(ctx context.Context, id uint, hidden bool) error
+	Delete(ctx context.Context, id uint) error
+	ListReceivedSince(ctx context.Context, userID uint, since time.Time) ([]models.Endorsement, error)
+}
+type FileRepositoryInterface interface {
+	GetByIDs(ctx context.Context, ids []uint) ([]models.File, error)
+}
 
 // Implementations
 type UserRepository struct{ db *gorm.DB }
@@ -89,12 +340,19 @@ type PostRepository struct{ db *gorm.DB }
 type CommentRepository struct{ db *gorm.DB }
 type ReactionRepository struct{ db *gorm.DB }
 type ConnectionRepository struct{ db *gorm.DB }
+type UserSettingsRepository struct{ db *gorm.DB }
 type NotificationRepository struct{ db *gorm.DB }
 type MessageRepository struct{ db *gorm.DB }
+type ConversationRepository struct{ db *gorm.DB }
 type GroupRepository struct{ db *gorm.DB }
 type GroupMemberRepository struct{ db *gorm.DB }
+type GroupInviteRepository struct{ db *gorm.DB }
+type EventRepository struct{ db *gorm.DB }
 type SkillRepository struct{ db *gorm.DB }
 type UserSkillRepository struct{ db *gorm.DB }
+type SkillAssessmentRepository struct{ db *gorm.DB }
+type GoalRepository struct{ db *gorm.DB }
+type MentorshipRepository struct{ db *gorm.DB }
 type EndorsementRepository struct{ db *gorm.DB }
 type FileRepository struct{ db *gorm.DB }
 
@@ -138,6 +396,57 @@ func (r *UserRepository) Search(ctx context.Context, query string) ([]models.Use
 	return users, err
 }
 
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.WithContext(ctx).Preload("Profile").Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) GetByUsernames(ctx context.Context, usernames []string) ([]models.User, error) {
+	var users []models.User
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	err := r.db.WithContext(ctx).Where("username IN ?", usernames).Find(&users).Error
+	return users, err
+}
+
+// UpdateLastSeen records when the user was last connected without touching
+// updated_at.
+func (r *UserRepository) UpdateLastSeen(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		UpdateColumn("last_seen_at", at).Error
+}
+
+// ListActive returns active users in ID order starting after afterID.
+func (r *UserRepository) ListActive(ctx context.Context, afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Preload("Profile").
+		Where("id > ? AND is_active = ?", afterID, true).
+		Order("id").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// ListByDepartment returns the active users whose profile is in the
+// department, ignoring case.
+func (r *UserRepository) ListByDepartment(ctx context.Context, department string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Preload("Profile").
+		Where("is_active = ? AND id IN (SELECT user_id FROM profiles WHERE LOWER(department) = LOWER(?))", true, department).
+		Order("id").
+		Find(&users).Error
+	return users, err
+}
+
 // Profile repository methods
 func (r *ProfileRepository) Create(ctx context.Context, profile *models.Profile) error {
 	return r.db.WithContext(ctx).Create(profile).Error
@@ -168,10 +477,12 @@ func (r *PostRepository) GetByID(ctx context.Context, id uint) (*models.Post, er
 	return &post, err
 }
 
+// GetFeed returns posts outside groups and posts in the user's groups.
 func (r *PostRepository) GetFeed(ctx context.Context, userID uint, page, limit int) ([]models.Post, error) {
 	var posts []models.Post
 	offset := (page - 1) * limit
 	err := r.db.WithContext(ctx).
+		Where("posts.group_id IS NULL OR posts.group_id IN (SELECT group_id FROM group_members WHERE user_id = ?)", userID).
 		Preload("User.Profile").
 		Preload("Reactions").
 		Order("created_at DESC").
@@ -181,13 +492,18 @@ func (r *PostRepository) GetFeed(ctx context.Context, userID uint, page, limit i
 	return posts, err
 }
 
-func (r *PostRepository) GetByUserID(ctx context.Context, userID uint, page, limit int) ([]models.Post, error) {
+// GetByUserID returns the user's posts the viewer can read: those outside
+// groups, in public groups, or in groups the viewer is a member of.
+func (r *PostRepository) GetByUserID(ctx context.Context, userID, viewerID uint, page, limit int) ([]models.Post, error) {
 	var posts []models.Post
 	offset := (page - 1) * limit
-	err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+	err := r.withPins(ctx, models.PinTargetProfile, userID).
+		Where("posts.user_id = ?", userID).
+		Where(`posts.group_id IS NULL
+			OR posts.group_id IN (SELECT id FROM groups WHERE visibility = ?)
+			OR posts.group_id IN (SELECT group_id FROM group_members WHERE user_id = ?)`,
+			models.GroupVisibilityPublic, viewerID).
 		Preload("User.Profile").
-		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&posts).Error
@@ -197,16 +513,25 @@ func (r *PostRepository) GetByUserID(ctx context.Context, userID uint, page, lim
 func (r *PostRepository) GetByGroupID(ctx context.Context, groupID uint, page, limit int) ([]models.Post, error) {
 	var posts []models.Post
 	offset := (page - 1) * limit
-	err := r.db.WithContext(ctx).
-		Where("group_id = ?", groupID).
+	err := r.withPins(ctx, models.PinTargetGroup, groupID).
+		Where("posts.group_id = ?", groupID).
 		Preload("User.Profile").
-		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&posts).Error
 	return posts, err
 }
 
+// withPins joins the pins of the given target so pinned posts sort first
+// (most recently pinned on top) and Post.Pinned is populated.
+func (r *PostRepository) withPins(ctx context.Context, targetType string, targetID uint) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Select("posts.*, pinned_posts.id IS NOT NULL AS pinned").
+		Joins("LEFT JOIN pinned_posts ON pinned_posts.post_id = posts.id AND pinned_posts.target_type = ? AND pinned_posts.target_id = ?", targetType, targetID).
+		Order("pinned_posts.created_at DESC NULLS LAST").
+		Order("posts.created_at DESC")
+}
+
 func (r *PostRepository) Update(ctx context.Context, post *models.Post) error {
 	return r.db.WithContext(ctx).Save(post).Error
 }
@@ -214,3 +539,92 @@ func (r *PostRepository) Update(ctx context.Context, post *models.Post) error {
 func (r *PostRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&models.Post{}, id).Error
 }
+
+// Errors returned by Pin.
+var (
+	ErrPinLimitReached = errors.New("pin limit reached")
+	ErrAlreadyPinned   = errors.New("post is already pinned")
+)
+
+// Pin pins the post to its target unless the target already has max live
+// pins. The target's group or user row is locked so the limit holds under
+// concurrent pins. It returns ErrPinLimitReached or ErrAlreadyPinned when
+// the pin is refused.
+func (r *PostRepository) Pin(ctx context.Context, pin *models.PinnedPost, max int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var target interface{} = &models.User{}
+		if pin.TargetType == models.PinTargetGroup {
+			target = &models.Group{}
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(target, pin.TargetID).Error; err != nil {
+			return err
+		}
+
+		count, err := countPinned(tx, pin.TargetType, pin.TargetID)
+		if err != nil {
+			return err
+		}
+		if count >= max {
+			return ErrPinLimitReached
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(pin)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyPinned
+		}
+		return nil
+	})
+}
+
+func (r *PostRepository) Unpin(ctx context.Context, postID uint, targetType string, targetID uint) error {
+	return r.db.WithContext(ctx).
+		Where("post_id = ? AND target_type = ? AND target_id = ?", postID, targetType, targetID).
+		Delete(&models.PinnedPost{}).Error
+}
+
+func (r *PostRepository) IsPinned(ctx context.Context, postID uint, targetType string, targetID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.PinnedPost{}).
+		Where("post_id = ? AND target_type = ? AND target_id = ?", postID, targetType, targetID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// countPinned ignores pins whose post has since been deleted so they do not
+// use up the target's slots.
+func countPinned(tx *gorm.DB, targetType string, targetID uint) (int64, error) {
+	var count int64
+	err := tx.
+		Model(&models.PinnedPost{}).
+		Joins("JOIN posts ON posts.id = pinned_posts.post_id AND posts.deleted_at IS NULL").
+		Where("pinned_posts.target_type = ? AND pinned_posts.target_id = ?", targetType, targetID).
+		Count(&count).Error
+	return count, err
+}
+
+// TopForUser returns the most engaged posts created since the given time in
+// the user's groups and by the user's connections, ranked by reactions plus
+// twice the comments.
+func (r *PostRepository) TopForUser(ctx context.Context, userID uint, since time.Time, limit int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.WithContext(ctx).
+		Preload("User.Profile").
+		Preload("Group").
+		Where("posts.created_at >= ? AND posts.user_id <> ?", since, userID).
+		Where(`posts.group_id IN (SELECT group_id FROM group_members WHERE user_id = @user)
+			OR (posts.group_id IS NULL AND posts.user_id IN (
+				SELECT CASE WHEN user_id = @user THEN target_id ELSE user_id END
+				FROM connections
+				WHERE (user_id = @user OR target_id = @user) AND status = @accepted))`,
+			map[string]interface{}{"user": userID, "accepted": models.ConnectionStatusAccepted}).
+		Order(`((SELECT COUNT(*) FROM reactions WHERE reactions.post_id = posts.id AND reactions.comment_id IS NULL)
+			+ 2 * (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted_at IS NULL)) DESC`).
+		Order("posts.created_at DESC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
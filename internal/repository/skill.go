@@ -0,0 +1,621 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SkillFilter narrows SkillRepository.List results.
+type SkillFilter struct {
+	Category   string
+	CategoryID uint
+	ParentID   *uint // 0 lists top-level skills
+	Query      string
+}
+
+// SkillSuggestion is an autocomplete match. Synonym is set when the query
+// matched one of the skill's synonyms rather than its name.
+type SkillSuggestion struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Synonym  string `json:"synonym,omitempty"`
+}
+
+// Skill repository methods
+func (r *SkillRepository) Create(ctx context.Context, skill *models.Skill) error {
+	return r.db.WithContext(ctx).Omit("Parent", "Children", "Synonyms", "UserSkills").Create(skill).Error
+}
+
+func (r *SkillRepository) GetByID(ctx context.Context, id uint) (*models.Skill, error) {
+	var skill models.Skill
+	err := r.db.WithContext(ctx).
+		Preload("Parent").
+		Preload("Children", func(db *gorm.DB) *gorm.DB { return db.Order("LOWER(name)") }).
+		Preload("Synonyms", func(db *gorm.DB) *gorm.DB { return db.Order("name_key") }).
+		First(&skill, id).Error
+	return &skill, err
+}
+
+func (r *SkillRepository) GetByIDs(ctx context.Context, ids []uint) ([]models.Skill, error) {
+	var skills []models.Skill
+	if len(ids) == 0 {
+		return skills, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&skills).Error
+	return skills, err
+}
+
+// GetByName finds a skill by name, ignoring case.
+func (r *SkillRepository) GetByName(ctx context.Context, name string) (*models.Skill, error) {
+	var skill models.Skill
+	err := r.db.WithContext(ctx).Where("LOWER(name) = ?", models.SkillKey(name)).First(&skill).Error
+	return &skill, err
+}
+
+// Resolve finds the canonical skill for a name or one of its synonyms,
+// ignoring case.
+func (r *SkillRepository) Resolve(ctx context.Context, name string) (*models.Skill, error) {
+	return resolveSkill(r.db.WithContext(ctx), name)
+}
+
+func resolveSkill(db *gorm.DB, name string) (*models.Skill, error) {
+	key := models.SkillKey(name)
+	var skill models.Skill
+	err := db.
+		Where("LOWER(name) = ? OR id IN (SELECT skill_id FROM skill_synonyms WHERE name_key = ?)", key, key).
+		First(&skill).Error
+	return &skill, err
+}
+
+// List returns catalog skills ordered by name. The category matches
+// exactly, ignoring case; the query matches name, description or a synonym.
+func (r *SkillRepository) List(ctx context.Context, filter SkillFilter, page, limit int) ([]models.Skill, error) {
+	var skills []models.Skill
+	offset := (page - 1) * limit
+	query := r.db.WithContext(ctx).Model(&models.Skill{})
+	if filter.Category != "" {
+		query = query.Where("LOWER(category) = LOWER(?)", filter.Category)
+	}
+	if filter.CategoryID != 0 {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.ParentID != nil {
+		if *filter.ParentID == 0 {
+			query = query.Where("parent_id IS NULL")
+		} else {
+			query = query.Where("parent_id = ?", *filter.ParentID)
+		}
+	}
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("name ILIKE ? OR description ILIKE ? OR id IN (SELECT skill_id FROM skill_synonyms WHERE name ILIKE ?)", like, like, like)
+	}
+	err := query.
+		Order("LOWER(name)").
+		Limit(limit).
+		Offset(offset).
+		Find(&skills).Error
+	return skills, err
+}
+
+// Autocomplete returns up to limit skills whose name or a synonym contains
+// the query. Prefix matches rank before other matches and name matches
+// before synonym matches.
+func (r *SkillRepository) Autocomplete(ctx context.Context, query string, limit int) ([]SkillSuggestion, error) {
+	var rows []struct {
+		SkillSuggestion
+		Rank int
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT s.id, s.name, s.category, COALESCE(syn.name, '') AS synonym,
+			CASE
+				WHEN s.name ILIKE @prefix THEN 0
+				WHEN syn.name ILIKE @prefix THEN 1
+				WHEN s.name ILIKE @contains THEN 2
+				ELSE 3
+			END AS rank
+		FROM skills s
+		LEFT JOIN skill_synonyms syn ON syn.skill_id = s.id AND syn.name ILIKE @contains
+		WHERE s.deleted_at IS NULL AND (s.name ILIKE @contains OR syn.id IS NOT NULL)
+		ORDER BY rank, LENGTH(s.name), LOWER(s.name)
+		LIMIT @rows`,
+		map[string]interface{}{"prefix": query + "%", "contains": "%" + query + "%", "rows": limit * 4},
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]SkillSuggestion, 0, limit)
+	seen := make(map[uint]bool, len(rows))
+	for _, row := range rows {
+		if seen[row.ID] || len(suggestions) == limit {
+			continue
+		}
+		seen[row.ID] = true
+		if row.Rank == 0 || row.Rank == 2 {
+			row.Synonym = ""
+		}
+		suggestions = append(suggestions, row.SkillSuggestion)
+	}
+	return suggestions, nil
+}
+
+func (r *SkillRepository) Update(ctx context.Context, skill *models.Skill) error {
+	return r.db.WithContext(ctx).Omit("Parent", "Children", "Synonyms", "UserSkills").Save(skill).Error
+}
+
+// ListAncestorIDs returns the IDs of the skill's parent, grandparent and so
+// on, nearest first.
+func (r *SkillRepository) ListAncestorIDs(ctx context.Context, id uint) ([]uint, error) {
+	return listAncestorIDs(r.db.WithContext(ctx), id)
+}
+
+func listAncestorIDs(db *gorm.DB, id uint) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_id, 1 AS depth FROM skills WHERE id = ?
+			UNION
+			SELECT s.parent_id, a.depth + 1 FROM skills s JOIN ancestors a ON s.id = a.parent_id
+			WHERE a.depth < 100
+		)
+		SELECT parent_id FROM ancestors WHERE parent_id IS NOT NULL ORDER BY depth`, id,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// Merge folds the duplicate skills into the canonical one in a single
+// transaction. For each duplicate:
+//   - user skills move to the canonical skill; a user who has both keeps one
+//     user skill with the higher level and experience, the more recent
+//     verification, and the endorsements and assessments of both, without
+//     endorsing twice
+//   - target skill profiles move to the canonical skill, keeping the
+//     stricter target where a profile has both
+//   - assessor grants, learning goals and mentorships move to the canonical
+//     skill; a user's active goal of the duplicate is abandoned when they
+//     have one for the canonical skill, and of two open mentorships between
+//     the same people the one further along (active, then paused, then
+//     pending) stays open and the other ends
+//   - mentor offers move to the canonical skill; a user who offers both
+//     keeps one offer with the larger capacity, available if either was
+//   - child skills and synonyms move to the canonical skill, and the
+//     duplicate's name becomes a synonym
+//   - the duplicate is deleted
+func (r *SkillRepository) Merge(ctx context.Context, canonicalID uint, duplicateIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var canonical models.Skill
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&canonical, canonicalID).Error; err != nil {
+			return err
+		}
+		for _, id := range duplicateIDs {
+			var duplicate models.Skill
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&duplicate, id).Error; err != nil {
+				return err
+			}
+			if err := mergeSkill(tx, &canonical, &duplicate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func mergeSkill(tx *gorm.DB, canonical, duplicate *models.Skill) error {
+	args := map[string]interface{}{
+		"canonical": canonical.ID,
+		"duplicate": duplicate.ID,
+		"parent":    duplicate.ParentID,
+		"name":      duplicate.Name,
+		"key":       models.SkillKey(duplicate.Name),
+		"ckey":      models.SkillKey(canonical.Name),
+		"now":       time.Now(),
+		"active":    models.GoalStatusActive,
+		"abandoned": models.GoalStatusAbandoned,
+		"open":      openMentorshipStatuses,
+		"ended":     models.MentorshipStatusEnded,
+	}
+	// both joins a user's user skill of the duplicate (d) with their user
+	// skill of the canonical skill (c).
+	const both = `user_skills d JOIN user_skills c ON c.user_id = d.user_id AND c.skill_id = @canonical`
+	// newer holds when the duplicate's verification is more recent.
+	const newer = `(c.verified_at IS NULL OR d.verified_at > c.verified_at)`
+	// sameMentorship joins the duplicate's open mentorship (d) with the open
+	// mentorship between the same people for the canonical skill (c).
+	const sameMentorship = `c.skill_id = @canonical AND c.mentor_id = d.mentor_id AND c.mentee_id = d.mentee_id
+		AND c.status IN @open AND d.status IN @open`
+	statements := []string{
+		`UPDATE endorsements e SET user_skill_id = c.id
+		FROM ` + both + `
+		WHERE d.skill_id = @duplicate AND e.user_skill_id = d.id
+			AND NOT EXISTS (SELECT 1 FROM endorsements x WHERE x.user_skill_id = c.id AND x.endorser_id = e.endorser_id)`,
+
+		`UPDATE skill_assessments a SET user_skill_id = c.id
+		FROM ` + both + `
+		WHERE d.skill_id = @duplicate AND a.user_skill_id = d.id`,
+
+		`UPDATE user_skills c SET
+			years_of_exp = GREATEST(c.years_of_exp, d.years_of_exp),
+			level = CASE WHEN ` + levelRank("d.level") + ` > ` + levelRank("c.level") + ` THEN d.level ELSE c.level END,
+			verified_level = CASE WHEN ` + newer + ` THEN d.verified_level ELSE c.verified_level END,
+			verified_at = CASE WHEN ` + newer + ` THEN d.verified_at ELSE c.verified_at END,
+			verified_until = CASE WHEN ` + newer + ` THEN d.verified_until ELSE c.verified_until END,
+			updated_at = @now
+		FROM user_skills d
+		WHERE d.skill_id = @duplicate AND d.user_id = c.user_id AND c.skill_id = @canonical`,
+
+		`DELETE FROM endorsements WHERE user_skill_id IN (SELECT d.id FROM ` + both + ` WHERE d.skill_id = @duplicate)`,
+
+		`DELETE FROM user_skills WHERE id IN (SELECT d.id FROM ` + both + ` WHERE d.skill_id = @duplicate)`,
+
+		`UPDATE user_skills SET skill_id = @canonical, updated_at = @now WHERE skill_id = @duplicate`,
+
+		`UPDATE skill_assessments SET skill_id = @canonical WHERE skill_id = @duplicate`,
+
+		`UPDATE skill_assessors SET skill_id = @canonical WHERE skill_id = @duplicate`,
+
+		`UPDATE learning_goals d SET status = @abandoned, updated_at = @now
+		WHERE d.skill_id = @duplicate AND d.status = @active
+			AND EXISTS (SELECT 1 FROM learning_goals c WHERE c.skill_id = @canonical AND c.user_id = d.user_id AND c.status = @active)`,
+
+		`UPDATE learning_goals SET skill_id = @canonical, updated_at = @now WHERE skill_id = @duplicate`,
+
+		`UPDATE mentor_offers c SET
+			capacity = GREATEST(c.capacity, d.capacity),
+			available = c.available OR d.available,
+			updated_at = @now
+		FROM mentor_offers d
+		WHERE d.skill_id = @duplicate AND d.user_id = c.user_id AND c.skill_id = @canonical`,
+
+		`DELETE FROM mentor_offers d WHERE d.skill_id = @duplicate
+			AND EXISTS (SELECT 1 FROM mentor_offers c WHERE c.skill_id = @canonical AND c.user_id = d.user_id)`,
+
+		`UPDATE mentor_offers SET skill_id = @canonical, updated_at = @now WHERE skill_id = @duplicate`,
+
+		`UPDATE mentorships c SET status = @ended, ended_at = @now, updated_at = @now
+		FROM mentorships d
+		WHERE d.skill_id = @duplicate AND ` + sameMentorship + `
+			AND ` + mentorshipRank("d.status") + ` > ` + mentorshipRank("c.status"),
+
+		`UPDATE mentorships d SET status = @ended, ended_at = @now, updated_at = @now
+		WHERE d.skill_id = @duplicate
+			AND EXISTS (SELECT 1 FROM mentorships c WHERE ` + sameMentorship + `)`,
+
+		`UPDATE mentorships SET skill_id = @canonical, updated_at = @now WHERE skill_id = @duplicate`,
+
+		`UPDATE skill_targets c SET
+			min_level = CASE WHEN ` + levelRank("d.min_level") + ` > ` + levelRank("c.min_level") + ` THEN d.min_level ELSE c.min_level END,
+			min_people = GREATEST(c.min_people, d.min_people),
+			updated_at = @now
+		FROM skill_targets d
+		WHERE d.skill_id = @duplicate AND c.skill_id = @canonical AND c.scope_key = d.scope_key`,
+
+		`DELETE FROM skill_targets d WHERE d.skill_id = @duplicate
+			AND EXISTS (SELECT 1 FROM skill_targets c WHERE c.skill_id = @canonical AND c.scope_key = d.scope_key)`,
+
+		`UPDATE skill_targets SET skill_id = @canonical, updated_at = @now WHERE skill_id = @duplicate`,
+
+		// A canonical skill below the duplicate takes the duplicate's place
+		// in the hierarchy, so moving the duplicate's children cannot form
+		// a cycle.
+		`UPDATE skills SET parent_id = @parent, updated_at = @now
+		WHERE id = @canonical AND @duplicate IN (
+			WITH RECURSIVE ancestors AS (
+				SELECT parent_id FROM skills WHERE id = @canonical
+				UNION
+				SELECT s.parent_id FROM skills s JOIN ancestors a ON s.id = a.parent_id
+			)
+			SELECT parent_id FROM ancestors WHERE parent_id IS NOT NULL)`,
+
+		`UPDATE skills SET parent_id = @canonical, updated_at = @now WHERE parent_id = @duplicate`,
+
+		`UPDATE skill_synonyms SET skill_id = @canonical WHERE skill_id = @duplicate`,
+
+		`INSERT INTO skill_synonyms (skill_id, name, name_key, created_at)
+		VALUES (@canonical, @name, @key, @now)
+		ON CONFLICT (name_key) DO UPDATE SET skill_id = EXCLUDED.skill_id`,
+
+		`DELETE FROM skill_synonyms WHERE name_key = @ckey`,
+
+		`DELETE FROM skills WHERE id = @duplicate`,
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement, args).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// levelRank orders skill levels in SQL; unknown levels rank lowest.
+func levelRank(column string) string {
+	var b strings.Builder
+	b.WriteString("(CASE " + column)
+	for i, level := range models.SkillLevels {
+		fmt.Fprintf(&b, " WHEN '%s' THEN %d", level, i+1)
+	}
+	b.WriteString(" ELSE 0 END)")
+	return b.String()
+}
+
+// mentorshipRank orders open mentorship statuses in SQL by how far along
+// they are: active, then paused, then pending.
+func mentorshipRank(column string) string {
+	return fmt.Sprintf("(CASE %s WHEN '%s' THEN 3 WHEN '%s' THEN 2 ELSE 1 END)",
+		column, models.MentorshipStatusActive, models.MentorshipStatusPaused)
+}
+
+// visibleEndorsementsSQL counts the endorsements of the user skill with the
+// given alias that its owner has not hidden.
+func visibleEndorsementsSQL(userSkill string) string {
+	return "(SELECT COUNT(*) FROM endorsements e WHERE e.user_skill_id = " + userSkill + ".id AND NOT e.hidden)"
+}
+
+// endorsementWeightSQL sums the weights of the visible endorsements of the
+// user skill with the given alias by each endorser's level in the skill,
+// verified while valid and self-declared otherwise (see
+// models.EndorsementWeight). It takes the current time as its parameter.
+func endorsementWeightSQL(userSkill string) string {
+	var b strings.Builder
+	b.WriteString(`(SELECT COALESCE(SUM(CASE (CASE WHEN es.verified_level <> '' AND (es.verified_until IS NULL OR es.verified_until > ?)
+		THEN es.verified_level ELSE es.level END)`)
+	for _, level := range models.SkillLevels {
+		fmt.Fprintf(&b, " WHEN '%s' THEN %g", level, models.EndorsementWeight(level))
+	}
+	fmt.Fprintf(&b, ` ELSE %g END), 0)::float8
+		FROM endorsements e LEFT JOIN user_skills es ON es.user_id = e.endorser_id AND es.skill_id = %s.skill_id
+		WHERE e.user_skill_id = %s.id AND NOT e.hidden)`, models.EndorsementWeight(""), userSkill, userSkill)
+	return b.String()
+}
+
+// Skill category methods
+func (r *SkillRepository) CreateCategory(ctx context.Context, category *models.SkillCategory) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *SkillRepository) GetCategory(ctx context.Context, id uint) (*models.SkillCategory, error) {
+	var category models.SkillCategory
+	err := r.db.WithContext(ctx).First(&category, id).Error
+	return &category, err
+}
+
+// GetCategoryByName finds a category by name, ignoring case.
+func (r *SkillRepository) GetCategoryByName(ctx context.Context, name string) (*models.SkillCategory, error) {
+	var category models.SkillCategory
+	err := r.db.WithContext(ctx).Where("LOWER(name) = ?", models.SkillKey(name)).First(&category).Error
+	return &category, err
+}
+
+func (r *SkillRepository) ListCategories(ctx context.Context) ([]models.SkillCategory, error) {
+	var categories []models.SkillCategory
+	err := r.db.WithContext(ctx).Order("LOWER(name)").Find(&categories).Error
+	return categories, err
+}
+
+// UpdateCategory saves the category and renames it on its skills.
+func (r *SkillRepository) UpdateCategory(ctx context.Context, category *models.SkillCategory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Skill{}).
+			Where("category_id = ?", category.ID).
+			Update("category", category.Name).Error
+	})
+}
+
+// DeleteCategory deletes the category, leaving its skills uncategorised.
+func (r *SkillRepository) DeleteCategory(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Skill{}).
+			Where("category_id = ?", id).
+			Updates(map[string]interface{}{"category_id": nil, "category": ""}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.SkillCategory{}, id).Error
+	})
+}
+
+// Skill synonym methods
+func (r *SkillRepository) AddSynonym(ctx context.Context, synonym *models.SkillSynonym) error {
+	return r.db.WithContext(ctx).Create(synonym).Error
+}
+
+func (r *SkillRepository) GetSynonymByKey(ctx context.Context, key string) (*models.SkillSynonym, error) {
+	var synonym models.SkillSynonym
+	err := r.db.WithContext(ctx).Where("name_key = ?", key).First(&synonym).Error
+	return &synonym, err
+}
+
+func (r *SkillRepository) DeleteSynonym(ctx context.Context, id, skillID uint) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND skill_id = ?", id, skillID).
+		Delete(&models.SkillSynonym{})
+	return result.RowsAffected, result.Error
+}
+
+// User skill repository methods
+func (r *UserSkillRepository) Create(ctx context.Context, userSkill *models.UserSkill) error {
+	return r.db.WithContext(ctx).Create(userSkill).Error
+}
+
+func (r *UserSkillRepository) GetByID(ctx context.Context, id uint) (*models.UserSkill, error) {
+	var userSkill models.UserSkill
+	err := r.db.WithContext(ctx).Preload("Skill").First(&userSkill, id).Error
+	return &userSkill, err
+}
+
+func (r *UserSkillRepository) GetByUserAndSkill(ctx context.Context, userID, skillID uint) (*models.UserSkill, error) {
+	var userSkill models.UserSkill
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND skill_id = ?", userID, skillID).
+		First(&userSkill).Error
+	return &userSkill, err
+}
+
+// ListByUser returns the user's skills with their visible endorsements,
+// highest endorsement weight first. Endorsements by users blocking or
+// blocked by the viewer are left out.
+func (r *UserSkillRepository) ListByUser(ctx context.Context, userID, viewerID uint) ([]models.UserSkill, error) {
+	var userSkills []models.UserSkill
+	err := r.db.WithContext(ctx).
+		Preload("Skill").
+		Preload("Endorsements", func(db *gorm.DB) *gorm.DB {
+			return db.Where("NOT hidden").
+				Where(`NOT EXISTS (SELECT 1 FROM user_blocks b
+					WHERE (b.blocker_id = ? AND b.blocked_id = endorsements.endorser_id) OR (b.blocker_id = endorsements.endorser_id AND b.blocked_id = ?))`,
+					viewerID, viewerID).
+				Order("created_at DESC")
+		}).
+		Preload("Endorsements.Endorser.Profile").
+		Where("user_id = ?", userID).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                endorsementWeightSQL("user_skills") + " DESC, id",
+			Vars:               []interface{}{time.Now()},
+			WithoutParentheses: true,
+		}}).
+		Find(&userSkills).Error
+	return userSkills, err
+}
+
+// ListBySkillAndUsers returns the given users' user skills of one skill.
+func (r *UserSkillRepository) ListBySkillAndUsers(ctx context.Context, skillID uint, userIDs []uint) ([]models.UserSkill, error) {
+	var userSkills []models.UserSkill
+	if len(userIDs) == 0 {
+		return userSkills, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("skill_id = ? AND user_id IN ?", skillID, userIDs).
+		Find(&userSkills).Error
+	return userSkills, err
+}
+
+// ListByUsers returns the skills of the given users.
+func (r *UserSkillRepository) ListByUsers(ctx context.Context, userIDs []uint) ([]models.UserSkill, error) {
+	var userSkills []models.UserSkill
+	if len(userIDs) == 0 {
+		return userSkills, nil
+	}
+	err := r.db.WithContext(ctx).
+		Preload("Skill").
+		Where("user_id IN ?", userIDs).
+		Find(&userSkills).Error
+	return userSkills, err
+}
+
+func (r *UserSkillRepository) Update(ctx context.Context, userSkill *models.UserSkill) error {
+	return r.db.WithContext(ctx).Omit("User", "Skill", "Endorsements", "Assessments").Save(userSkill).Error
+}
+
+// Delete removes the user skill together with its endorsements and
+// assessments.
+func (r *UserSkillRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_skill_id = ?", id).Delete(&models.Endorsement{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_skill_id = ?", id).Delete(&models.SkillAssessment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.UserSkill{}, id).Error
+	})
+}
+
+// Endorsement repository methods
+func (r *EndorsementRepository) Create(ctx context.Context, endorsement *models.Endorsement) error {
+	return r.db.WithContext(ctx).Create(endorsement).Error
+}
+
+func (r *EndorsementRepository) Exists(ctx context.Context, userSkillID, endorserID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Endorsement{}).
+		Where("user_skill_id = ? AND endorser_id = ?", userSkillID, endorserID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *EndorsementRepository) GetByID(ctx context.Context, id uint) (*models.Endorsement, error) {
+	var endorsement models.Endorsement
+	err := r.db.WithContext(ctx).Preload("UserSkill").First(&endorsement, id).Error
+	return &endorsement, err
+}
+
+func (r *EndorsementRepository) GetByUserSkillAndEndorser(ctx context.Context, userSkillID, endorserID uint) (*models.Endorsement, error) {
+	var endorsement models.Endorsement
+	err := r.db.WithContext(ctx).
+		Where("user_skill_id = ? AND endorser_id = ?", userSkillID, endorserID).
+		First(&endorsement).Error
+	return &endorsement, err
+}
+
+// ListByUserSkill returns the endorsements of a user skill with their
+// endorsers, newest first, leaving out hidden ones unless includeHidden.
+func (r *EndorsementRepository) ListByUserSkill(ctx context.Context, userSkillID uint, includeHidden bool) ([]models.Endorsement, error) {
+	var endorsements []models.Endorsement
+	query := r.db.WithContext(ctx).Where("user_skill_id = ?", userSkillID)
+	if !includeHidden {
+		query = query.Where("NOT hidden")
+	}
+	err := query.
+		Preload("Endorser.Profile").
+		Order("created_at DESC, id DESC").
+		Find(&endorsements).Error
+	return endorsements, err
+}
+
+func (r *EndorsementRepository) SetHidden(ctx context.Context, id uint, hidden bool) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Endorsement{}).
+		Where("id = ?", id).
+		Update("hidden", hidden).Error
+}
+
+func (r *EndorsementRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Endorsement{}, id).Error
+}
+
+// ListReceivedSince returns endorsements of the user's skills made since the
+// given time, newest first.
+func (r *EndorsementRepository) ListReceivedSince(ctx context.Context, userID uint, since time.Time) ([]models.Endorsement, error) {
+	var endorsements []models.Endorsement
+	err := r.db.WithContext(ctx).
+		Preload("Endorser.Profile").
+		Preload("UserSkill.Skill").
+		Joins("JOIN user_skills ON user_skills.id = endorsements.user_skill_id").
+		Where("user_skills.user_id = ? AND endorsements.created_at >= ?", userID, since).
+		Order("endorsements.created_at DESC").
+		Find(&endorsements).Error
+	return endorsements, err
+}
+
+// ListTargets returns a department's or group's target skill profile.
+func (r *SkillRepository) ListTargets(ctx context.Context, scopeKey string) ([]models.SkillTarget, error) {
+	var targets []models.SkillTarget
+	err := r.db.WithContext(ctx).
+		Preload("Skill").
+		Where("scope_key = ?", scopeKey).
+		Order("id").
+		Find(&targets).Error
+	return targets, err
+}
+
+// ReplaceTargets replaces a department's or group's target skill profile.
+func (r *SkillRepository) ReplaceTargets(ctx context.Context, scopeKey string, targets []models.SkillTarget) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("scope_key = ?", scopeKey).Delete(&models.SkillTarget{}).Error; err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			return nil
+		}
+		return tx.Omit("Skill").Create(&targets).Error
+	})
+}
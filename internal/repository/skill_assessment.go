@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// assessableSkillsSQL selects the IDs of the skills an assessor may assess:
+// the skills and categories of their grants, or every skill for a grant
+// without either, together with the descendants of those skills. It takes
+// the assessor's user ID.
+const assessableSkillsSQL = `WITH RECURSIVE assessable AS (
+		SELECT s.id, 0 AS depth FROM skills s
+		JOIN skill_assessors a ON a.user_id = ? AND (
+			a.skill_id = s.id OR a.category_id = s.category_id OR (a.skill_id IS NULL AND a.category_id IS NULL))
+		WHERE s.deleted_at IS NULL
+		UNION
+		SELECT s.id, d.depth + 1 FROM skills s JOIN assessable d ON s.parent_id = d.id
+		WHERE s.deleted_at IS NULL AND d.depth < 20
+	)
+	SELECT id FROM assessable`
+
+// Skill assessor methods
+func (r *SkillAssessmentRepository) CreateAssessor(ctx context.Context, assessor *models.SkillAssessor) error {
+	return r.db.WithContext(ctx).Omit("User", "Skill", "Category").Create(assessor).Error
+}
+
+func (r *SkillAssessmentRepository) GetAssessor(ctx context.Context, id uint) (*models.SkillAssessor, error) {
+	var assessor models.SkillAssessor
+	err := r.db.WithContext(ctx).First(&assessor, id).Error
+	return &assessor, err
+}
+
+// ListAssessors returns assessor grants, optionally only those of one user
+// or one skill, newest first.
+func (r *SkillAssessmentRepository) ListAssessors(ctx context.Context, userID, skillID uint) ([]models.SkillAssessor, error) {
+	var assessors []models.SkillAssessor
+	query := r.db.WithContext(ctx).
+		Preload("User.Profile").
+		Preload("Skill").
+		Preload("Category")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if skillID != 0 {
+		query = query.Where("skill_id = ?", skillID)
+	}
+	err := query.Order("id DESC").Find(&assessors).Error
+	return assessors, err
+}
+
+func (r *SkillAssessmentRepository) DeleteAssessor(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.SkillAssessor{}, id).Error
+}
+
+// CanAssess reports whether one of the user's assessor grants covers the
+// skill.
+func (r *SkillAssessmentRepository) CanAssess(ctx context.Context, userID, skillID uint) (bool, error) {
+	var ok bool
+	err := r.db.WithContext(ctx).
+		Raw("SELECT ? IN ("+assessableSkillsSQL+")", skillID, userID).
+		Scan(&ok).Error
+	return ok, err
+}
+
+// Skill assessment methods
+
+// Create records the assessment and, unless the user skill has a more
+// recent one, makes it the user skill's verification.
+func (r *SkillAssessmentRepository) Create(ctx context.Context, assessment *models.SkillAssessment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Assessor").Create(assessment).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.UserSkill{}).
+			Where("id = ? AND (verified_at IS NULL OR verified_at <= ?)", assessment.UserSkillID, assessment.AssessedAt).
+			Updates(map[string]interface{}{
+				"verified_level": assessment.Level,
+				"verified_at":    assessment.AssessedAt,
+				"verified_until": assessment.ExpiresAt,
+				"updated_at":     time.Now(),
+			}).Error
+	})
+}
+
+// ListByUserSkill returns the assessment history of a user skill, most
+// recent first.
+func (r *SkillAssessmentRepository) ListByUserSkill(ctx context.Context, userSkillID uint) ([]models.SkillAssessment, error) {
+	var assessments []models.SkillAssessment
+	err := r.db.WithContext(ctx).
+		Preload("Assessor.Profile").
+		Where("user_skill_id = ?", userSkillID).
+		Order("assessed_at DESC, id DESC").
+		Find(&assessments).Error
+	return assessments, err
+}
+
+// ListExpiring returns the verified user skills of active users whose
+// verification ends before the given time, including those that already
+// expired, limited to skills the assessor may assess. The assessor's own
+// skills are left out. The soonest to expire come first.
+func (r *SkillAssessmentRepository) ListExpiring(ctx context.Context, assessorID uint, before time.Time, page, limit int) ([]models.UserSkill, error) {
+	var userSkills []models.UserSkill
+	offset := (page - 1) * limit
+	err := r.db.WithContext(ctx).
+		Preload("User.Profile").
+		Preload("Skill").
+		Joins("JOIN users u ON u.id = user_skills.user_id AND u.deleted_at IS NULL AND u.is_active").
+		Where("user_skills.verified_level <> '' AND user_skills.verified_until < ?", before).
+		Where("user_skills.user_id <> ?", assessorID).
+		Where("user_skills.skill_id IN ("+assessableSkillsSQL+")", assessorID).
+		Order("user_skills.verified_until, user_skills.id").
+		Limit(limit).
+		Offset(offset).
+		Find(&userSkills).Error
+	return userSkills, err
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// CatalogCategory is a skill category in an imported or exported catalog.
+type CatalogCategory struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CatalogSkill is a skill in an imported or exported catalog. Category and
+// Parent refer to other entries by name; a parent may also be given by one
+// of its synonyms.
+type CatalogSkill struct {
+	Name        string   `json:"name"`
+	Category    string   `json:"category,omitempty"`
+	Parent      string   `json:"parent,omitempty"`
+	Synonyms    []string `json:"synonyms,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// CatalogChange describes one category or skill an import created or
+// updated, with a line per changed field.
+type CatalogChange struct {
+	Action  string   `json:"action"` // create or update
+	Type    string   `json:"type"`   // category or skill
+	Name    string   `json:"name"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// CatalogReport is the outcome of an import. Conflicts are entries, or parts
+// of entries, that were skipped because they contradict the catalog.
+type CatalogReport struct {
+	DryRun    bool            `json:"dry_run"`
+	Changes   []CatalogChange `json:"changes"`
+	Conflicts []string        `json:"conflicts"`
+	Unchanged int             `json:"unchanged"`
+}
+
+// errDryRun rolls back a dry-run import.
+var errDryRun = errors.New("dry run")
+
+// ListAll returns every catalog skill with its parent and synonyms, ordered
+// by name.
+func (r *SkillRepository) ListAll(ctx context.Context) ([]models.Skill, error) {
+	var skills []models.Skill
+	err := r.db.WithContext(ctx).
+		Preload("Parent").
+		Preload("Synonyms", func(db *gorm.DB) *gorm.DB { return db.Order("name_key") }).
+		Order("LOWER(name)").
+		Find(&skills).Error
+	return skills, err
+}
+
+// ImportCatalog adds the categories and skills to the catalog in a single
+// transaction. Entries match existing skills by name or synonym. Imports only
+// add: descriptions, categories and parents given in the catalog replace
+// the current ones, synonyms are added, and nothing is removed, so importing
+// the same catalog again changes nothing. A dry run reports the same changes
+// and rolls them back.
+func (r *SkillRepository) ImportCatalog(ctx context.Context, categories []CatalogCategory, skills []CatalogSkill, dryRun bool) (*CatalogReport, error) {
+	report := &CatalogReport{DryRun: dryRun, Changes: []CatalogChange{}, Conflicts: []string{}}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		imp := &catalogImport{
+			tx:         tx,
+			report:     report,
+			categories: make(map[string]*models.SkillCategory),
+			changes:    make(map[string]int),
+		}
+		if err := imp.run(categories, skills); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+type catalogImport struct {
+	tx         *gorm.DB
+	report     *CatalogReport
+	categories map[string]*models.SkillCategory // by name key
+	changes    map[string]int                   // report.Changes index by type and name key
+}
+
+func (imp *catalogImport) run(categories []CatalogCategory, entries []CatalogSkill) error {
+	for _, c := range categories {
+		if _, err := imp.category(c.Name, c.Description); err != nil {
+			return err
+		}
+	}
+
+	// Skills are created first so that synonyms and parents can refer to
+	// entries anywhere in the catalog.
+	skills := make([]*models.Skill, len(entries))
+	for i, entry := range entries {
+		skill, err := imp.skill(entry)
+		if err != nil {
+			return err
+		}
+		skills[i] = skill
+	}
+	for i, entry := range entries {
+		if err := imp.synonyms(skills[i], entry); err != nil {
+			return err
+		}
+	}
+	for i, entry := range entries {
+		if err := imp.parent(skills[i], entry); err != nil {
+			return err
+		}
+	}
+
+	counted := make(map[uint]bool, len(skills))
+	for _, skill := range skills {
+		if _, changed := imp.changes["skill:"+models.SkillKey(skill.Name)]; !changed && !counted[skill.ID] {
+			counted[skill.ID] = true
+			imp.report.Unchanged++
+		}
+	}
+	return nil
+}
+
+// category finds or creates the named category. A non-empty description
+// replaces the current one.
+func (imp *catalogImport) category(name, description string) (*models.SkillCategory, error) {
+	key := models.SkillKey(name)
+	if category, ok := imp.categories[key]; ok && description == "" {
+		return category, nil
+	}
+
+	var category models.SkillCategory
+	err := imp.tx.Where("LOWER(name) = ?", key).First(&category).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		category = models.SkillCategory{Name: name, Description: description}
+		if err := imp.tx.Create(&category).Error; err != nil {
+			return nil, err
+		}
+		imp.record("create", "category", category.Name, "")
+	case err != nil:
+		return nil, err
+	case description != "" && description != category.Description:
+		category.Description = description
+		if err := imp.tx.Save(&category).Error; err != nil {
+			return nil, err
+		}
+		imp.record("update", "category", category.Name, "description changed")
+	}
+	imp.categories[key] = &category
+	return &category, nil
+}
+
+// skill finds the entry's skill by name or synonym, or creates it, and
+// applies its description and category.
+func (imp *catalogImport) skill(entry CatalogSkill) (*models.Skill, error) {
+	var category *models.SkillCategory
+	if entry.Category != "" {
+		var err error
+		if category, err = imp.category(entry.Category, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	skill, err := resolveSkill(imp.tx, entry.Name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		skill = &models.Skill{Name: entry.Name, Description: entry.Description}
+		if category != nil {
+			skill.CategoryID, skill.Category = &category.ID, category.Name
+		}
+		if err := imp.tx.Omit("Parent", "Children", "Synonyms", "UserSkills").Create(skill).Error; err != nil {
+			return nil, err
+		}
+		imp.record("create", "skill", skill.Name, "")
+		return skill, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	if entry.Description != "" && entry.Description != skill.Description {
+		skill.Description = entry.Description
+		changes = append(changes, "description changed")
+	}
+	if category != nil && (skill.CategoryID == nil || *skill.CategoryID != category.ID) {
+		changes = append(changes, fmt.Sprintf("category: %q -> %q", skill.Category, category.Name))
+		skill.CategoryID, skill.Category = &category.ID, category.Name
+	}
+	if len(changes) == 0 {
+		return skill, nil
+	}
+	if err := imp.tx.Omit("Parent", "Children", "Synonyms", "UserSkills").Save(skill).Error; err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		imp.record("update", "skill", skill.Name, change)
+	}
+	return skill, nil
+}
+
+// synonyms adds the entry's synonyms that are not yet names or synonyms of
+// any skill.
+func (imp *catalogImport) synonyms(skill *models.Skill, entry CatalogSkill) error {
+	for _, name := range entry.Synonyms {
+		key := models.SkillKey(name)
+		if key == models.SkillKey(skill.Name) {
+			continue
+		}
+		existing, err := resolveSkill(imp.tx, name)
+		if err == nil {
+			if existing.ID != skill.ID {
+				imp.conflict("synonym %q of %q already refers to %q", name, entry.Name, existing.Name)
+			}
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := imp.tx.Create(&models.SkillSynonym{SkillID: skill.ID, Name: name, NameKey: key}).Error; err != nil {
+			return err
+		}
+		imp.record("update", "skill", skill.Name, fmt.Sprintf("synonym added: %q", name))
+	}
+	return nil
+}
+
+// parent moves the skill below the entry's parent unless that would make
+// the skill its own ancestor.
+func (imp *catalogImport) parent(skill *models.Skill, entry CatalogSkill) error {
+	if entry.Parent == "" {
+		return nil
+	}
+	parent, err := resolveSkill(imp.tx, entry.Parent)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		imp.conflict("parent %q of %q not found", entry.Parent, entry.Name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if skill.ParentID != nil && *skill.ParentID == parent.ID {
+		return nil
+	}
+	if parent.ID == skill.ID {
+		imp.conflict("%q cannot be its own parent", entry.Name)
+		return nil
+	}
+	ancestors, err := listAncestorIDs(imp.tx, parent.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ancestors {
+		if id == skill.ID {
+			imp.conflict("parent %q of %q is one of its descendants", entry.Parent, entry.Name)
+			return nil
+		}
+	}
+
+	from := ""
+	if skill.ParentID != nil {
+		var current models.Skill
+		if err := imp.tx.Select("name").First(&current, *skill.ParentID).Error; err != nil {
+			return err
+		}
+		from = current.Name
+	}
+	if err := imp.tx.Model(skill).Update("parent_id", parent.ID).Error; err != nil {
+		return err
+	}
+	skill.ParentID = &parent.ID
+	imp.record("update", "skill", skill.Name, fmt.Sprintf("parent: %q -> %q", from, parent.Name))
+	return nil
+}
+
+// record adds a change to the report, folding changes to the same category
+// or skill into one entry. Updates to an entry created by this import stay
+// part of the creation.
+func (imp *catalogImport) record(action, typ, name, change string) {
+	key := typ + ":" + models.SkillKey(name)
+	i, ok := imp.changes[key]
+	if !ok {
+		imp.report.Changes = append(imp.report.Changes, CatalogChange{Action: action, Type: typ, Name: name})
+		i = len(imp.report.Changes) - 1
+		imp.changes[key] = i
+	}
+	if change != "" {
+		imp.report.Changes[i].Changes = append(imp.report.Changes[i].Changes, change)
+	}
+}
+
+func (imp *catalogImport) conflict(format string, args ...interface{}) {
+	imp.report.Conflicts = append(imp.report.Conflicts, fmt.Sprintf(format, args...))
+}
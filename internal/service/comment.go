@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+var ErrCommentNotFound = fmt.Errorf("%w: comment not found", ErrNotFound)
+
+type CommentService struct {
+	deps ServicesDeps
+}
+
+func NewCommentService(deps ServicesDeps) *CommentService {
+	return &CommentService{deps: deps}
+}
+
+type CreateCommentInput struct {
+	PostID   uint   `json:"-"`
+	UserID   uint   `json:"-"`
+	Content  string `json:"content" binding:"required,max=5000"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type UpdateCommentInput struct {
+	Content string `json:"content" binding:"required,max=5000"`
+}
+
+// Create adds a comment to a post. Replies are kept one level deep: a reply
+// to a reply is threaded under the top-level comment, while the author of
+// the comment being answered is still the one notified.
+func (s *CommentService) Create(ctx context.Context, input CreateCommentInput) (*models.Comment, error) {
+	post, err := getReadablePost(ctx, s.deps, input.PostID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		PostID:  post.ID,
+		UserID:  input.UserID,
+		Content: input.Content,
+	}
+	var parent *models.Comment
+	if input.ParentID != nil {
+		parent, err = getComment(ctx, s.deps, *input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.PostID != post.ID {
+			return nil, fmt.Errorf("%w: parent comment belongs to another post", ErrInvalidInput)
+		}
+		threadID := parent.ID
+		if parent.ParentID != nil {
+			threadID = *parent.ParentID
+		}
+		comment.ParentID = &threadID
+	}
+
+	if err := s.deps.Repos.Comment.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	link := commentLink(comment)
+	notified := []uint{post.UserID}
+	if parent != nil {
+		notify(ctx, s.deps, Notice{
+			ActorID: input.UserID,
+			Title:   "New reply",
+			Action:  "replied to your comment",
+			Link:    link,
+			Data:    models.CommentReplyData{PostID: post.ID, CommentID: comment.ID, ParentID: parent.ID},
+		}, parent.UserID)
+		notified = append(notified, parent.UserID)
+	}
+	if parent == nil || parent.UserID != post.UserID {
+		notify(ctx, s.deps, Notice{
+			ActorID: input.UserID,
+			Title:   "New comment",
+			Action:  "commented on your post",
+			Link:    link,
+			Data:    models.CommentData{PostID: post.ID, CommentID: comment.ID},
+		}, post.UserID)
+	}
+	s.notifyMentions(ctx, post, comment, "", notified...)
+
+	return comment, nil
+}
+
+// ListByPost returns the post's top-level comments with their replies.
+func (s *CommentService) ListByPost(ctx context.Context, postID, viewerID uint, page, limit int) ([]models.Comment, error) {
+	if _, err := getReadablePost(ctx, s.deps, postID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Comment.ListByPost(ctx, postID, page, limit)
+}
+
+// Update edits a comment. Only its author may edit it; users mentioned for
+// the first time are notified.
+func (s *CommentService) Update(ctx context.Context, id, userID uint, input UpdateCommentInput) (*models.Comment, error) {
+	comment, err := getComment(ctx, s.deps, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, fmt.Errorf("%w: only the author can edit a comment", ErrForbidden)
+	}
+
+	previous := comment.Content
+	comment.Content = input.Content
+	if err := s.deps.Repos.Comment.Update(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	if post, err := getPost(ctx, s.deps, comment.PostID); err == nil {
+		s.notifyMentions(ctx, post, comment, previous)
+	}
+	return comment, nil
+}
+
+// Delete removes a comment and its replies. The comment's author and the
+// post's author may delete it.
+func (s *CommentService) Delete(ctx context.Context, id, userID uint) error {
+	comment, err := getComment(ctx, s.deps, id)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != userID {
+		post, err := getPost(ctx, s.deps, comment.PostID)
+		if err != nil {
+			return err
+		}
+		if post.UserID != userID {
+			return fmt.Errorf("%w: only the comment or post author can delete a comment", ErrForbidden)
+		}
+	}
+	return s.deps.Repos.Comment.Delete(ctx, id)
+}
+
+func (s *CommentService) notifyMentions(ctx context.Context, post *models.Post, comment *models.Comment, previous string, skip ...uint) {
+	notifyMentions(ctx, s.deps, Notice{
+		ActorID: comment.UserID,
+		Title:   "You were mentioned",
+		Action:  "mentioned you in a comment",
+		Link:    commentLink(comment),
+		Data:    models.MentionData{PostID: post.ID, CommentID: &comment.ID},
+	}, comment.Content, previous, func(userID uint) bool {
+		return canReadPost(ctx, s.deps, post, userID) == nil
+	}, skip...)
+}
+
+func getComment(ctx context.Context, deps ServicesDeps, id uint) (*models.Comment, error) {
+	comment, err := deps.Repos.Comment.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+func commentLink(comment *models.Comment) string {
+	return fmt.Sprintf("/posts/%d#comment-%d", comment.PostID, comment.ID)
+}
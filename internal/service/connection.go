@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrConnectionNotFound = fmt.Errorf("%w: connection not found", ErrNotFound)
+	ErrAlreadyConnected   = fmt.Errorf("%w: already connected", ErrConflict)
+	ErrConnectionPending  = fmt.Errorf("%w: connection request already pending", ErrConflict)
+	ErrConnectionAnswered = fmt.Errorf("%w: connection request was already answered", ErrConflict)
+)
+
+type ConnectionService struct {
+	deps ServicesDeps
+}
+
+func NewConnectionService(deps ServicesDeps) *ConnectionService {
+	return &ConnectionService{deps: deps}
+}
+
+// Send asks targetID to connect. If targetID already asked the user, their
+// request is accepted instead. A previously rejected request may be sent
+// again.
+func (s *ConnectionService) Send(ctx context.Context, userID, targetID uint) (*models.Connection, error) {
+	if userID == targetID {
+		return nil, fmt.Errorf("%w: cannot connect to yourself", ErrInvalidInput)
+	}
+	if _, err := s.deps.Repos.User.GetByID(ctx, targetID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user not found", ErrNotFound)
+		}
+		return nil, err
+	}
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, fmt.Errorf("%w: cannot connect with this user", ErrForbidden)
+	}
+
+	existing, err := s.deps.Repos.Connection.GetBetween(ctx, userID, targetID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		connection := &models.Connection{UserID: userID, TargetID: targetID, Status: models.ConnectionStatusPending}
+		if err := s.deps.Repos.Connection.Create(ctx, connection); err != nil {
+			return nil, err
+		}
+		s.notifyRequest(ctx, connection)
+		return connection, nil
+	case err != nil:
+		return nil, err
+	case existing.Status == models.ConnectionStatusAccepted:
+		return nil, ErrAlreadyConnected
+	case existing.Status == models.ConnectionStatusPending && existing.TargetID == userID:
+		return existing, s.accept(ctx, existing)
+	case existing.Status == models.ConnectionStatusPending:
+		return nil, ErrConnectionPending
+	}
+
+	// Re-sending after a rejection reuses the row, with the roles of the
+	// two users set by who is asking now.
+	existing.UserID = userID
+	existing.TargetID = targetID
+	existing.Status = models.ConnectionStatusPending
+	if err := s.deps.Repos.Connection.Update(ctx, existing); err != nil {
+		return nil, err
+	}
+	s.notifyRequest(ctx, existing)
+	return existing, nil
+}
+
+func (s *ConnectionService) List(ctx context.Context, userID uint, page, limit int) ([]models.Connection, error) {
+	return s.deps.Repos.Connection.ListAccepted(ctx, userID, page, limit)
+}
+
+// ListPending returns requests awaiting the user's answer.
+func (s *ConnectionService) ListPending(ctx context.Context, userID uint) ([]models.Connection, error) {
+	return s.deps.Repos.Connection.ListPending(ctx, userID)
+}
+
+// Accept accepts a pending request addressed to the user.
+func (s *ConnectionService) Accept(ctx context.Context, id, userID uint) (*models.Connection, error) {
+	connection, err := s.getIncoming(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return connection, s.accept(ctx, connection)
+}
+
+// Reject declines a pending request addressed to the user. The requester is
+// not notified.
+func (s *ConnectionService) Reject(ctx context.Context, id, userID uint) error {
+	connection, err := s.getIncoming(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	connection.Status = models.ConnectionStatusRejected
+	return s.deps.Repos.Connection.Update(ctx, connection)
+}
+
+// Remove deletes a connection or withdraws a request. Either user may do so.
+func (s *ConnectionService) Remove(ctx context.Context, id, userID uint) error {
+	connection, err := s.getConnection(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	return s.deps.Repos.Connection.Delete(ctx, connection.ID)
+}
+
+func (s *ConnectionService) accept(ctx context.Context, connection *models.Connection) error {
+	connection.Status = models.ConnectionStatusAccepted
+	if err := s.deps.Repos.Connection.Update(ctx, connection); err != nil {
+		return err
+	}
+	notify(ctx, s.deps, Notice{
+		ActorID: connection.TargetID,
+		Title:   "Connection accepted",
+		Action:  "accepted your connection request",
+		Link:    fmt.Sprintf("/users/%d", connection.TargetID),
+		Data:    models.ConnectionAcceptedData{ConnectionID: connection.ID},
+	}, connection.UserID)
+	return nil
+}
+
+func (s *ConnectionService) notifyRequest(ctx context.Context, connection *models.Connection) {
+	notify(ctx, s.deps, Notice{
+		ActorID: connection.UserID,
+		Title:   "Connection request",
+		Action:  "wants to connect with you",
+		Link:    "/connections/pending",
+		Data:    models.ConnectionRequestData{ConnectionID: connection.ID},
+	}, connection.TargetID)
+}
+
+// getConnection loads a connection the user is part of. Connections between
+// other users are reported as not found.
+func (s *ConnectionService) getConnection(ctx context.Context, id, userID uint) (*models.Connection, error) {
+	connection, err := s.deps.Repos.Connection.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && connection.UserID != userID && connection.TargetID != userID) {
+		return nil, ErrConnectionNotFound
+	}
+	return connection, err
+}
+
+// getIncoming loads a pending request addressed to the user.
+func (s *ConnectionService) getIncoming(ctx context.Context, id, userID uint) (*models.Connection, error) {
+	connection, err := s.getConnection(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if connection.TargetID != userID {
+		return nil, fmt.Errorf("%w: only the recipient can answer a connection request", ErrForbidden)
+	}
+	if connection.Status != models.ConnectionStatusPending {
+		return nil, ErrConnectionAnswered
+	}
+	return connection, nil
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/pkg/mailer"
+)
+
+const (
+	// emailBatchSize is how many users or notifications are loaded at once.
+	emailBatchSize = 200
+	// digestItemLimit caps the notifications listed in one digest.
+	digestItemLimit = 20
+	// digestPostLimit caps the top posts listed in one digest.
+	digestPostLimit = 5
+	// digestClaimTimeout is how long a claimed digest that never completed
+	// blocks a retry, e.g. after the process died while sending it.
+	digestClaimTimeout = time.Hour
+	// excerptLength is the number of characters shown of a post.
+	excerptLength = 140
+)
+
+//go:embed templates/*.tmpl
+var emailTemplateFS embed.FS
+
+var (
+	htmlEmailTemplates = htmltemplate.Must(htmltemplate.ParseFS(emailTemplateFS, "templates/*.html.tmpl"))
+	textEmailTemplates = texttemplate.Must(texttemplate.ParseFS(emailTemplateFS, "templates/*.txt.tmpl"))
+)
+
+// EmailService delivers notifications on the e-mail channel and sends the
+// periodic digests.
+type EmailService struct {
+	deps ServicesDeps
+}
+
+func NewEmailService(deps ServicesDeps) *EmailService {
+	return &EmailService{deps: deps}
+}
+
+type digestLink struct {
+	Text string
+	URL  string
+}
+
+type digestRequest struct {
+	Name string
+	URL  string
+}
+
+type digestEndorsement struct {
+	Endorser string
+	Skill    string
+	Comment  string
+}
+
+type digestPost struct {
+	Author  string
+	Group   string
+	Excerpt string
+	URL     string
+}
+
+// digestView is the data rendered by the digest templates.
+type digestView struct {
+	Name           string
+	Frequency      string
+	Notifications  []digestLink
+	Requests       []digestRequest
+	Endorsements   []digestEndorsement
+	Posts          []digestPost
+	UnreadMessages int64
+	MessagesURL    string
+	SettingsURL    string
+}
+
+func (v *digestView) items() int {
+	n := len(v.Notifications) + len(v.Requests) + len(v.Endorsements) + len(v.Posts)
+	if v.UnreadMessages > 0 {
+		n++
+	}
+	return n
+}
+
+// notificationView is the data rendered by the notification templates.
+type notificationView struct {
+	Name        string
+	Text        string
+	URL         string
+	SettingsURL string
+}
+
+// Run sends pending notification e-mails and due digests every interval
+// until ctx is cancelled.
+func (s *EmailService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.SendNotificationEmails(ctx, now); err != nil {
+				s.deps.Logger.Error("Failed to send notification emails", "error", err)
+			}
+			if err := s.SendDigests(ctx, now); err != nil {
+				s.deps.Logger.Error("Failed to send digests", "error", err)
+			}
+		}
+	}
+}
+
+// SendNotificationEmails mails every notification still pending on the
+// e-mail channel. Notifications of recipients in their do-not-disturb hours
+// stay pending until the window ends; notifications read in the meantime are
+// dropped. Each notification is claimed before it is sent, so concurrent or
+// restarted jobs never mail it twice.
+func (s *EmailService) SendNotificationEmails(ctx context.Context, now time.Time) error {
+	var afterID uint
+	for {
+		batch, err := s.deps.Repos.Notification.ListPendingEmail(ctx, afterID, emailBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		afterID = batch[len(batch)-1].ID
+
+		userIDs := make([]uint, 0, len(batch))
+		for _, n := range batch {
+			userIDs = append(userIDs, n.UserID)
+		}
+		settings, err := s.deps.Repos.Notification.GetSettingsFor(ctx, uniqueIDs(userIDs, 0))
+		if err != nil {
+			return err
+		}
+
+		for i := range batch {
+			n := &batch[i]
+			if !n.IsRead && settings[n.UserID].InQuietHours(now) {
+				continue
+			}
+			claimed, err := s.deps.Repos.Notification.ClaimEmail(ctx, n.ID)
+			if err != nil {
+				return err
+			}
+			if !claimed || n.IsRead || n.User == nil || !n.User.IsActive {
+				continue
+			}
+			if err := s.sendNotification(ctx, n); err != nil {
+				s.deps.Logger.Error("Failed to email notification", "notification_id", n.ID, "user_id", n.UserID, "error", err)
+			}
+		}
+	}
+}
+
+func (s *EmailService) sendNotification(ctx context.Context, n *models.Notification) error {
+	text := n.Message
+	if text == "" {
+		text = n.Title
+	}
+	view := notificationView{
+		Name:        displayName(n.User),
+		Text:        text,
+		URL:         s.url(n.Link),
+		SettingsURL: s.url("/settings/notifications"),
+	}
+	subject := n.Title
+	if subject == "" {
+		subject = text
+	}
+	return s.send(ctx, mailer.Message{
+		ID:      fmt.Sprintf("notification-%d", n.ID),
+		To:      n.User.Email,
+		Subject: subject,
+	}, "notification", view)
+}
+
+// SendDigests sends every digest that is due at now. Daily digests are due
+// from DigestHour in the user's time zone and cover the preceding 24 hours;
+// weekly digests are due from DigestHour on Monday and cover the preceding
+// week. Each (user, period) digest is claimed in the database before it is
+// built, so a restarted or concurrent job skips digests already sent.
+func (s *EmailService) SendDigests(ctx context.Context, now time.Time) error {
+	var afterID uint
+	for {
+		users, err := s.deps.Repos.User.ListActive(ctx, afterID, emailBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		afterID = users[len(users)-1].ID
+
+		userIDs := make([]uint, 0, len(users))
+		for _, u := range users {
+			userIDs = append(userIDs, u.ID)
+		}
+		settings, err := s.deps.Repos.Notification.GetSettingsFor(ctx, userIDs)
+		if err != nil {
+			return err
+		}
+
+		for i := range users {
+			user := &users[i]
+			period, since, due := digestPeriod(settings[user.ID], s.deps.Config.Email.DigestHour, now)
+			if !due {
+				continue
+			}
+			claimed, err := s.deps.Repos.Notification.ClaimDigest(ctx, user.ID, period, now, now.Add(-digestClaimTimeout))
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+			if err := s.sendDigest(ctx, user, settings[user.ID].DigestFrequency, period, since, now); err != nil {
+				s.deps.Logger.Error("Failed to send digest", "user_id", user.ID, "period", period, "error", err)
+			}
+		}
+	}
+}
+
+// digestPeriod returns the key and start of the user's latest digest period
+// at now, and whether that digest is due yet.
+func digestPeriod(settings *models.NotificationSettings, hour int, now time.Time) (string, time.Time, bool) {
+	loc := settings.Location()
+	local := now.In(loc)
+	switch settings.DigestFrequency {
+	case models.DigestDaily:
+		due := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, loc)
+		if local.Before(due) {
+			return "", time.Time{}, false
+		}
+		return models.DigestDaily + ":" + local.Format("2006-01-02"), due.AddDate(0, 0, -1), true
+	case models.DigestWeekly:
+		sinceMonday := (int(local.Weekday()) + 6) % 7
+		due := time.Date(local.Year(), local.Month(), local.Day()-sinceMonday, hour, 0, 0, 0, loc)
+		if local.Before(due) {
+			return "", time.Time{}, false
+		}
+		year, week := local.ISOWeek()
+		return fmt.Sprintf("%s:%d-W%02d", models.DigestWeekly, year, week), due.AddDate(0, 0, -7), true
+	default:
+		return "", time.Time{}, false
+	}
+}
+
+func (s *EmailService) sendDigest(ctx context.Context, user *models.User, frequency, period string, since, now time.Time) error {
+	view, notificationIDs, err := s.buildDigest(ctx, user, frequency, since)
+	if err != nil {
+		return err
+	}
+	items := view.items()
+	if items > 0 {
+		err := s.send(ctx, mailer.Message{
+			ID:      fmt.Sprintf("digest-%d-%s", user.ID, period),
+			To:      user.Email,
+			Subject: fmt.Sprintf("Your %s SkillFlow digest", frequency),
+		}, "digest", view)
+		if err != nil {
+			return err
+		}
+		if err := s.deps.Repos.Notification.ClearDigest(ctx, notificationIDs); err != nil {
+			s.deps.Logger.Warn("Failed to clear digested notifications", "user_id", user.ID, "error", err)
+		}
+	}
+	return s.deps.Repos.Notification.MarkDigestSent(ctx, user.ID, period, items, now)
+}
+
+// buildDigest collects the digest contents, honouring the digest channel of
+// each category. It also returns the IDs of the included notifications.
+func (s *EmailService) buildDigest(ctx context.Context, user *models.User, frequency string, since time.Time) (*digestView, []uint, error) {
+	preferences, err := s.deps.Repos.Notification.GetPreferences(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	wants := make(map[string]bool, len(preferences))
+	for _, p := range preferences {
+		wants[p.Category] = p.Digest
+	}
+
+	view := &digestView{
+		Name:        displayName(user),
+		Frequency:   frequency,
+		MessagesURL: s.url("/messages"),
+		SettingsURL: s.url("/settings/notifications"),
+	}
+
+	notifications, err := s.deps.Repos.Notification.ListForDigest(ctx, user.ID, since, digestItemLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]uint, 0, len(notifications))
+	for _, n := range notifications {
+		text := n.Message
+		if text == "" {
+			text = n.Title
+		}
+		view.Notifications = append(view.Notifications, digestLink{Text: text, URL: s.url(n.Link)})
+		ids = append(ids, n.ID)
+	}
+
+	if wants[models.NotificationCategoryConnection] {
+		pending, err := s.deps.Repos.Connection.ListPending(ctx, user.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range pending {
+			view.Requests = append(view.Requests, digestRequest{
+				Name: displayName(c.User),
+				URL:  s.url("/connections/pending"),
+			})
+		}
+	}
+
+	if wants[models.NotificationCategoryEndorsement] {
+		endorsements, err := s.deps.Repos.Endorsement.ListReceivedSince(ctx, user.ID, since)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, e := range endorsements {
+			skill := "a skill"
+			if e.UserSkill != nil && e.UserSkill.Skill != nil {
+				skill = e.UserSkill.Skill.Name
+			}
+			view.Endorsements = append(view.Endorsements, digestEndorsement{
+				Endorser: displayName(e.Endorser),
+				Skill:    skill,
+				Comment:  e.Comment,
+			})
+		}
+	}
+
+	if wants[models.NotificationCategoryMessage] {
+		if view.UnreadMessages, err = s.deps.Repos.Conversation.CountUnread(ctx, user.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	posts, err := s.deps.Repos.Post.TopForUser(ctx, user.ID, since, digestPostLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range posts {
+		post := digestPost{
+			Author:  displayName(p.User),
+			Excerpt: excerpt(p.Content, excerptLength),
+			URL:     s.url(fmt.Sprintf("/posts/%d", p.ID)),
+		}
+		if p.Group != nil {
+			post.Group = p.Group.Name
+		}
+		view.Posts = append(view.Posts, post)
+	}
+
+	return view, ids, nil
+}
+
+// send renders the named template pair and delivers the message.
+func (s *EmailService) send(ctx context.Context, msg mailer.Message, name string, data interface{}) error {
+	var text, html bytes.Buffer
+	if err := textEmailTemplates.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return err
+	}
+	if err := htmlEmailTemplates.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return err
+	}
+	msg.Text = text.String()
+	msg.HTML = html.String()
+	return s.deps.Mailer.Send(ctx, msg)
+}
+
+// url turns an app path into an absolute link.
+func (s *EmailService) url(path string) string {
+	if path == "" {
+		return ""
+	}
+	return strings.TrimRight(s.deps.Config.Email.BaseURL, "/") + path
+}
+
+func displayName(user *models.User) string {
+	if user == nil {
+		return "Someone"
+	}
+	if user.Profile != nil && user.Profile.DisplayName != "" {
+		return user.Profile.DisplayName
+	}
+	return user.Username
+}
+
+// excerpt shortens text to at most n characters on a word boundary.
+func excerpt(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	cut := string([]rune(text)[:n])
+	if i := strings.LastIndex(cut, " "); i > n/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
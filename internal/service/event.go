@@ -0,0 +1,497 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	_ "time/tzdata" // validate event timezones without relying on the host zoneinfo
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/pkg/ical"
+	"gorm.io/gorm"
+)
+
+// eventReminderLead is how long before an event starts attendees are
+// reminded.
+const eventReminderLead = time.Hour
+
+// calendarFeedLimit bounds the number of events in a calendar feed, which
+// starts a month back.
+const calendarFeedLimit = 500
+
+// calendarTokenBytes is the amount of randomness behind a calendar feed
+// token.
+const calendarTokenBytes = 24
+
+type EventService struct {
+	deps ServicesDeps
+}
+
+func NewEventService(deps ServicesDeps) *EventService {
+	return &EventService{deps: deps}
+}
+
+type CreateEventInput struct {
+	GroupID     uint      `json:"-"`
+	UserID      uint      `json:"-"`
+	Title       string    `json:"title" binding:"required,max=200"`
+	Description string    `json:"description"`
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	Timezone    string    `json:"timezone"`
+	Location    string    `json:"location"`
+	MeetingURL  string    `json:"meeting_url" binding:"omitempty,url"`
+	Capacity    int       `json:"capacity" binding:"min=0"`
+}
+
+type UpdateEventInput struct {
+	Title       string     `json:"title" binding:"max=200"`
+	Description string     `json:"description"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Timezone    string     `json:"timezone"`
+	Location    string     `json:"location"`
+	MeetingURL  string     `json:"meeting_url" binding:"omitempty,url"`
+	Capacity    *int       `json:"capacity" binding:"omitempty,min=0"`
+}
+
+var (
+	ErrEventNotFound = fmt.Errorf("%w: event not found", ErrNotFound)
+	ErrEventEnded    = fmt.Errorf("%w: event has already ended", ErrConflict)
+	ErrEventTime     = fmt.Errorf("%w: event must end after it starts", ErrInvalidInput)
+	ErrEventTimezone = fmt.Errorf("%w: unknown timezone", ErrInvalidInput)
+	ErrRSVPStatus    = fmt.Errorf("%w: RSVP status must be going, maybe or not_going", ErrInvalidInput)
+
+	// ErrCalendarToken is returned for an unknown or revoked calendar feed
+	// token.
+	ErrCalendarToken = errors.New("invalid calendar feed token")
+)
+
+// Create schedules an event. Group moderators and above can organize events.
+func (s *EventService) Create(ctx context.Context, input CreateEventInput) (*models.GroupEvent, error) {
+	if _, err := getGroup(ctx, s.deps, input.GroupID); err != nil {
+		return nil, err
+	}
+	if _, err := requireGroupRole(ctx, s.deps, input.GroupID, input.UserID, models.GroupRoleModerator); err != nil {
+		return nil, err
+	}
+
+	event := &models.GroupEvent{
+		GroupID:     input.GroupID,
+		CreatorID:   input.UserID,
+		Title:       strings.TrimSpace(input.Title),
+		Description: input.Description,
+		StartsAt:    input.StartsAt.UTC(),
+		EndsAt:      input.EndsAt.UTC(),
+		Timezone:    input.Timezone,
+		Location:    input.Location,
+		MeetingURL:  input.MeetingURL,
+		Capacity:    input.Capacity,
+	}
+	if err := validateEvent(event); err != nil {
+		return nil, err
+	}
+
+	if err := s.deps.Repos.Event.Create(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (s *EventService) GetByID(ctx context.Context, id, viewerID uint) (*models.GroupEvent, error) {
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireEventAccess(ctx, event, viewerID); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ListByGroup returns events that have not ended before from, optionally
+// bounded by to.
+func (s *EventService) ListByGroup(ctx context.Context, groupID, viewerID uint, from, to time.Time, page, limit int) ([]models.GroupEvent, error) {
+	group, err := getVisibleGroup(ctx, s.deps, groupID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireGroupContentAccess(ctx, s.deps, group, viewerID); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Event.ListByGroup(ctx, groupID, from, to, page, limit)
+}
+
+// Update lets the organizer or a group moderator change an event. Raising the
+// capacity promotes waitlisted attendees.
+func (s *EventService) Update(ctx context.Context, id, userID uint, input UpdateEventInput) (*models.GroupEvent, error) {
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireOrganizer(ctx, event, userID); err != nil {
+		return nil, err
+	}
+
+	if input.Title != "" {
+		event.Title = strings.TrimSpace(input.Title)
+	}
+	if input.Description != "" {
+		event.Description = input.Description
+	}
+	if input.StartsAt != nil {
+		event.StartsAt = input.StartsAt.UTC()
+		event.ReminderSentAt = nil
+	}
+	if input.EndsAt != nil {
+		event.EndsAt = input.EndsAt.UTC()
+	}
+	if input.Timezone != "" {
+		event.Timezone = input.Timezone
+	}
+	if input.Location != "" {
+		event.Location = input.Location
+	}
+	if input.MeetingURL != "" {
+		event.MeetingURL = input.MeetingURL
+	}
+	if input.Capacity != nil {
+		event.Capacity = *input.Capacity
+	}
+	if err := validateEvent(event); err != nil {
+		return nil, err
+	}
+
+	if err := s.deps.Repos.Event.Update(ctx, event); err != nil {
+		return nil, err
+	}
+
+	promoted, err := s.deps.Repos.Event.PromoteWaitlisted(ctx, event.ID)
+	if err != nil {
+		s.deps.Logger.Error("Failed to promote waitlisted attendees", "event_id", event.ID, "error", err)
+	}
+	s.notifyPromoted(ctx, event, promoted)
+
+	return event, nil
+}
+
+// Delete cancels the event and notifies everyone who had not declined.
+func (s *EventService) Delete(ctx context.Context, id, userID uint) error {
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.requireOrganizer(ctx, event, userID); err != nil {
+		return err
+	}
+
+	attendees, err := s.deps.Repos.Event.ListAttendeeIDs(ctx, id, models.RSVPGoing, models.RSVPMaybe, models.RSVPWaitlisted)
+	if err != nil {
+		return err
+	}
+	if err := s.deps.Repos.Event.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if event.EndsAt.After(time.Now()) {
+		notify(ctx, s.deps, Notice{
+			ActorID: userID,
+			Title:   "Event cancelled",
+			Message: fmt.Sprintf("%s has been cancelled", event.Title),
+			Link:    fmt.Sprintf("/groups/%d/events", event.GroupID),
+			Data:    models.EventCancelledData{GroupID: event.GroupID, EventID: event.ID},
+		}, attendees...)
+	}
+
+	return nil
+}
+
+// RSVP records the user's answer. Answering "going" to a full event puts the
+// user on the waitlist; the returned RSVP carries the effective status.
+func (s *EventService) RSVP(ctx context.Context, eventID, userID uint, status string) (*models.EventRSVP, error) {
+	switch status {
+	case models.RSVPGoing, models.RSVPMaybe, models.RSVPNotGoing:
+	default:
+		return nil, ErrRSVPStatus
+	}
+
+	event, err := s.getEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getVisibleGroup(ctx, s.deps, event.GroupID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := requireGroupRole(ctx, s.deps, event.GroupID, userID, models.GroupRoleMember); err != nil {
+		return nil, err
+	}
+	if !event.EndsAt.After(time.Now()) {
+		return nil, ErrEventEnded
+	}
+
+	rsvp, promoted, err := s.deps.Repos.Event.SetRSVP(ctx, eventID, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyPromoted(ctx, event, promoted)
+
+	return rsvp, nil
+}
+
+// GetAttendees returns RSVPs grouped by status, waitlist in queue order.
+func (s *EventService) GetAttendees(ctx context.Context, eventID, viewerID uint) (map[string][]models.EventRSVP, error) {
+	event, err := s.getEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireEventAccess(ctx, event, viewerID); err != nil {
+		return nil, err
+	}
+
+	rsvps, err := s.deps.Repos.Event.ListRSVPs(ctx, eventID, models.RSVPGoing, models.RSVPMaybe, models.RSVPWaitlisted)
+	if err != nil {
+		return nil, err
+	}
+
+	attendees := map[string][]models.EventRSVP{
+		models.RSVPGoing:      {},
+		models.RSVPMaybe:      {},
+		models.RSVPWaitlisted: {},
+	}
+	for _, rsvp := range rsvps {
+		attendees[rsvp.Status] = append(attendees[rsvp.Status], rsvp)
+	}
+	return attendees, nil
+}
+
+// WriteGroupCalendar writes the group's upcoming events as an iCalendar feed.
+func (s *EventService) WriteGroupCalendar(ctx context.Context, w io.Writer, groupID, viewerID uint) error {
+	group, err := getVisibleGroup(ctx, s.deps, groupID, viewerID)
+	if err != nil {
+		return err
+	}
+	if err := requireGroupContentAccess(ctx, s.deps, group, viewerID); err != nil {
+		return err
+	}
+
+	events, err := s.deps.Repos.Event.ListByGroup(ctx, groupID, time.Now().AddDate(0, -1, 0), time.Time{}, 1, calendarFeedLimit)
+	if err != nil {
+		return err
+	}
+	return s.calendar(group.Name, events).Encode(w)
+}
+
+// WriteUserCalendar writes the events the user is attending or considering.
+// Events the user is only considering or waitlisted for are tentative.
+func (s *EventService) WriteUserCalendar(ctx context.Context, w io.Writer, userID uint) error {
+	events, err := s.deps.Repos.Event.ListForUser(ctx, userID, time.Now().AddDate(0, -1, 0), calendarFeedLimit)
+	if err != nil {
+		return err
+	}
+	return s.calendar("SkillFlow events", events).Encode(w)
+}
+
+// RotateCalendarToken issues a new secret the user's calendar clients can
+// subscribe to their feeds with, replacing any previous one.
+func (s *EventService) RotateCalendarToken(ctx context.Context, userID uint) (string, error) {
+	settings, err := s.deps.Repos.UserSettings.GetByUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	token, err := generateRandomString(calendarTokenBytes)
+	if err != nil {
+		return "", err
+	}
+	settings.CalendarToken = &token
+	if err := s.deps.Repos.UserSettings.Save(ctx, settings); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeCalendarToken stops the user's calendar feeds from being served
+// without an access token.
+func (s *EventService) RevokeCalendarToken(ctx context.Context, userID uint) error {
+	settings, err := s.deps.Repos.UserSettings.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if settings.CalendarToken == nil {
+		return nil
+	}
+	settings.CalendarToken = nil
+	return s.deps.Repos.UserSettings.Save(ctx, settings)
+}
+
+// CalendarTokenUser returns the ID of the user a calendar feed token was
+// issued to.
+func (s *EventService) CalendarTokenUser(ctx context.Context, token string) (uint, error) {
+	if token == "" {
+		return 0, ErrCalendarToken
+	}
+	settings, err := s.deps.Repos.UserSettings.GetByCalendarToken(ctx, token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrCalendarToken
+	}
+	if err != nil {
+		return 0, err
+	}
+	return settings.UserID, nil
+}
+
+// SendReminders notifies going and maybe attendees of events starting within
+// eventReminderLead. Each event is reminded once, by whichever replica
+// claims it first.
+func (s *EventService) SendReminders(ctx context.Context) error {
+	now := time.Now()
+	events, err := s.deps.Repos.Event.ListNeedingReminder(ctx, now.Add(eventReminderLead))
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		claimed, err := s.deps.Repos.Event.ClaimReminder(ctx, event.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		attendees, err := s.deps.Repos.Event.ListAttendeeIDs(ctx, event.ID, models.RSVPGoing, models.RSVPMaybe)
+		if err != nil {
+			return err
+		}
+
+		notify(ctx, s.deps, Notice{
+			Title:   "Upcoming event",
+			Message: fmt.Sprintf("%s starts at %s", event.Title, formatEventTime(&event)),
+			Link:    fmt.Sprintf("/events/%d", event.ID),
+			Data:    models.EventReminderData{GroupID: event.GroupID, EventID: event.ID},
+		}, attendees...)
+	}
+
+	return nil
+}
+
+// RunReminders calls SendReminders every interval until ctx is cancelled.
+func (s *EventService) RunReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SendReminders(ctx); err != nil {
+				s.deps.Logger.Error("Failed to send event reminders", "error", err)
+			}
+		}
+	}
+}
+
+func (s *EventService) calendar(name string, events []models.GroupEvent) *ical.Calendar {
+	cal := &ical.Calendar{
+		ProdID: "-//SkillFlow//Events//EN",
+		Name:   name,
+		Events: make([]ical.Event, 0, len(events)),
+	}
+	for _, e := range events {
+		location := e.Location
+		if location == "" {
+			location = e.MeetingURL
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:          fmt.Sprintf("event-%d@%s", e.ID, s.deps.Config.Server.Domain),
+			Summary:      e.Title,
+			Description:  e.Description,
+			Location:     location,
+			URL:          e.MeetingURL,
+			Status:       calendarStatus(e),
+			Start:        e.StartsAt,
+			End:          e.EndsAt,
+			Created:      e.CreatedAt,
+			LastModified: e.UpdatedAt,
+		})
+	}
+	return cal
+}
+
+// calendarStatus is the iCalendar status of an event: tentative when its
+// loaded RSVPs, if any, are not going.
+func calendarStatus(event models.GroupEvent) string {
+	for _, rsvp := range event.RSVPs {
+		if rsvp.Status != models.RSVPGoing {
+			return "TENTATIVE"
+		}
+	}
+	return "CONFIRMED"
+}
+
+func (s *EventService) notifyPromoted(ctx context.Context, event *models.GroupEvent, userIDs []uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	notify(ctx, s.deps, Notice{
+		Title:   "You're going!",
+		Message: fmt.Sprintf("A spot opened up for %s", event.Title),
+		Link:    fmt.Sprintf("/events/%d", event.ID),
+		Data:    models.EventPromotedData{GroupID: event.GroupID, EventID: event.ID},
+	}, userIDs...)
+}
+
+func (s *EventService) getEvent(ctx context.Context, id uint) (*models.GroupEvent, error) {
+	event, err := s.deps.Repos.Event.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEventNotFound
+	}
+	return event, err
+}
+
+// requireEventAccess hides events of groups whose content the viewer cannot
+// see. Secret groups report their events as not found.
+func (s *EventService) requireEventAccess(ctx context.Context, event *models.GroupEvent, viewerID uint) error {
+	group, err := getVisibleGroup(ctx, s.deps, event.GroupID, viewerID)
+	if errors.Is(err, ErrGroupNotFound) {
+		return ErrEventNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return requireGroupContentAccess(ctx, s.deps, group, viewerID)
+}
+
+// requireOrganizer allows the event creator, or group moderators and above.
+func (s *EventService) requireOrganizer(ctx context.Context, event *models.GroupEvent, userID uint) error {
+	if event.CreatorID == userID {
+		return nil
+	}
+	_, err := requireGroupRole(ctx, s.deps, event.GroupID, userID, models.GroupRoleModerator)
+	return err
+}
+
+func validateEvent(event *models.GroupEvent) error {
+	if !event.EndsAt.After(event.StartsAt) {
+		return ErrEventTime
+	}
+	if event.Timezone == "" {
+		event.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(event.Timezone); err != nil {
+		return ErrEventTimezone
+	}
+	return nil
+}
+
+// formatEventTime renders the start time in the event's own timezone.
+func formatEventTime(event *models.GroupEvent) string {
+	loc, err := time.LoadLocation(event.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return event.StartsAt.In(loc).Format("Mon Jan 2 15:04 MST")
+}
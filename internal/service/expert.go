@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+	"gorm.io/gorm"
+)
+
+// maxExpertSkills bounds the skills one expert search may ask for.
+const maxExpertSkills = 10
+
+// relatedSkillWeight scales the score of a match through a descendant of the
+// requested skill, e.g. Helm for Kubernetes.
+const relatedSkillWeight = 0.75
+
+// verifiedSkillBonus is added to the score of a match whose level an
+// assessor verified.
+const verifiedSkillBonus = 5
+
+// FindExpertsInput describes an expert search. Skills are given by name or
+// synonym, by ID, or both. With Match "all" (the default) people need every
+// skill; with "any" at least one. VerifiedOnly leaves out skills without a
+// valid verification.
+type FindExpertsInput struct {
+	ViewerID        uint
+	Skills          []string
+	SkillIDs        []uint
+	Match           string
+	MinLevel        string
+	VerifiedOnly    bool
+	MinYears        int
+	MinEndorsements int
+	Department      string
+	Location        string
+	Page            int
+	Limit           int
+}
+
+// Expert is a person found by the expert finder, with the skills that
+// matched and a readable summary of why.
+type Expert struct {
+	User    *models.User  `json:"user"`
+	Score   float64       `json:"score"`
+	Matches []ExpertSkill `json:"matches"`
+	Reasons []string      `json:"reasons"`
+}
+
+// ExpertSkill is the best user skill matching one requested skill. The
+// matched skill differs from the requested one when the person has a
+// descendant of it. Level is self-declared; VerifiedLevel is set while a
+// verification is valid. Endorsements counts the visible endorsements and
+// EndorsementWeight weighs them by the endorsers' own levels.
+type ExpertSkill struct {
+	SkillID           uint    `json:"skill_id"`
+	Skill             string  `json:"skill"`
+	MatchedSkillID    uint    `json:"matched_skill_id"`
+	MatchedSkill      string  `json:"matched_skill"`
+	UserSkillID       uint    `json:"user_skill_id"`
+	Level             string  `json:"level"`
+	VerifiedLevel     string  `json:"verified_level,omitempty"`
+	Verification      string  `json:"verification"`
+	YearsOfExp        int     `json:"years_of_experience"`
+	Endorsements      int     `json:"endorsements"`
+	EndorsementWeight float64 `json:"endorsement_weight"`
+}
+
+// FindExperts searches people by skills, level, experience, endorsements,
+// department and location. A requested skill is also satisfied by its
+// descendants in the taxonomy, at a lower score. People rank by the sum of
+// their best match per requested skill, which grows with level, years of
+// experience and endorsements weighted by the endorsers' levels. A verified
+// level counts instead of the self-declared one, with a bonus.
+func (s *SkillService) FindExperts(ctx context.Context, input FindExpertsInput) ([]Expert, error) {
+	if input.Page < 1 || input.Limit < 1 {
+		return nil, fmt.Errorf("%w: page and limit must be positive", ErrInvalidInput)
+	}
+	matchAll := true
+	switch input.Match {
+	case "", "all":
+	case "any":
+		matchAll = false
+	default:
+		return nil, fmt.Errorf("%w: match must be all or any", ErrInvalidInput)
+	}
+	if input.MinLevel != "" && models.SkillLevelRank(input.MinLevel) == 0 {
+		return nil, fmt.Errorf("%w: min_level must be one of %s", ErrInvalidInput, strings.Join(models.SkillLevels, ", "))
+	}
+	requested, err := s.requestedSkills(ctx, input.Skills, input.SkillIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(requested))
+	for id := range requested {
+		ids = append(ids, id)
+	}
+	minMatches := 1
+	if matchAll {
+		minMatches = len(requested)
+	}
+	rows, err := s.deps.Repos.UserSkill.SearchExperts(ctx, repository.ExpertFilter{
+		SkillIDs:        ids,
+		MinLevel:        input.MinLevel,
+		VerifiedOnly:    input.VerifiedOnly,
+		MinYears:        input.MinYears,
+		MinEndorsements: input.MinEndorsements,
+		Department:      strings.TrimSpace(input.Department),
+		Location:        strings.TrimSpace(input.Location),
+		ViewerID:        input.ViewerID,
+		MinMatches:      minMatches,
+		VerifiedBonus:   verifiedSkillBonus,
+		RelatedWeight:   relatedSkillWeight,
+	}, input.Page, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows come grouped by person, best person first.
+	experts := make([]Expert, 0, input.Limit)
+	for _, row := range rows {
+		if len(experts) == 0 || experts[len(experts)-1].User.ID != row.UserID {
+			experts = append(experts, Expert{User: &models.User{ID: row.UserID}})
+		}
+		expert := &experts[len(experts)-1]
+		expert.Score += row.Score
+		match := ExpertSkill{
+			SkillID:           row.RequestedSkillID,
+			Skill:             requested[row.RequestedSkillID].Name,
+			MatchedSkillID:    row.SkillID,
+			MatchedSkill:      row.SkillName,
+			UserSkillID:       row.UserSkillID,
+			Level:             row.Level,
+			Verification:      models.VerificationSelfDeclared,
+			YearsOfExp:        row.YearsOfExp,
+			Endorsements:      row.Endorsements,
+			EndorsementWeight: row.EndorsementWeight,
+		}
+		switch {
+		case row.Verified:
+			match.VerifiedLevel, match.Verification = row.VerifiedLevel, models.VerificationVerified
+		case row.VerifiedLevel != "":
+			match.Verification = models.VerificationExpired
+		}
+		expert.Matches = append(expert.Matches, match)
+	}
+	for i := range experts {
+		sort.Slice(experts[i].Matches, func(a, b int) bool {
+			return experts[i].Matches[a].Skill < experts[i].Matches[b].Skill
+		})
+	}
+
+	userIDs := make([]uint, len(experts))
+	for i, expert := range experts {
+		userIDs[i] = expert.User.ID
+	}
+	users, err := s.deps.Repos.User.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+	for i := range experts {
+		if user, ok := byID[experts[i].User.ID]; ok {
+			experts[i].User = user
+		}
+		experts[i].Reasons = expertReasons(&experts[i], input)
+	}
+	return experts, nil
+}
+
+// requestedSkills resolves skill names and IDs, keyed by skill ID.
+func (s *SkillService) requestedSkills(ctx context.Context, names []string, ids []uint) (map[uint]*models.Skill, error) {
+	requested := make(map[uint]*models.Skill)
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		skill, err := s.deps.Repos.Skill.Resolve(ctx, name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrSkillNotFound, strings.TrimSpace(name))
+		}
+		if err != nil {
+			return nil, err
+		}
+		requested[skill.ID] = skill
+	}
+	if len(ids) > 0 {
+		skills, err := s.deps.Repos.Skill.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range skills {
+			requested[skills[i].ID] = &skills[i]
+		}
+		for _, id := range ids {
+			if requested[id] == nil {
+				return nil, fmt.Errorf("%w: %d", ErrSkillNotFound, id)
+			}
+		}
+	}
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: at least one skill is required", ErrInvalidInput)
+	}
+	if len(requested) > maxExpertSkills {
+		return nil, fmt.Errorf("%w: at most %d skills can be searched at once", ErrInvalidInput, maxExpertSkills)
+	}
+	return requested, nil
+}
+
+// expertReasons explains a match, e.g. "Kubernetes (via Helm): advanced
+// (verified), 5 years, 3 endorsements".
+func expertReasons(expert *Expert, input FindExpertsInput) []string {
+	var reasons []string
+	for _, m := range expert.Matches {
+		skill := m.Skill
+		if m.MatchedSkillID != m.SkillID {
+			skill += " (via " + m.MatchedSkill + ")"
+		}
+		level := m.Level + " (self-declared)"
+		if m.Verification == models.VerificationVerified {
+			level = m.VerifiedLevel + " (verified)"
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %s, %s, %s", skill, level,
+			plural(m.YearsOfExp, "year"), plural(m.Endorsements, "endorsement")))
+	}
+	if profile := expert.User.Profile; profile != nil {
+		if input.Department != "" {
+			reasons = append(reasons, "department: "+profile.Department)
+		}
+		if input.Location != "" {
+			reasons = append(reasons, "location: "+profile.Location)
+		}
+	}
+	return reasons
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
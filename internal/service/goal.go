@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// goalReminderLead is how long before a learning goal falls due its owner is
+// reminded.
+const goalReminderLead = 7 * 24 * time.Hour
+
+var (
+	ErrGoalNotFound = fmt.Errorf("%w: learning goal not found", ErrNotFound)
+	ErrGoalExists   = fmt.Errorf("%w: you already have an active goal for this skill", ErrConflict)
+	ErrGoalClosed   = fmt.Errorf("%w: goal is no longer active", ErrConflict)
+	ErrGoalStatus   = fmt.Errorf("%w: status must be active, completed or abandoned", ErrInvalidInput)
+)
+
+type GoalService struct {
+	deps ServicesDeps
+}
+
+func NewGoalService(deps ServicesDeps) *GoalService {
+	return &GoalService{deps: deps}
+}
+
+type CreateGoalInput struct {
+	UserID      uint      `json:"-"`
+	SkillID     uint      `json:"skill_id" binding:"required"`
+	TargetLevel string    `json:"target_level" binding:"required,oneof=beginner intermediate advanced expert"`
+	DueDate     time.Time `json:"due_date" binding:"required"`
+	Notes       string    `json:"notes" binding:"max=2000"`
+	Shared      bool      `json:"shared"`
+	MentorID    *uint     `json:"mentor_id"`
+}
+
+// UpdateGoalInput changes a goal; omitted fields are unchanged and a
+// mentor_id of 0 removes the mentor.
+type UpdateGoalInput struct {
+	TargetLevel string     `json:"target_level" binding:"omitempty,oneof=beginner intermediate advanced expert"`
+	DueDate     *time.Time `json:"due_date"`
+	Notes       *string    `json:"notes" binding:"omitempty,max=2000"`
+	Shared      *bool      `json:"shared"`
+	MentorID    *uint      `json:"mentor_id"`
+	Status      string     `json:"status" binding:"omitempty,oneof=active completed abandoned"`
+}
+
+// GoalCheckInInput reports progress on a goal. Only the owner sets
+// progress; mentors leave notes.
+type GoalCheckInInput struct {
+	Progress *int   `json:"progress" binding:"omitempty,min=0,max=100"`
+	Note     string `json:"note" binding:"max=2000"`
+}
+
+// Create adds a learning goal for a catalog skill. The due date must be in
+// the future, the target above the user's current level of the skill, and
+// each skill can have one active goal. An assigned mentor is notified.
+func (s *GoalService) Create(ctx context.Context, input CreateGoalInput) (*models.LearningGoal, error) {
+	skill, err := s.deps.Repos.Skill.GetByID(ctx, input.SkillID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSkillNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !input.DueDate.After(time.Now()) {
+		return nil, fmt.Errorf("%w: due_date must be in the future", ErrInvalidInput)
+	}
+	userSkill, err := s.deps.Repos.UserSkill.GetByUserAndSkill(ctx, input.UserID, input.SkillID)
+	if err == nil && models.SkillLevelRank(userSkill.Level) >= models.SkillLevelRank(input.TargetLevel) {
+		return nil, fmt.Errorf("%w: you are already %s in %s", ErrInvalidInput, userSkill.Level, skill.Name)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	exists, err := s.deps.Repos.Goal.ExistsActive(ctx, input.UserID, input.SkillID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrGoalExists
+	}
+
+	goal := &models.LearningGoal{
+		UserID:      input.UserID,
+		SkillID:     input.SkillID,
+		TargetLevel: input.TargetLevel,
+		DueDate:     input.DueDate,
+		Notes:       strings.TrimSpace(input.Notes),
+		Status:      models.GoalStatusActive,
+		Shared:      input.Shared,
+	}
+	if input.MentorID != nil && *input.MentorID != 0 {
+		if err := s.checkMentor(ctx, input.UserID, *input.MentorID); err != nil {
+			return nil, err
+		}
+		goal.MentorID = input.MentorID
+	}
+	if err := s.deps.Repos.Goal.Create(ctx, goal); err != nil {
+		return nil, err
+	}
+	goal.Skill = skill
+	if goal.MentorID != nil {
+		s.notifyMentor(ctx, goal)
+	}
+	return goal, nil
+}
+
+// Get returns a goal with its check-ins to its owner, its mentor, or, when
+// shared, the owner's manager.
+func (s *GoalService) Get(ctx context.Context, id, viewerID uint) (*models.LearningGoal, error) {
+	goal, err := s.getGoal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canViewGoal(goal, viewerID) {
+		return nil, ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+// List returns the user's own goals, soonest due first.
+func (s *GoalService) List(ctx context.Context, userID uint, status string, page, limit int) ([]models.LearningGoal, error) {
+	if err := checkGoalStatus(status); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Goal.ListByUser(ctx, userID, status, page, limit)
+}
+
+// ListMentoring returns the goals the user mentors.
+func (s *GoalService) ListMentoring(ctx context.Context, mentorID uint, status string, page, limit int) ([]models.LearningGoal, error) {
+	if err := checkGoalStatus(status); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Goal.ListByMentor(ctx, mentorID, status, page, limit)
+}
+
+// ListReports returns the shared goals of the people who name the user as
+// their manager.
+func (s *GoalService) ListReports(ctx context.Context, managerID uint, status string, page, limit int) ([]models.LearningGoal, error) {
+	if err := checkGoalStatus(status); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Goal.ListSharedWithManager(ctx, managerID, status, page, limit)
+}
+
+// Update changes one of the user's goals. Moving the due date re-arms the
+// deadline reminder; completing a goal sets its progress to 100.
+func (s *GoalService) Update(ctx context.Context, id, userID uint, input UpdateGoalInput) (*models.LearningGoal, error) {
+	goal, err := s.getOwnGoal(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.TargetLevel != "" {
+		goal.TargetLevel = input.TargetLevel
+	}
+	if input.DueDate != nil && !input.DueDate.Equal(goal.DueDate) {
+		if !input.DueDate.After(time.Now()) {
+			return nil, fmt.Errorf("%w: due_date must be in the future", ErrInvalidInput)
+		}
+		goal.DueDate = *input.DueDate
+		goal.ReminderSentAt = nil
+	}
+	if input.Notes != nil {
+		goal.Notes = strings.TrimSpace(*input.Notes)
+	}
+	if input.Shared != nil {
+		goal.Shared = *input.Shared
+	}
+	mentorAssigned := false
+	if input.MentorID != nil {
+		switch {
+		case *input.MentorID == 0:
+			goal.MentorID, goal.Mentor = nil, nil
+		case goal.MentorID == nil || *goal.MentorID != *input.MentorID:
+			if err := s.checkMentor(ctx, userID, *input.MentorID); err != nil {
+				return nil, err
+			}
+			goal.MentorID, goal.Mentor = input.MentorID, nil
+			mentorAssigned = true
+		}
+	}
+	if input.Status != "" && input.Status != goal.Status {
+		goal.Status = input.Status
+		goal.CompletedAt = nil
+		if input.Status == models.GoalStatusCompleted {
+			now := time.Now()
+			goal.CompletedAt = &now
+			goal.Progress = 100
+		}
+	}
+
+	if err := s.deps.Repos.Goal.Update(ctx, goal); err != nil {
+		return nil, err
+	}
+	if mentorAssigned {
+		s.notifyMentor(ctx, goal)
+	}
+	return goal, nil
+}
+
+// Delete removes one of the user's goals with its check-ins.
+func (s *GoalService) Delete(ctx context.Context, id, userID uint) error {
+	goal, err := s.getOwnGoal(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	return s.deps.Repos.Goal.Delete(ctx, goal.ID)
+}
+
+// RemoveMentor lets the owner or the mentor end the mentor's assignment.
+func (s *GoalService) RemoveMentor(ctx context.Context, id, userID uint) error {
+	goal, err := s.getGoal(ctx, id)
+	if err != nil {
+		return err
+	}
+	isMentor := goal.MentorID != nil && *goal.MentorID == userID
+	if goal.UserID != userID && !isMentor {
+		if canViewGoal(goal, userID) {
+			return fmt.Errorf("%w: only the owner or mentor can remove the mentor", ErrForbidden)
+		}
+		return ErrGoalNotFound
+	}
+	if goal.MentorID == nil {
+		return nil
+	}
+	goal.MentorID, goal.Mentor = nil, nil
+	return s.deps.Repos.Goal.Update(ctx, goal)
+}
+
+// CheckIn adds a progress update to an active goal. The owner reports
+// progress, which completes the goal at 100; the mentor adds notes. The
+// other party is notified.
+func (s *GoalService) CheckIn(ctx context.Context, id, userID uint, input GoalCheckInInput) (*models.GoalCheckIn, error) {
+	goal, err := s.getGoal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	isOwner := goal.UserID == userID
+	isMentor := goal.MentorID != nil && *goal.MentorID == userID
+	if !isOwner && !isMentor {
+		if canViewGoal(goal, userID) {
+			return nil, fmt.Errorf("%w: only the owner or mentor can check in", ErrForbidden)
+		}
+		return nil, ErrGoalNotFound
+	}
+	if goal.Status != models.GoalStatusActive {
+		return nil, ErrGoalClosed
+	}
+	note := strings.TrimSpace(input.Note)
+	if input.Progress == nil && note == "" {
+		return nil, fmt.Errorf("%w: progress or note is required", ErrInvalidInput)
+	}
+	if input.Progress != nil && !isOwner {
+		return nil, fmt.Errorf("%w: only the goal's owner can report progress", ErrForbidden)
+	}
+
+	checkIn := &models.GoalCheckIn{GoalID: goal.ID, UserID: userID, Progress: input.Progress, Note: note}
+	if input.Progress != nil {
+		goal.Progress = *input.Progress
+		if goal.Progress == 100 {
+			now := time.Now()
+			goal.Status = models.GoalStatusCompleted
+			goal.CompletedAt = &now
+		}
+	}
+	if err := s.deps.Repos.Goal.AddCheckIn(ctx, goal, checkIn); err != nil {
+		return nil, err
+	}
+
+	recipient := goal.UserID
+	action := fmt.Sprintf("commented on your %s goal", goalSkillName(goal))
+	if isOwner {
+		if goal.MentorID == nil {
+			return checkIn, nil
+		}
+		recipient = *goal.MentorID
+		action = fmt.Sprintf("checked in on their %s goal", goalSkillName(goal))
+	}
+	notify(ctx, s.deps, Notice{
+		ActorID: userID,
+		Title:   "Goal check-in",
+		Action:  action,
+		Link:    fmt.Sprintf("/goals/%d", goal.ID),
+		Data:    models.GoalCheckInData{GoalID: goal.ID, CheckInID: checkIn.ID},
+	}, recipient)
+	return checkIn, nil
+}
+
+// SendReminders notifies the owners of active goals falling due within
+// goalReminderLead. Each goal is reminded once per due date, by whichever
+// replica claims it first.
+func (s *GoalService) SendReminders(ctx context.Context) error {
+	now := time.Now()
+	goals, err := s.deps.Repos.Goal.ListNeedingReminder(ctx, now.Add(goalReminderLead))
+	if err != nil {
+		return err
+	}
+
+	for _, goal := range goals {
+		claimed, err := s.deps.Repos.Goal.ClaimReminder(ctx, goal.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		notify(ctx, s.deps, Notice{
+			Title:   "Learning goal due soon",
+			Message: fmt.Sprintf("Your %s goal is due on %s (%d%% done)", goalSkillName(&goal), goal.DueDate.Format("Jan 2"), goal.Progress),
+			Link:    fmt.Sprintf("/goals/%d", goal.ID),
+			Data:    models.GoalDueData{GoalID: goal.ID, SkillID: goal.SkillID},
+		}, goal.UserID)
+	}
+	return nil
+}
+
+// RunReminders calls SendReminders every interval until ctx is cancelled.
+func (s *GoalService) RunReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SendReminders(ctx); err != nil {
+				s.deps.Logger.Error("Failed to send goal reminders", "error", err)
+			}
+		}
+	}
+}
+
+// checkMentor reports ErrNotFound for mentors who do not exist or are
+// blocked by or blocking the goal's owner.
+func (s *GoalService) checkMentor(ctx context.Context, userID, mentorID uint) error {
+	if mentorID == userID {
+		return fmt.Errorf("%w: you cannot mentor yourself", ErrInvalidInput)
+	}
+	mentor, err := s.deps.Repos.User.GetByID(ctx, mentorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !mentor.IsActive) {
+		return fmt.Errorf("%w: mentor not found", ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, userID, mentorID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return fmt.Errorf("%w: mentor not found", ErrNotFound)
+	}
+	return nil
+}
+
+func (s *GoalService) notifyMentor(ctx context.Context, goal *models.LearningGoal) {
+	notify(ctx, s.deps, Notice{
+		ActorID: goal.UserID,
+		Title:   "New mentee",
+		Action:  fmt.Sprintf("asked you to mentor their %s goal", goalSkillName(goal)),
+		Link:    fmt.Sprintf("/goals/%d", goal.ID),
+		Data:    models.GoalMentorAssignedData{GoalID: goal.ID, SkillID: goal.SkillID},
+	}, *goal.MentorID)
+}
+
+func (s *GoalService) getGoal(ctx context.Context, id uint) (*models.LearningGoal, error) {
+	goal, err := s.deps.Repos.Goal.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrGoalNotFound
+	}
+	return goal, err
+}
+
+func (s *GoalService) getOwnGoal(ctx context.Context, id, userID uint) (*models.LearningGoal, error) {
+	goal, err := s.getGoal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if goal.UserID != userID {
+		if canViewGoal(goal, userID) {
+			return nil, fmt.Errorf("%w: not your goal", ErrForbidden)
+		}
+		return nil, ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+// canViewGoal reports whether the viewer is the goal's owner or mentor, or
+// the owner's manager when the goal is shared.
+func canViewGoal(goal *models.LearningGoal, viewerID uint) bool {
+	switch {
+	case goal.UserID == viewerID:
+		return true
+	case goal.MentorID != nil && *goal.MentorID == viewerID:
+		return true
+	case goal.Shared && goal.User != nil && goal.User.Profile != nil:
+		managerID := goal.User.Profile.ManagerID
+		return managerID != nil && *managerID == viewerID
+	}
+	return false
+}
+
+func checkGoalStatus(status string) error {
+	switch status {
+	case "", models.GoalStatusActive, models.GoalStatusCompleted, models.GoalStatusAbandoned:
+		return nil
+	}
+	return ErrGoalStatus
+}
+
+func goalSkillName(goal *models.LearningGoal) string {
+	if goal.Skill != nil {
+		return goal.Skill.Name
+	}
+	return "learning"
+}
@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+	"gorm.io/gorm"
+)
+
+type GroupService struct {
+	deps ServicesDeps
+}
+
+func NewGroupService(deps ServicesDeps) *GroupService {
+	return &GroupService{deps: deps}
+}
+
+type CreateGroupInput struct {
+	UserID      uint   `json:"-"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+	AvatarURL   string `json:"avatar_url"`
+	CoverURL    string `json:"cover_url"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public private secret"`
+}
+
+type UpdateGroupInput struct {
+	Name        string `json:"name" binding:"omitempty,max=100"`
+	Description string `json:"description"`
+	AvatarURL   string `json:"avatar_url"`
+	CoverURL    string `json:"cover_url"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public private secret"`
+}
+
+type ListGroupsInput struct {
+	ViewerID uint
+	Query    string
+	Mine     bool
+	Page     int
+	Limit    int
+}
+
+var (
+	ErrGroupNotFound   = fmt.Errorf("%w: group not found", ErrNotFound)
+	ErrNotGroupMember  = fmt.Errorf("%w: not a member of this group", ErrForbidden)
+	ErrGroupRole       = fmt.Errorf("%w: insufficient group role", ErrForbidden)
+	ErrAlreadyMember   = fmt.Errorf("%w: already a member of this group", ErrConflict)
+	ErrBannedFromGroup = fmt.Errorf("%w: banned from this group", ErrForbidden)
+	ErrLastGroupOwner  = fmt.Errorf("%w: the last owner cannot leave the group; transfer ownership first", ErrConflict)
+	ErrGroupMemberRole = fmt.Errorf("%w: unknown group role", ErrInvalidInput)
+	ErrGroupInviteOnly = fmt.Errorf("%w: this group can only be joined by invitation", ErrForbidden)
+)
+
+// groupRoleRank orders roles so that a higher rank includes the permissions
+// of every lower one.
+var groupRoleRank = map[string]int{
+	models.GroupRoleMember:    1,
+	models.GroupRoleModerator: 2,
+	models.GroupRoleAdmin:     3,
+	models.GroupRoleOwner:     4,
+}
+
+func (s *GroupService) Create(ctx context.Context, input CreateGroupInput) (*models.Group, error) {
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = models.GroupVisibilityPublic
+	}
+
+	group := &models.Group{
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+		AvatarURL:   input.AvatarURL,
+		CoverURL:    input.CoverURL,
+		Visibility:  visibility,
+		CreatorID:   input.UserID,
+	}
+
+	if err := s.deps.Repos.Group.CreateWithOwner(ctx, group, input.UserID); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *GroupService) GetByID(ctx context.Context, id, viewerID uint) (*models.Group, error) {
+	return getVisibleGroup(ctx, s.deps, id, viewerID)
+}
+
+func (s *GroupService) List(ctx context.Context, input ListGroupsInput) ([]models.Group, error) {
+	filter := repository.GroupFilter{
+		Query:    strings.TrimSpace(input.Query),
+		ViewerID: input.ViewerID,
+	}
+	if input.Mine {
+		filter.MemberID = input.ViewerID
+	}
+	return s.deps.Repos.Group.List(ctx, filter, input.Page, input.Limit)
+}
+
+func (s *GroupService) Update(ctx context.Context, id, userID uint, input UpdateGroupInput) (*models.Group, error) {
+	group, err := getGroup(ctx, s.deps, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := requireGroupRole(ctx, s.deps, id, userID, models.GroupRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if input.Name != "" {
+		group.Name = strings.TrimSpace(input.Name)
+	}
+	if input.Description != "" {
+		group.Description = input.Description
+	}
+	if input.AvatarURL != "" {
+		group.AvatarURL = input.AvatarURL
+	}
+	if input.CoverURL != "" {
+		group.CoverURL = input.CoverURL
+	}
+	if input.Visibility != "" {
+		group.Visibility = input.Visibility
+	}
+
+	if err := s.deps.Repos.Group.Update(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *GroupService) Delete(ctx context.Context, id, userID uint) error {
+	if _, err := getGroup(ctx, s.deps, id); err != nil {
+		return err
+	}
+	if _, err := requireGroupRole(ctx, s.deps, id, userID, models.GroupRoleOwner); err != nil {
+		return err
+	}
+	return s.deps.Repos.Group.Delete(ctx, id)
+}
+
+// Join adds the user to a public group as a regular member. For private
+// groups a pending join request is created instead and returned; secret
+// groups can only be joined through an invitation.
+func (s *GroupService) Join(ctx context.Context, id, userID uint, message string) (*models.GroupJoinRequest, error) {
+	group, err := getVisibleGroup(ctx, s.deps, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch group.Visibility {
+	case models.GroupVisibilityPublic:
+		return nil, s.addMember(ctx, group.ID, userID)
+	case models.GroupVisibilityPrivate:
+		return s.requestToJoin(ctx, group, userID, message)
+	default:
+		return nil, ErrGroupInviteOnly
+	}
+}
+
+// Leave removes the user from the group. An owner may only leave while
+// another owner remains.
+func (s *GroupService) Leave(ctx context.Context, id, userID uint) error {
+	member, err := getGroupMember(ctx, s.deps, id, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrNotGroupMember
+	}
+
+	if member.Role == models.GroupRoleOwner {
+		owners, err := s.deps.Repos.GroupMember.CountByRole(ctx, id, models.GroupRoleOwner)
+		if err != nil {
+			return err
+		}
+		if owners <= 1 {
+			return ErrLastGroupOwner
+		}
+	}
+
+	return s.deps.Repos.GroupMember.Delete(ctx, id, userID)
+}
+
+// GetMembers lists members with their roles. Members of private groups are
+// only visible to other members.
+func (s *GroupService) GetMembers(ctx context.Context, id, viewerID uint, page, limit int) ([]models.GroupMember, error) {
+	group, err := getVisibleGroup(ctx, s.deps, id, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireGroupContentAccess(ctx, s.deps, group, viewerID); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.GroupMember.ListByGroup(ctx, id, page, limit)
+}
+
+// ChangeRole promotes or demotes a member. The actor must be at least an
+// admin, must outrank the target, and cannot grant a role equal to or above
+// their own. Ownership moves only through TransferOwnership.
+func (s *GroupService) ChangeRole(ctx context.Context, groupID, actorID, targetID uint, role string) (*models.GroupMember, error) {
+	if _, ok := groupRoleRank[role]; !ok || role == models.GroupRoleOwner {
+		return nil, ErrGroupMemberRole
+	}
+
+	actor, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.outrankedMember(ctx, actor, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if groupRoleRank[role] >= groupRoleRank[actor.Role] {
+		return nil, ErrGroupRole
+	}
+
+	if err := s.deps.Repos.GroupMember.UpdateRole(ctx, groupID, targetID, role); err != nil {
+		return nil, err
+	}
+	target.Role = role
+
+	return target, nil
+}
+
+// Kick removes a member who may rejoin later. Moderators can kick members,
+// admins can kick moderators, owners can kick admins.
+func (s *GroupService) Kick(ctx context.Context, groupID, actorID, targetID uint) error {
+	actor, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleModerator)
+	if err != nil {
+		return err
+	}
+	if _, err := s.outrankedMember(ctx, actor, targetID); err != nil {
+		return err
+	}
+	return s.deps.Repos.GroupMember.Delete(ctx, groupID, targetID)
+}
+
+// Ban removes the user from the group, if they are a member, and prevents
+// them from joining again.
+func (s *GroupService) Ban(ctx context.Context, groupID, actorID, targetID uint, reason string) error {
+	actor, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	target, err := getGroupMember(ctx, s.deps, groupID, targetID)
+	if err != nil {
+		return err
+	}
+	if target != nil && groupRoleRank[target.Role] >= groupRoleRank[actor.Role] {
+		return ErrGroupRole
+	}
+
+	banned, err := s.deps.Repos.GroupMember.IsBanned(ctx, groupID, targetID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return nil
+	}
+
+	return s.deps.Repos.GroupMember.Ban(ctx, &models.GroupBan{
+		GroupID:    groupID,
+		UserID:     targetID,
+		BannedByID: actorID,
+		Reason:     reason,
+	})
+}
+
+func (s *GroupService) Unban(ctx context.Context, groupID, actorID, targetID uint) error {
+	if _, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleAdmin); err != nil {
+		return err
+	}
+	return s.deps.Repos.GroupMember.Unban(ctx, groupID, targetID)
+}
+
+// TransferOwnership hands ownership to another member; the previous owner
+// stays in the group as an admin.
+func (s *GroupService) TransferOwnership(ctx context.Context, groupID, ownerID, newOwnerID uint) error {
+	if ownerID == newOwnerID {
+		return fmt.Errorf("%w: already the owner", ErrInvalidInput)
+	}
+	if _, err := requireGroupRole(ctx, s.deps, groupID, ownerID, models.GroupRoleOwner); err != nil {
+		return err
+	}
+
+	target, err := getGroupMember(ctx, s.deps, groupID, newOwnerID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return fmt.Errorf("%w: new owner must be a member of the group", ErrInvalidInput)
+	}
+
+	return s.deps.Repos.GroupMember.TransferOwnership(ctx, groupID, ownerID, newOwnerID)
+}
+
+func (s *GroupService) addMember(ctx context.Context, groupID, userID uint) error {
+	if err := s.checkCanJoin(ctx, groupID, userID); err != nil {
+		return err
+	}
+
+	return s.deps.Repos.GroupMember.Create(ctx, &models.GroupMember{
+		GroupID:  groupID,
+		UserID:   userID,
+		Role:     models.GroupRoleMember,
+		JoinedAt: time.Now(),
+	})
+}
+
+// checkCanJoin rejects users who are banned from or already in the group.
+func (s *GroupService) checkCanJoin(ctx context.Context, groupID, userID uint) error {
+	banned, err := s.deps.Repos.GroupMember.IsBanned(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrBannedFromGroup
+	}
+
+	member, err := getGroupMember(ctx, s.deps, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if member != nil {
+		return ErrAlreadyMember
+	}
+	return nil
+}
+
+// outrankedMember loads the target membership and checks that the actor
+// holds a strictly higher role.
+func (s *GroupService) outrankedMember(ctx context.Context, actor *models.GroupMember, targetID uint) (*models.GroupMember, error) {
+	target, err := getGroupMember(ctx, s.deps, actor.GroupID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: user is not a member of this group", ErrNotFound)
+	}
+	if groupRoleRank[target.Role] >= groupRoleRank[actor.Role] {
+		return nil, ErrGroupRole
+	}
+	return target, nil
+}
+
+func getGroup(ctx context.Context, deps ServicesDeps, id uint) (*models.Group, error) {
+	group, err := deps.Repos.Group.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrGroupNotFound
+	}
+	return group, err
+}
+
+// getVisibleGroup loads a group the viewer is allowed to know about. Secret
+// groups are reported as not found to non-members.
+func getVisibleGroup(ctx context.Context, deps ServicesDeps, id, viewerID uint) (*models.Group, error) {
+	group, err := getGroup(ctx, deps, id)
+	if err != nil {
+		return nil, err
+	}
+	if group.Visibility != models.GroupVisibilitySecret {
+		return group, nil
+	}
+
+	member, err := getGroupMember(ctx, deps, id, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+// getGroupMember returns nil without an error when the user is not a member.
+func getGroupMember(ctx context.Context, deps ServicesDeps, groupID, userID uint) (*models.GroupMember, error) {
+	member, err := deps.Repos.GroupMember.GetByGroupAndUser(ctx, groupID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// requireGroupRole returns the user's membership if their role is at least
+// minRole.
+func requireGroupRole(ctx context.Context, deps ServicesDeps, groupID, userID uint, minRole string) (*models.GroupMember, error) {
+	member, err := getGroupMember(ctx, deps, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotGroupMember
+	}
+	if groupRoleRank[member.Role] < groupRoleRank[minRole] {
+		return nil, ErrGroupRole
+	}
+	return member, nil
+}
+
+// requireGroupContentAccess checks whether the viewer may see the group's
+// posts and member list.
+func requireGroupContentAccess(ctx context.Context, deps ServicesDeps, group *models.Group, viewerID uint) error {
+	if group.Visibility == models.GroupVisibilityPublic {
+		return nil
+	}
+	_, err := requireGroupRole(ctx, deps, group.ID, viewerID, models.GroupRoleMember)
+	return err
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+	"gorm.io/gorm"
+)
+
+type CreateInviteLinkInput struct {
+	MaxUses      int `json:"max_uses" binding:"min=0"`
+	ExpiresInHrs int `json:"expires_in_hours" binding:"min=0"`
+}
+
+var (
+	ErrJoinRequestPending = fmt.Errorf("%w: a join request is already pending", ErrConflict)
+	ErrJoinRequestHandled = fmt.Errorf("%w: join request was already handled", ErrConflict)
+	ErrInvitationPending  = fmt.Errorf("%w: user already has a pending invitation", ErrConflict)
+	ErrInvitationHandled  = fmt.Errorf("%w: invitation was already answered", ErrConflict)
+	ErrInviteLinkInvalid  = fmt.Errorf("%w: invite link is invalid or expired", ErrNotFound)
+)
+
+// inviteTokenBytes is the amount of randomness behind an invite link token.
+const inviteTokenBytes = 24
+
+func (s *GroupService) requestToJoin(ctx context.Context, group *models.Group, userID uint, message string) (*models.GroupJoinRequest, error) {
+	if err := s.checkCanJoin(ctx, group.ID, userID); err != nil {
+		return nil, err
+	}
+
+	_, err := s.deps.Repos.GroupInvite.GetPendingJoinRequest(ctx, group.ID, userID)
+	if err == nil {
+		return nil, ErrJoinRequestPending
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	req := &models.GroupJoinRequest{
+		GroupID: group.ID,
+		UserID:  userID,
+		Message: message,
+		Status:  models.InviteStatusPending,
+	}
+	if err := s.deps.Repos.GroupInvite.CreateJoinRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	admins, err := s.deps.Repos.GroupMember.ListUserIDsByRoles(ctx, group.ID, models.GroupRoleOwner, models.GroupRoleAdmin)
+	if err != nil {
+		s.deps.Logger.Error("Failed to list group admins", "group_id", group.ID, "error", err)
+	}
+	notify(ctx, s.deps, Notice{
+		ActorID: userID,
+		Title:   "New join request",
+		Action:  fmt.Sprintf("asked to join %s", group.Name),
+		Link:    fmt.Sprintf("/groups/%d/requests", group.ID),
+		Data:    models.GroupJoinRequestData{GroupID: group.ID, RequestID: req.ID},
+	}, admins...)
+
+	return req, nil
+}
+
+// ListJoinRequests returns pending join requests to group admins.
+func (s *GroupService) ListJoinRequests(ctx context.Context, groupID, actorID uint, page, limit int) ([]models.GroupJoinRequest, error) {
+	if _, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.GroupInvite.ListJoinRequests(ctx, groupID, models.InviteStatusPending, page, limit)
+}
+
+func (s *GroupService) ApproveJoinRequest(ctx context.Context, groupID, requestID, actorID uint) error {
+	return s.reviewJoinRequest(ctx, groupID, requestID, actorID, true)
+}
+
+func (s *GroupService) DeclineJoinRequest(ctx context.Context, groupID, requestID, actorID uint) error {
+	return s.reviewJoinRequest(ctx, groupID, requestID, actorID, false)
+}
+
+func (s *GroupService) reviewJoinRequest(ctx context.Context, groupID, requestID, actorID uint, approve bool) error {
+	group, err := getGroup(ctx, s.deps, groupID)
+	if err != nil {
+		return err
+	}
+	if _, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleAdmin); err != nil {
+		return err
+	}
+
+	req, err := s.deps.Repos.GroupInvite.GetJoinRequest(ctx, requestID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && req.GroupID != groupID) {
+		return fmt.Errorf("%w: join request not found", ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if req.Status != models.InviteStatusPending {
+		return ErrJoinRequestHandled
+	}
+
+	n := Notice{
+		ActorID: actorID,
+		Link:    fmt.Sprintf("/groups/%d", group.ID),
+		Data:    models.GroupJoinDecisionData{GroupID: group.ID, RequestID: req.ID, Approved: approve},
+	}
+	if approve {
+		if err := s.checkCanJoin(ctx, groupID, req.UserID); err != nil {
+			return err
+		}
+		if err := s.deps.Repos.GroupInvite.ApproveJoinRequest(ctx, req, actorID); err != nil {
+			return joinRequestError(err)
+		}
+		n.Title = "Join request approved"
+		n.Message = fmt.Sprintf("You are now a member of %s", group.Name)
+	} else {
+		if err := s.deps.Repos.GroupInvite.DeclineJoinRequest(ctx, req, actorID); err != nil {
+			return joinRequestError(err)
+		}
+		n.Title = "Join request declined"
+		n.Message = fmt.Sprintf("Your request to join %s was declined", group.Name)
+	}
+
+	notify(ctx, s.deps, n, req.UserID)
+	return nil
+}
+
+// InviteUser sends a direct invitation. Invitations bypass join requests and
+// are the only way into secret groups.
+func (s *GroupService) InviteUser(ctx context.Context, groupID, inviterID, inviteeID uint) (*models.GroupInvitation, error) {
+	group, err := getGroup(ctx, s.deps, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := requireGroupRole(ctx, s.deps, groupID, inviterID, models.GroupRoleAdmin); err != nil {
+		return nil, err
+	}
+	if _, err := s.deps.Repos.User.GetByID(ctx, inviteeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user not found", ErrNotFound)
+		}
+		return nil, err
+	}
+	if err := s.checkCanJoin(ctx, groupID, inviteeID); err != nil {
+		return nil, err
+	}
+
+	_, err = s.deps.Repos.GroupInvite.GetPendingInvitation(ctx, groupID, inviteeID)
+	if err == nil {
+		return nil, ErrInvitationPending
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	inv := &models.GroupInvitation{
+		GroupID:   groupID,
+		InviterID: inviterID,
+		InviteeID: inviteeID,
+		Status:    models.InviteStatusPending,
+	}
+	if err := s.deps.Repos.GroupInvite.CreateInvitation(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	notify(ctx, s.deps, Notice{
+		ActorID: inviterID,
+		Title:   "Group invitation",
+		Message: fmt.Sprintf("You were invited to join %s", group.Name),
+		Link:    "/groups/invitations",
+		Data:    models.GroupInvitationData{GroupID: group.ID, InvitationID: inv.ID},
+	}, inviteeID)
+
+	return inv, nil
+}
+
+func (s *GroupService) ListInvitations(ctx context.Context, userID uint) ([]models.GroupInvitation, error) {
+	return s.deps.Repos.GroupInvite.ListInvitationsForUser(ctx, userID, models.InviteStatusPending)
+}
+
+func (s *GroupService) AcceptInvitation(ctx context.Context, invitationID, userID uint) error {
+	return s.answerInvitation(ctx, invitationID, userID, true)
+}
+
+func (s *GroupService) DeclineInvitation(ctx context.Context, invitationID, userID uint) error {
+	return s.answerInvitation(ctx, invitationID, userID, false)
+}
+
+func (s *GroupService) answerInvitation(ctx context.Context, invitationID, userID uint, accept bool) error {
+	inv, err := s.deps.Repos.GroupInvite.GetInvitation(ctx, invitationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && inv.InviteeID != userID) {
+		return fmt.Errorf("%w: invitation not found", ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if inv.Status != models.InviteStatusPending {
+		return ErrInvitationHandled
+	}
+
+	group, err := getGroup(ctx, s.deps, inv.GroupID)
+	if err != nil {
+		return err
+	}
+
+	verb := "declined"
+	if accept {
+		if err := s.checkCanJoin(ctx, inv.GroupID, userID); err != nil {
+			return err
+		}
+		if err := s.deps.Repos.GroupInvite.AcceptInvitation(ctx, inv); err != nil {
+			return invitationError(err)
+		}
+		verb = "accepted"
+	} else if err := s.deps.Repos.GroupInvite.DeclineInvitation(ctx, inv); err != nil {
+		return invitationError(err)
+	}
+
+	notify(ctx, s.deps, Notice{
+		ActorID: userID,
+		Title:   "Invitation " + verb,
+		Message: fmt.Sprintf("Your invitation to %s was %s", group.Name, verb),
+		Link:    fmt.Sprintf("/groups/%d", group.ID),
+		Data:    models.GroupInvitationAnsweredData{GroupID: group.ID, InvitationID: inv.ID, Accepted: accept},
+	}, inv.InviterID)
+
+	return nil
+}
+
+// joinRequestError and invitationError map a concurrent answer reported by
+// the repository to the conflict the caller would have got a moment later.
+func joinRequestError(err error) error {
+	if errors.Is(err, repository.ErrInviteNotPending) {
+		return ErrJoinRequestHandled
+	}
+	return err
+}
+
+func invitationError(err error) error {
+	if errors.Is(err, repository.ErrInviteNotPending) {
+		return ErrInvitationHandled
+	}
+	return err
+}
+
+func (s *GroupService) CreateInviteLink(ctx context.Context, groupID, actorID uint, input CreateInviteLinkInput) (*models.GroupInviteLink, error) {
+	if _, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	token, err := generateRandomString(inviteTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.GroupInviteLink{
+		GroupID:     groupID,
+		CreatedByID: actorID,
+		Token:       token,
+		MaxUses:     input.MaxUses,
+	}
+	if input.ExpiresInHrs > 0 {
+		expiresAt := time.Now().Add(time.Duration(input.ExpiresInHrs) * time.Hour)
+		link.ExpiresAt = &expiresAt
+	}
+
+	if err := s.deps.Repos.GroupInvite.CreateLink(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (s *GroupService) ListInviteLinks(ctx context.Context, groupID, actorID uint) ([]models.GroupInviteLink, error) {
+	if _, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.GroupInvite.ListLinks(ctx, groupID)
+}
+
+func (s *GroupService) RevokeInviteLink(ctx context.Context, groupID, linkID, actorID uint) error {
+	if _, err := requireGroupRole(ctx, s.deps, groupID, actorID, models.GroupRoleAdmin); err != nil {
+		return err
+	}
+
+	link, err := s.deps.Repos.GroupInvite.GetLinkByID(ctx, linkID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && link.GroupID != groupID) {
+		return fmt.Errorf("%w: invite link not found", ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.deps.Repos.GroupInvite.RevokeLink(ctx, linkID)
+}
+
+// JoinWithLink redeems an invite link token and returns the joined group.
+func (s *GroupService) JoinWithLink(ctx context.Context, token string, userID uint) (*models.Group, error) {
+	link, err := s.deps.Repos.GroupInvite.GetLinkByToken(ctx, token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInviteLinkInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !link.Usable(time.Now()) {
+		return nil, ErrInviteLinkInvalid
+	}
+
+	group, err := getGroup(ctx, s.deps, link.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanJoin(ctx, group.ID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.deps.Repos.GroupInvite.RedeemLink(ctx, link, userID); err != nil {
+		if errors.Is(err, repository.ErrInviteLinkExhausted) {
+			return nil, ErrInviteLinkInvalid
+		}
+		return nil, err
+	}
+
+	return group, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"regexp"
+)
+
+// maxMentions caps how many users a single post or comment can notify.
+const maxMentions = 20
+
+// mentionPattern matches @username where the @ does not follow a word
+// character, so e-mail addresses are not treated as mentions. Trailing dots
+// and dashes are left out so "@alice." mentions alice.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w(?:[\w.-]{0,48}\w)?)`)
+
+// parseMentions returns the distinct usernames mentioned in content, in order
+// of first appearance.
+func parseMentions(content string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		names = append(names, m[1])
+		if len(names) == maxMentions {
+			break
+		}
+	}
+	return names
+}
+
+// notifyMentions notifies users mentioned in content who were not already
+// mentioned in previous, the content before an edit. canSee filters out
+// users who may not see the content; users in skip are not notified, e.g.
+// because they already got a more specific notification.
+func notifyMentions(ctx context.Context, deps ServicesDeps, n Notice, content, previous string, canSee func(userID uint) bool, skip ...uint) {
+	before := make(map[string]bool)
+	for _, name := range parseMentions(previous) {
+		before[name] = true
+	}
+	var names []string
+	for _, name := range parseMentions(content) {
+		if !before[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	users, err := deps.Repos.User.GetByUsernames(ctx, names)
+	if err != nil {
+		deps.Logger.Error("Failed to resolve mentions", "error", err)
+		return
+	}
+	skipped := make(map[uint]bool, len(skip))
+	for _, id := range skip {
+		skipped[id] = true
+	}
+	var userIDs []uint
+	for _, u := range users {
+		if !skipped[u.ID] && canSee(u.ID) {
+			userIDs = append(userIDs, u.ID)
+		}
+	}
+	notify(ctx, deps, n, userIDs...)
+}
@@ -0,0 +1,552 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+	"gorm.io/gorm"
+)
+
+// mentorMinLevel is the level a user needs in a skill to mentor it.
+const mentorMinLevel = models.SkillLevelAdvanced
+
+// Mentor suggestions score points for the same department and, in
+// proportion to the share of free spots, for availability.
+const (
+	sameDepartmentBonus = 8
+	availabilityWeight  = 10
+)
+
+var (
+	ErrMentorshipNotFound  = fmt.Errorf("%w: mentorship not found", ErrNotFound)
+	ErrMentorOfferNotFound = fmt.Errorf("%w: you are not mentoring this skill", ErrNotFound)
+	ErrMentorshipExists    = fmt.Errorf("%w: you already have an open mentorship with this mentor for this skill", ErrConflict)
+	ErrMentorUnavailable   = fmt.Errorf("%w: mentor is not taking mentees for this skill", ErrConflict)
+	ErrMentorFull          = fmt.Errorf("%w: mentor has no free spot for this skill", ErrConflict)
+	ErrMentorshipStatus    = fmt.Errorf("%w: status must be pending, active, paused, ended or declined", ErrInvalidInput)
+	ErrMentorshipChanged   = fmt.Errorf("%w: mentorship was changed meanwhile", ErrConflict)
+)
+
+type MentorshipService struct {
+	deps ServicesDeps
+}
+
+func NewMentorshipService(deps ServicesDeps) *MentorshipService {
+	return &MentorshipService{deps: deps}
+}
+
+// MentorOfferInput opts in as a mentor for a skill or changes the offer.
+// Available defaults to true.
+type MentorOfferInput struct {
+	Capacity  int    `json:"capacity" binding:"required,min=1,max=20"`
+	Available *bool  `json:"available"`
+	Note      string `json:"note" binding:"max=1000"`
+}
+
+type RequestMentorshipInput struct {
+	MentorID uint   `json:"mentor_id" binding:"required"`
+	SkillID  uint   `json:"skill_id" binding:"required"`
+	Message  string `json:"message" binding:"max=1000"`
+}
+
+// MentorSuggestion is a mentor suggested for a skill, with a readable
+// summary of why. DepartmentDistance is 0 within the mentee's department
+// and 1 otherwise.
+type MentorSuggestion struct {
+	User               *models.User `json:"user"`
+	Score              float64      `json:"score"`
+	OfferID            uint         `json:"offer_id"`
+	UserSkillID        uint         `json:"user_skill_id"`
+	Level              string       `json:"level"`
+	VerifiedLevel      string       `json:"verified_level,omitempty"`
+	Verification       string       `json:"verification"`
+	Endorsements       int          `json:"endorsements"`
+	EndorsementWeight  float64      `json:"endorsement_weight"`
+	DepartmentDistance int          `json:"department_distance"`
+	FreeSpots          int          `json:"free_spots"`
+	Reasons            []string     `json:"reasons"`
+}
+
+// ListOffers returns the user's mentor offers with their mentee counts.
+func (s *MentorshipService) ListOffers(ctx context.Context, userID uint) ([]models.MentorOffer, error) {
+	return s.deps.Repos.Mentorship.ListOffers(ctx, userID)
+}
+
+// SetOffer opts the user in as a mentor for a skill they hold at advanced
+// level or above, or updates their offer. Lowering the capacity below the
+// current mentees only stops new ones from being accepted.
+func (s *MentorshipService) SetOffer(ctx context.Context, userID, skillID uint, input MentorOfferInput) (*models.MentorOffer, error) {
+	userSkill, err := s.deps.Repos.UserSkill.GetByUserAndSkill(ctx, userID, skillID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: add the skill to your profile before mentoring it", ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if models.SkillLevelRank(userSkill.EffectiveLevel(time.Now())) < models.SkillLevelRank(mentorMinLevel) {
+		return nil, fmt.Errorf("%w: you need %s level or above to mentor a skill", ErrInvalidInput, mentorMinLevel)
+	}
+
+	available := input.Available == nil || *input.Available
+	offer, err := s.deps.Repos.Mentorship.GetOffer(ctx, userID, skillID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		offer = &models.MentorOffer{
+			UserID:    userID,
+			SkillID:   skillID,
+			Capacity:  input.Capacity,
+			Available: available,
+			Note:      strings.TrimSpace(input.Note),
+		}
+		if err := s.deps.Repos.Mentorship.CreateOffer(ctx, offer); err != nil {
+			return nil, err
+		}
+		offer.Skill = userSkill.Skill
+		return offer, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	offer.Capacity = input.Capacity
+	offer.Available = available
+	offer.Note = strings.TrimSpace(input.Note)
+	if err := s.deps.Repos.Mentorship.UpdateOffer(ctx, offer); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// DeleteOffer stops the user mentoring a skill. Pending requests are
+// declined and their mentees notified; running mentorships continue.
+func (s *MentorshipService) DeleteOffer(ctx context.Context, userID, skillID uint) error {
+	offer, err := s.deps.Repos.Mentorship.GetOffer(ctx, userID, skillID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMentorOfferNotFound
+	}
+	if err != nil {
+		return err
+	}
+	declined, err := s.deps.Repos.Mentorship.DeleteOffer(ctx, offer)
+	if err != nil {
+		return err
+	}
+	for i := range declined {
+		declined[i].Skill = offer.Skill
+		s.notifyUpdate(ctx, &declined[i], userID, "declined your mentorship request for "+mentorshipSkillName(&declined[i]))
+	}
+	return nil
+}
+
+// Suggest ranks mentors for a skill the mentee wants to learn. Mentors hold
+// the skill at advanced level or above and above the mentee's own level,
+// are available, and have a free spot. They score ten points per level
+// (verified levels count instead of self-declared ones, with a bonus), two
+// per unit of endorsement weight up to 10, a bonus within the mentee's
+// department, and up to availabilityWeight for the share of their capacity
+// still free.
+func (s *MentorshipService) Suggest(ctx context.Context, menteeID, skillID uint, page, limit int) ([]MentorSuggestion, error) {
+	if page < 1 || limit < 1 {
+		return nil, fmt.Errorf("%w: page and limit must be positive", ErrInvalidInput)
+	}
+	skill, err := s.deps.Repos.Skill.GetByID(ctx, skillID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSkillNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	minRank := models.SkillLevelRank(mentorMinLevel)
+	menteeSkill, err := s.deps.Repos.UserSkill.GetByUserAndSkill(ctx, menteeID, skill.ID)
+	if err == nil {
+		minRank = max(minRank, models.SkillLevelRank(menteeSkill.EffectiveLevel(time.Now()))+1)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if minRank > len(models.SkillLevels) {
+		return []MentorSuggestion{}, nil
+	}
+	department := ""
+	profile, err := s.deps.Repos.Profile.GetByUserID(ctx, menteeID)
+	if err == nil {
+		department = profile.Department
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	candidates, err := s.deps.Repos.Mentorship.SearchMentors(ctx, repository.MentorFilter{
+		SkillID:  skill.ID,
+		MenteeID: menteeID,
+		MinLevel: models.SkillLevels[minRank-1],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]MentorSuggestion, 0, len(candidates))
+	for _, c := range candidates {
+		suggestion := MentorSuggestion{
+			User:               &models.User{ID: c.UserID},
+			Score:              mentorScore(c, department),
+			OfferID:            c.OfferID,
+			UserSkillID:        c.UserSkillID,
+			Level:              c.Level,
+			Verification:       models.VerificationSelfDeclared,
+			Endorsements:       c.Endorsements,
+			EndorsementWeight:  c.EndorsementWeight,
+			DepartmentDistance: departmentDistance(department, c.Department),
+			FreeSpots:          max(c.Capacity-c.Mentees, 0),
+		}
+		switch {
+		case c.Verified:
+			suggestion.VerifiedLevel, suggestion.Verification = c.VerifiedLevel, models.VerificationVerified
+		case c.VerifiedLevel != "":
+			suggestion.Verification = models.VerificationExpired
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].User.ID < suggestions[j].User.ID
+	})
+
+	offset := (page - 1) * limit
+	if offset >= len(suggestions) {
+		return []MentorSuggestion{}, nil
+	}
+	suggestions = suggestions[offset:min(offset+limit, len(suggestions))]
+
+	userIDs := make([]uint, len(suggestions))
+	for i, suggestion := range suggestions {
+		userIDs[i] = suggestion.User.ID
+	}
+	users, err := s.deps.Repos.User.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+	for i := range suggestions {
+		if user, ok := byID[suggestions[i].User.ID]; ok {
+			suggestions[i].User = user
+		}
+		suggestions[i].Reasons = mentorReasons(&suggestions[i], skill.Name)
+	}
+	return suggestions, nil
+}
+
+// Request asks a mentor to mentor the user in a skill. The mentor must have
+// an available offer for it and a higher level in it than the user. A
+// mentor at capacity can accept the request once a spot frees up.
+func (s *MentorshipService) Request(ctx context.Context, menteeID uint, input RequestMentorshipInput) (*models.Mentorship, error) {
+	if input.MentorID == menteeID {
+		return nil, fmt.Errorf("%w: you cannot mentor yourself", ErrInvalidInput)
+	}
+	mentor, err := s.deps.Repos.User.GetByID(ctx, input.MentorID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !mentor.IsActive) {
+		return nil, ErrMentorUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, menteeID, input.MentorID)
+	if err != nil {
+		return nil, err
+	}
+	offer, err := s.deps.Repos.Mentorship.GetOffer(ctx, input.MentorID, input.SkillID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && (!offer.Available || blocked)) {
+		return nil, ErrMentorUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	mentorSkill, err := s.deps.Repos.UserSkill.GetByUserAndSkill(ctx, input.MentorID, input.SkillID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMentorUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	mentorRank := models.SkillLevelRank(mentorSkill.EffectiveLevel(now))
+	if mentorRank < models.SkillLevelRank(mentorMinLevel) {
+		return nil, ErrMentorUnavailable
+	}
+	menteeSkill, err := s.deps.Repos.UserSkill.GetByUserAndSkill(ctx, menteeID, input.SkillID)
+	if err == nil && models.SkillLevelRank(menteeSkill.EffectiveLevel(now)) >= mentorRank {
+		return nil, fmt.Errorf("%w: your level is not below the mentor's", ErrInvalidInput)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	exists, err := s.deps.Repos.Mentorship.ExistsOpen(ctx, input.MentorID, menteeID, input.SkillID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrMentorshipExists
+	}
+
+	mentorship := &models.Mentorship{
+		MentorID: input.MentorID,
+		MenteeID: menteeID,
+		SkillID:  input.SkillID,
+		Status:   models.MentorshipStatusPending,
+		Message:  strings.TrimSpace(input.Message),
+	}
+	if err := s.deps.Repos.Mentorship.Create(ctx, mentorship); err != nil {
+		return nil, err
+	}
+	mentorship.Skill = offer.Skill
+	notify(ctx, s.deps, Notice{
+		ActorID: menteeID,
+		Title:   "Mentorship request",
+		Action:  fmt.Sprintf("asked you to mentor them in %s", mentorshipSkillName(mentorship)),
+		Link:    fmt.Sprintf("/mentorships/%d", mentorship.ID),
+		Data:    models.MentorshipRequestedData{MentorshipID: mentorship.ID, SkillID: mentorship.SkillID},
+	}, mentorship.MentorID)
+	return mentorship, nil
+}
+
+// List returns the user's mentorships as "mentor", as "mentee", or both
+// when role is empty.
+func (s *MentorshipService) List(ctx context.Context, userID uint, role, status string, page, limit int) ([]models.Mentorship, error) {
+	switch role {
+	case "", "mentor", "mentee":
+	default:
+		return nil, fmt.Errorf("%w: role must be mentor or mentee", ErrInvalidInput)
+	}
+	switch status {
+	case "", models.MentorshipStatusPending, models.MentorshipStatusActive, models.MentorshipStatusPaused,
+		models.MentorshipStatusEnded, models.MentorshipStatusDeclined:
+	default:
+		return nil, ErrMentorshipStatus
+	}
+	return s.deps.Repos.Mentorship.List(ctx, userID, role, status, page, limit)
+}
+
+// Get returns a mentorship to its mentor or mentee.
+func (s *MentorshipService) Get(ctx context.Context, id, userID uint) (*models.Mentorship, error) {
+	return s.getMentorship(ctx, id, userID)
+}
+
+// Accept lets the mentor take on a pending request if their offer for the
+// skill still has a free spot.
+func (s *MentorshipService) Accept(ctx context.Context, id, userID uint) (*models.Mentorship, error) {
+	mentorship, err := s.getMentorship(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mentorship.MentorID != userID {
+		return nil, fmt.Errorf("%w: only the mentor can accept a request", ErrForbidden)
+	}
+	if mentorship.Status != models.MentorshipStatusPending {
+		return nil, mentorshipStatusError(mentorship, "accepted")
+	}
+	accepted, err := s.deps.Repos.Mentorship.Accept(ctx, mentorship)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMentorOfferNotFound
+	}
+	if errors.Is(err, repository.ErrMentorshipChanged) {
+		return nil, ErrMentorshipChanged
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, ErrMentorFull
+	}
+
+	notify(ctx, s.deps, Notice{
+		ActorID: userID,
+		Title:   "Mentorship accepted",
+		Action:  fmt.Sprintf("agreed to mentor you in %s", mentorshipSkillName(mentorship)),
+		Link:    fmt.Sprintf("/mentorships/%d", mentorship.ID),
+		Data:    models.MentorshipAcceptedData{MentorshipID: mentorship.ID, SkillID: mentorship.SkillID},
+	}, mentorship.MenteeID)
+	return mentorship, nil
+}
+
+// Decline lets the mentor turn down a pending request.
+func (s *MentorshipService) Decline(ctx context.Context, id, userID uint) (*models.Mentorship, error) {
+	mentorship, err := s.getMentorship(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mentorship.MentorID != userID {
+		return nil, fmt.Errorf("%w: only the mentor can decline a request", ErrForbidden)
+	}
+	if mentorship.Status != models.MentorshipStatusPending {
+		return nil, mentorshipStatusError(mentorship, "declined")
+	}
+	mentorship.Status = models.MentorshipStatusDeclined
+	return mentorship, s.update(ctx, mentorship, models.MentorshipStatusPending, userID, "declined your mentorship request for "+mentorshipSkillName(mentorship))
+}
+
+// Pause puts an active mentorship on hold. It keeps its spot in the
+// mentor's capacity.
+func (s *MentorshipService) Pause(ctx context.Context, id, userID uint) (*models.Mentorship, error) {
+	mentorship, err := s.getMentorship(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mentorship.Status != models.MentorshipStatusActive {
+		return nil, mentorshipStatusError(mentorship, "paused")
+	}
+	mentorship.Status = models.MentorshipStatusPaused
+	mentorship.PausedByID = &userID
+	return mentorship, s.update(ctx, mentorship, models.MentorshipStatusActive, userID, "paused your "+mentorshipSkillName(mentorship)+" mentorship")
+}
+
+// Resume reactivates a paused mentorship.
+func (s *MentorshipService) Resume(ctx context.Context, id, userID uint) (*models.Mentorship, error) {
+	mentorship, err := s.getMentorship(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mentorship.Status != models.MentorshipStatusPaused {
+		return nil, mentorshipStatusError(mentorship, "resumed")
+	}
+	mentorship.Status = models.MentorshipStatusActive
+	mentorship.PausedByID = nil
+	return mentorship, s.update(ctx, mentorship, models.MentorshipStatusPaused, userID, "resumed your "+mentorshipSkillName(mentorship)+" mentorship")
+}
+
+// End finishes an active or paused mentorship, freeing the mentor's spot.
+// Mentees can also withdraw a pending request this way.
+func (s *MentorshipService) End(ctx context.Context, id, userID uint) (*models.Mentorship, error) {
+	mentorship, err := s.getMentorship(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	action := "ended your " + mentorshipSkillName(mentorship) + " mentorship"
+	switch mentorship.Status {
+	case models.MentorshipStatusActive, models.MentorshipStatusPaused:
+	case models.MentorshipStatusPending:
+		if mentorship.MenteeID != userID {
+			return nil, fmt.Errorf("%w: decline the request instead", ErrInvalidInput)
+		}
+		action = "withdrew their mentorship request for " + mentorshipSkillName(mentorship)
+	default:
+		return nil, mentorshipStatusError(mentorship, "ended")
+	}
+	from := mentorship.Status
+	now := time.Now()
+	mentorship.Status = models.MentorshipStatusEnded
+	mentorship.PausedByID = nil
+	mentorship.EndedAt = &now
+	return mentorship, s.update(ctx, mentorship, from, userID, action)
+}
+
+// update saves a status change from the given status and notifies the other
+// side of the action. A concurrent change of the status is a conflict.
+func (s *MentorshipService) update(ctx context.Context, mentorship *models.Mentorship, from string, userID uint, action string) error {
+	err := s.deps.Repos.Mentorship.UpdateStatus(ctx, mentorship, from)
+	if errors.Is(err, repository.ErrMentorshipChanged) {
+		return ErrMentorshipChanged
+	}
+	if err != nil {
+		return err
+	}
+	s.notifyUpdate(ctx, mentorship, userID, action)
+	return nil
+}
+
+func (s *MentorshipService) notifyUpdate(ctx context.Context, mentorship *models.Mentorship, userID uint, action string) {
+	recipient := mentorship.MentorID
+	if userID == mentorship.MentorID {
+		recipient = mentorship.MenteeID
+	}
+	notify(ctx, s.deps, Notice{
+		ActorID: userID,
+		Title:   "Mentorship updated",
+		Action:  action,
+		Link:    fmt.Sprintf("/mentorships/%d", mentorship.ID),
+		Data: models.MentorshipUpdatedData{
+			MentorshipID: mentorship.ID,
+			SkillID:      mentorship.SkillID,
+			Status:       mentorship.Status,
+		},
+	}, recipient)
+}
+
+// getMentorship returns the mentorship if the user is its mentor or mentee.
+func (s *MentorshipService) getMentorship(ctx context.Context, id, userID uint) (*models.Mentorship, error) {
+	mentorship, err := s.deps.Repos.Mentorship.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMentorshipNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mentorship.MentorID != userID && mentorship.MenteeID != userID {
+		return nil, ErrMentorshipNotFound
+	}
+	return mentorship, nil
+}
+
+func mentorshipStatusError(mentorship *models.Mentorship, action string) error {
+	return fmt.Errorf("%w: a %s mentorship cannot be %s", ErrConflict, mentorship.Status, action)
+}
+
+func mentorshipSkillName(mentorship *models.Mentorship) string {
+	if mentorship.Skill != nil {
+		return mentorship.Skill.Name
+	}
+	return "a skill"
+}
+
+// mentorScore rates a mentor for a mentee in the given department.
+func mentorScore(c repository.MentorCandidate, department string) float64 {
+	level := c.Level
+	if c.Verified {
+		level = c.VerifiedLevel
+	}
+	score := float64(10*models.SkillLevelRank(level)) + 2*min(c.EndorsementWeight, 10)
+	if c.Verified {
+		score += verifiedSkillBonus
+	}
+	if departmentDistance(department, c.Department) == 0 {
+		score += sameDepartmentBonus
+	}
+	if c.Capacity > 0 {
+		score += availabilityWeight * float64(max(c.Capacity-c.Mentees, 0)) / float64(c.Capacity)
+	}
+	return score
+}
+
+// departmentDistance is 0 for two users in the same department and 1 across
+// departments or when either department is unknown; departments are flat.
+func departmentDistance(a, b string) int {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a != "" && strings.EqualFold(a, b) {
+		return 0
+	}
+	return 1
+}
+
+// mentorReasons explains a suggestion, e.g. "Go: expert (verified), 4
+// endorsements", "same department", "2 free spots".
+func mentorReasons(suggestion *MentorSuggestion, skill string) []string {
+	level := suggestion.Level + " (self-declared)"
+	if suggestion.Verification == models.VerificationVerified {
+		level = suggestion.VerifiedLevel + " (verified)"
+	}
+	reasons := []string{fmt.Sprintf("%s: %s, %s", skill, level, plural(suggestion.Endorsements, "endorsement"))}
+	if suggestion.DepartmentDistance == 0 {
+		reasons = append(reasons, "same department")
+	}
+	reasons = append(reasons, plural(suggestion.FreeSpots, "free spot"))
+	return reasons
+}
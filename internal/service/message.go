@@ -0,0 +1,850 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/realtime"
+	"gorm.io/gorm"
+)
+
+type MessageService struct {
+	deps ServicesDeps
+}
+
+func NewMessageService(deps ServicesDeps) *MessageService {
+	return &MessageService{deps: deps}
+}
+
+const (
+	// maxConversationParticipants caps the size of group conversations.
+	maxConversationParticipants = 100
+	// messageEditWindow is how long after sending a message can be edited.
+	messageEditWindow = 15 * time.Minute
+	// typingThrottle is the minimum interval between typing events relayed
+	// for the same user and conversation.
+	typingThrottle = 3 * time.Second
+)
+
+// PostMessageInput is a message to post. Content may be empty when the
+// message carries attachments.
+type PostMessageInput struct {
+	Content       string `json:"content" binding:"max=5000"`
+	ReplyToID     *uint  `json:"reply_to_id"`
+	AttachmentIDs []uint `json:"attachment_ids" binding:"max=10"`
+}
+
+type SendMessageInput struct {
+	SenderID   uint `json:"-"`
+	ReceiverID uint `json:"receiver_id" binding:"required"`
+	PostMessageInput
+}
+
+type EditMessageInput struct {
+	Content string `json:"content" binding:"required,max=5000"`
+}
+
+// ReactionEvent is pushed to participants when a message reaction changes.
+type ReactionEvent struct {
+	MessageID      uint   `json:"message_id"`
+	ConversationID uint   `json:"conversation_id"`
+	UserID         uint   `json:"user_id"`
+	Emoji          string `json:"emoji"`
+	Removed        bool   `json:"removed"`
+}
+
+// MessageDeletedEvent is pushed when a message is deleted, to everyone or
+// only to the user who deleted it for themselves.
+type MessageDeletedEvent struct {
+	MessageID      uint `json:"message_id"`
+	ConversationID uint `json:"conversation_id"`
+}
+
+type CreateConversationInput struct {
+	CreatorID      uint   `json:"-"`
+	Title          string `json:"title" binding:"max=100"`
+	ParticipantIDs []uint `json:"participant_ids" binding:"required,min=1"`
+}
+
+type UpdateConversationInput struct {
+	Title string `json:"title" binding:"max=100"`
+}
+
+// ConversationView is a conversation as seen by one participant.
+type ConversationView struct {
+	*models.Conversation
+	LastMessage *models.Message `json:"last_message"`
+	UnreadCount int64           `json:"unread_count"`
+}
+
+var conversationRoleRank = map[string]int{
+	models.ConversationRoleMember: 1,
+	models.ConversationRoleAdmin:  2,
+	models.ConversationRoleOwner:  3,
+}
+
+var (
+	ErrMessageNotFound      = fmt.Errorf("%w: message not found", ErrNotFound)
+	ErrConversationNotFound = fmt.Errorf("%w: conversation not found", ErrNotFound)
+	ErrRecipientBlocked     = fmt.Errorf("%w: you cannot message this user", ErrForbidden)
+	ErrConnectionsOnly      = fmt.Errorf("%w: this user only accepts messages from connections", ErrForbidden)
+	ErrDirectConversation   = fmt.Errorf("%w: direct conversations cannot be changed", ErrInvalidInput)
+	ErrEditWindowExpired    = fmt.Errorf("%w: this message can no longer be edited", ErrForbidden)
+	ErrInvalidEmoji         = fmt.Errorf("%w: reaction must be an emoji", ErrInvalidInput)
+)
+
+// Send delivers a direct message, creating the 1:1 conversation on first
+// contact. Blocks in either direction and the recipient's message privacy
+// setting are checked on every message.
+func (s *MessageService) Send(ctx context.Context, input SendMessageInput) (*models.Message, error) {
+	if input.SenderID == input.ReceiverID {
+		return nil, fmt.Errorf("%w: cannot message yourself", ErrInvalidInput)
+	}
+	if err := s.checkCanMessage(ctx, input.SenderID, input.ReceiverID); err != nil {
+		return nil, err
+	}
+
+	conversation, err := s.getOrCreateDirect(ctx, input.SenderID, input.ReceiverID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.post(ctx, conversation.ID, input.SenderID, &input.ReceiverID, input.PostMessageInput)
+}
+
+// PostToConversation sends a message to every participant of the
+// conversation. The sender must be a participant.
+func (s *MessageService) PostToConversation(ctx context.Context, conversationID, senderID uint, input PostMessageInput) (*models.Message, error) {
+	conversation, err := s.getConversation(ctx, conversationID, senderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var receiverID *uint
+	if conversation.Type == models.ConversationDirect {
+		peerID := directPeerID(conversation, senderID)
+		if err := s.checkCanMessage(ctx, senderID, peerID); err != nil {
+			return nil, err
+		}
+		receiverID = &peerID
+	}
+
+	return s.post(ctx, conversation.ID, senderID, receiverID, input)
+}
+
+// CreateConversation starts a group conversation owned by the creator. Every
+// invited user must be reachable by the creator under the usual messaging
+// rules.
+func (s *MessageService) CreateConversation(ctx context.Context, input CreateConversationInput) (*ConversationView, error) {
+	participantIDs := uniqueIDs(input.ParticipantIDs, input.CreatorID)
+	if len(participantIDs) == 0 {
+		return nil, fmt.Errorf("%w: add at least one other participant", ErrInvalidInput)
+	}
+	if len(participantIDs)+1 > maxConversationParticipants {
+		return nil, fmt.Errorf("%w: conversations are limited to %d participants", ErrInvalidInput, maxConversationParticipants)
+	}
+	for _, id := range participantIDs {
+		if err := s.checkCanMessage(ctx, input.CreatorID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	conversation := &models.Conversation{
+		Type:      models.ConversationGroup,
+		Title:     input.Title,
+		CreatorID: input.CreatorID,
+	}
+	participants := []models.ConversationParticipant{{
+		UserID:   input.CreatorID,
+		Role:     models.ConversationRoleOwner,
+		JoinedAt: now,
+	}}
+	for _, id := range participantIDs {
+		participants = append(participants, models.ConversationParticipant{
+			UserID:   id,
+			Role:     models.ConversationRoleMember,
+			JoinedAt: now,
+		})
+	}
+	if err := s.deps.Repos.Conversation.Create(ctx, conversation, participants); err != nil {
+		return nil, err
+	}
+
+	return s.GetConversationByID(ctx, conversation.ID, input.CreatorID)
+}
+
+// GetConversations lists the user's conversations, most recent first, with
+// the last message and unread count for each.
+func (s *MessageService) GetConversations(ctx context.Context, userID uint, page, limit int) ([]ConversationView, error) {
+	summaries, err := s.deps.Repos.Conversation.ListForUser(ctx, userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(summaries))
+	for _, c := range summaries {
+		ids = append(ids, c.ConversationID)
+	}
+	conversations, err := s.deps.Repos.Conversation.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	messageIDs := make([]uint, 0, len(conversations))
+	for _, c := range conversations {
+		if c.LastMessageID != nil {
+			messageIDs = append(messageIDs, *c.LastMessageID)
+		}
+	}
+	messages, err := s.deps.Repos.Message.GetByIDs(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	conversationsByID := make(map[uint]*models.Conversation, len(conversations))
+	for i := range conversations {
+		conversationsByID[conversations[i].ID] = &conversations[i]
+	}
+	messagesByID := make(map[uint]*models.Message, len(messages))
+	for i := range messages {
+		messagesByID[messages[i].ID] = &messages[i]
+	}
+
+	views := make([]ConversationView, 0, len(summaries))
+	for _, c := range summaries {
+		conversation, ok := conversationsByID[c.ConversationID]
+		if !ok {
+			continue
+		}
+		view := ConversationView{Conversation: conversation, UnreadCount: c.UnreadCount}
+		if conversation.LastMessageID != nil {
+			view.LastMessage = messagesByID[*conversation.LastMessageID]
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// GetConversationByID returns the conversation with its participants.
+func (s *MessageService) GetConversationByID(ctx context.Context, id, userID uint) (*ConversationView, error) {
+	conversation, err := s.getConversation(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &ConversationView{Conversation: conversation}
+	if conversation.LastMessageID != nil {
+		messages, err := s.deps.Repos.Message.GetByIDs(ctx, []uint{*conversation.LastMessageID})
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) > 0 {
+			view.LastMessage = &messages[0]
+		}
+	}
+	return view, nil
+}
+
+// GetMessages returns messages of the conversation, newest first. Pass the
+// oldest ID already loaded as beforeID to fetch the previous page.
+func (s *MessageService) GetMessages(ctx context.Context, id, userID, beforeID uint, limit int) ([]models.Message, error) {
+	if _, err := s.getParticipant(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Message.GetHistory(ctx, id, userID, beforeID, limit)
+}
+
+// GetConversation returns messages exchanged with peerID in their direct
+// conversation, newest first.
+func (s *MessageService) GetConversation(ctx context.Context, userID, peerID, beforeID uint, limit int) ([]models.Message, error) {
+	conversation, err := s.deps.Repos.Conversation.GetDirect(ctx, userID, peerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []models.Message{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Message.GetHistory(ctx, conversation.ID, userID, beforeID, limit)
+}
+
+// UpdateConversation changes the title of a group conversation. Admins and
+// the owner can do this.
+func (s *MessageService) UpdateConversation(ctx context.Context, id, userID uint, input UpdateConversationInput) (*ConversationView, error) {
+	conversation, err := s.getGroupConversation(ctx, id, userID, models.ConversationRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation.Title = input.Title
+	if err := s.deps.Repos.Conversation.Update(ctx, conversation); err != nil {
+		return nil, err
+	}
+	return s.GetConversationByID(ctx, id, userID)
+}
+
+// AddParticipants adds users to a group conversation. Admins and the owner
+// can do this.
+func (s *MessageService) AddParticipants(ctx context.Context, id, actorID uint, userIDs []uint) (*ConversationView, error) {
+	conversation, err := s.getGroupConversation(ctx, id, actorID, models.ConversationRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[uint]bool, len(conversation.Participants))
+	for _, p := range conversation.Participants {
+		existing[p.UserID] = true
+	}
+	var added []models.ConversationParticipant
+	now := time.Now()
+	for _, userID := range uniqueIDs(userIDs, actorID) {
+		if existing[userID] {
+			continue
+		}
+		if err := s.checkCanMessage(ctx, actorID, userID); err != nil {
+			return nil, err
+		}
+		added = append(added, models.ConversationParticipant{
+			ConversationID: id,
+			UserID:         userID,
+			Role:           models.ConversationRoleMember,
+			JoinedAt:       now,
+		})
+	}
+	if len(conversation.Participants)+len(added) > maxConversationParticipants {
+		return nil, fmt.Errorf("%w: conversations are limited to %d participants", ErrInvalidInput, maxConversationParticipants)
+	}
+
+	if err := s.deps.Repos.Conversation.AddParticipants(ctx, added); err != nil {
+		return nil, err
+	}
+	return s.GetConversationByID(ctx, id, actorID)
+}
+
+// RemoveParticipant removes userID from a group conversation. Participants
+// can always remove themselves; removing someone else requires outranking
+// them. When the owner leaves, the longest-standing admin, or failing that
+// the longest-standing member, becomes the owner.
+func (s *MessageService) RemoveParticipant(ctx context.Context, id, actorID, userID uint) error {
+	conversation, err := s.getConversation(ctx, id, actorID)
+	if err != nil {
+		return err
+	}
+	if conversation.Type == models.ConversationDirect {
+		return ErrDirectConversation
+	}
+
+	var actor, target *models.ConversationParticipant
+	for i := range conversation.Participants {
+		switch conversation.Participants[i].UserID {
+		case actorID:
+			actor = &conversation.Participants[i]
+		case userID:
+			target = &conversation.Participants[i]
+		}
+	}
+	if actorID == userID {
+		target = actor
+	}
+	if target == nil {
+		return fmt.Errorf("%w: user is not a participant", ErrNotFound)
+	}
+	if actorID != userID && conversationRoleRank[actor.Role] <= conversationRoleRank[target.Role] {
+		return fmt.Errorf("%w: you cannot remove this participant", ErrForbidden)
+	}
+
+	if err := s.deps.Repos.Conversation.RemoveParticipant(ctx, id, userID); err != nil {
+		return err
+	}
+	if target.Role != models.ConversationRoleOwner {
+		return nil
+	}
+
+	var successor *models.ConversationParticipant
+	for i := range conversation.Participants {
+		p := &conversation.Participants[i]
+		if p.UserID == userID {
+			continue
+		}
+		if successor == nil || conversationRoleRank[p.Role] > conversationRoleRank[successor.Role] {
+			successor = p
+		}
+	}
+	if successor == nil {
+		return nil
+	}
+	return s.deps.Repos.Conversation.UpdateParticipantRole(ctx, id, successor.UserID, models.ConversationRoleOwner)
+}
+
+// ChangeParticipantRole promotes or demotes a participant between admin and
+// member. Only the owner can do this.
+func (s *MessageService) ChangeParticipantRole(ctx context.Context, id, actorID, userID uint, role string) error {
+	if role != models.ConversationRoleAdmin && role != models.ConversationRoleMember {
+		return fmt.Errorf("%w: role must be admin or member", ErrInvalidInput)
+	}
+	if _, err := s.getGroupConversation(ctx, id, actorID, models.ConversationRoleOwner); err != nil {
+		return err
+	}
+	if actorID == userID {
+		return fmt.Errorf("%w: the owner cannot change their own role", ErrInvalidInput)
+	}
+	if _, err := s.getParticipant(ctx, id, userID); err != nil {
+		return fmt.Errorf("%w: user is not a participant", ErrNotFound)
+	}
+	return s.deps.Repos.Conversation.UpdateParticipantRole(ctx, id, userID, role)
+}
+
+// MarkConversationRead advances the user's read cursor to messageID, or to
+// the latest message when messageID is zero.
+func (s *MessageService) MarkConversationRead(ctx context.Context, id, userID, messageID uint) error {
+	conversation, err := s.getConversation(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if messageID == 0 {
+		if conversation.LastMessageID == nil {
+			return nil
+		}
+		messageID = *conversation.LastMessageID
+	}
+	return s.markRead(ctx, conversation, userID, messageID)
+}
+
+// MarkAsRead marks the message, and every earlier message in its
+// conversation, as read by the user.
+func (s *MessageService) MarkAsRead(ctx context.Context, id, userID uint) error {
+	message, err := s.getMessage(ctx, id)
+	if err != nil {
+		return err
+	}
+	if message.ConversationID == nil || message.SenderID == userID {
+		return ErrMessageNotFound
+	}
+	conversation, err := s.getConversation(ctx, *message.ConversationID, userID)
+	if errors.Is(err, ErrConversationNotFound) {
+		return ErrMessageNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.markRead(ctx, conversation, userID, message.ID)
+}
+
+// Edit replaces the content of a message. Only the sender can edit, and only
+// within messageEditWindow of sending and while still a participant.
+func (s *MessageService) Edit(ctx context.Context, id, userID uint, input EditMessageInput) (*models.Message, error) {
+	message, err := s.getParticipantMessage(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID != userID {
+		return nil, fmt.Errorf("%w: only the sender can edit a message", ErrForbidden)
+	}
+	if time.Since(message.CreatedAt) > messageEditWindow {
+		return nil, ErrEditWindowExpired
+	}
+
+	now := time.Now()
+	message.Content = input.Content
+	message.IsEdited = true
+	message.EditedAt = &now
+	if err := s.deps.Repos.Message.Edit(ctx, message); err != nil {
+		return nil, err
+	}
+
+	s.publishToConversation(ctx, message.ConversationID, realtime.Event{Type: realtime.EventMessageUpdated, Data: message})
+	return message, nil
+}
+
+// Delete removes a message for every participant. Only the sender can do
+// this, and only while still in the conversation.
+func (s *MessageService) Delete(ctx context.Context, id, userID uint) error {
+	message, err := s.getParticipantMessage(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if message.SenderID != userID {
+		return fmt.Errorf("%w: only the sender can delete a message for everyone", ErrForbidden)
+	}
+	if err := s.deps.Repos.Message.Delete(ctx, message); err != nil {
+		return err
+	}
+
+	s.publishToConversation(ctx, message.ConversationID, realtime.Event{
+		Type: realtime.EventMessageDeleted,
+		Data: deletedEvent(message),
+	})
+	return nil
+}
+
+// DeleteForMe hides a message from the user's own history. Any participant
+// can do this with any message.
+func (s *MessageService) DeleteForMe(ctx context.Context, id, userID uint) error {
+	message, err := s.getParticipantMessage(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.deps.Repos.Message.Hide(ctx, message.ID, userID); err != nil {
+		return err
+	}
+
+	publish(ctx, s.deps, realtime.Event{Type: realtime.EventMessageDeleted, Data: deletedEvent(message)}, userID)
+	return nil
+}
+
+// AddReaction reacts to a message with an emoji.
+func (s *MessageService) AddReaction(ctx context.Context, id, userID uint, emoji string) error {
+	if !isEmoji(emoji) {
+		return ErrInvalidEmoji
+	}
+	message, err := s.getParticipantMessage(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	reaction := &models.MessageReaction{MessageID: message.ID, UserID: userID, Emoji: emoji}
+	if err := s.deps.Repos.Message.AddReaction(ctx, reaction); err != nil {
+		return err
+	}
+
+	s.publishToConversation(ctx, message.ConversationID, realtime.Event{
+		Type: realtime.EventMessageReaction,
+		Data: ReactionEvent{MessageID: message.ID, ConversationID: *message.ConversationID, UserID: userID, Emoji: emoji},
+	})
+	return nil
+}
+
+// RemoveReaction removes the user's emoji reaction from a message.
+func (s *MessageService) RemoveReaction(ctx context.Context, id, userID uint, emoji string) error {
+	message, err := s.getParticipantMessage(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.deps.Repos.Message.RemoveReaction(ctx, message.ID, userID, emoji)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return fmt.Errorf("%w: reaction not found", ErrNotFound)
+	}
+
+	s.publishToConversation(ctx, message.ConversationID, realtime.Event{
+		Type: realtime.EventMessageReaction,
+		Data: ReactionEvent{MessageID: message.ID, ConversationID: *message.ConversationID, UserID: userID, Emoji: emoji, Removed: true},
+	})
+	return nil
+}
+
+// TypingEvent is pushed to the other participants while a user is typing.
+type TypingEvent struct {
+	ConversationID uint `json:"conversation_id"`
+	UserID         uint `json:"user_id"`
+}
+
+// Typing tells the conversation's other participants that the user is
+// typing. Clients send this on keystrokes; events beyond one per
+// typingThrottle are dropped silently.
+func (s *MessageService) Typing(ctx context.Context, conversationID, userID uint) error {
+	if _, err := s.getParticipant(ctx, conversationID, userID); err != nil {
+		return err
+	}
+	key := fmt.Sprintf("typing:%d:%d", conversationID, userID)
+	first, err := s.deps.Cache.SetNX(ctx, key, 1, typingThrottle).Result()
+	if err != nil {
+		return err
+	}
+	if !first {
+		return nil
+	}
+
+	userIDs, err := s.deps.Repos.Conversation.ListParticipantIDs(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+
+	others := make([]uint, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != userID {
+			others = append(others, id)
+		}
+	}
+	publish(ctx, s.deps, realtime.Event{
+		Type: realtime.EventTyping,
+		Data: TypingEvent{ConversationID: conversationID, UserID: userID},
+	}, others...)
+	return nil
+}
+
+// post validates the reply target and attachments, stores the message and
+// pushes it to the conversation's participants.
+func (s *MessageService) post(ctx context.Context, conversationID, senderID uint, receiverID *uint, input PostMessageInput) (*models.Message, error) {
+	attachmentIDs := uniqueIDs(input.AttachmentIDs, 0)
+	if strings.TrimSpace(input.Content) == "" && len(attachmentIDs) == 0 {
+		return nil, fmt.Errorf("%w: message must have content or attachments", ErrInvalidInput)
+	}
+
+	message := &models.Message{
+		ConversationID: &conversationID,
+		SenderID:       senderID,
+		ReceiverID:     receiverID,
+		Content:        input.Content,
+	}
+
+	if input.ReplyToID != nil {
+		replyTo, err := s.deps.Repos.Message.GetByID(ctx, *input.ReplyToID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err != nil || replyTo.ConversationID == nil || *replyTo.ConversationID != conversationID {
+			return nil, fmt.Errorf("%w: can only reply to a message in the same conversation", ErrInvalidInput)
+		}
+		replyTo.ReplyTo = nil
+		message.ReplyToID = &replyTo.ID
+		message.ReplyTo = replyTo
+	}
+
+	if len(attachmentIDs) > 0 {
+		files, err := s.deps.Repos.File.GetByIDs(ctx, attachmentIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) != len(attachmentIDs) {
+			return nil, fmt.Errorf("%w: attachment not found", ErrInvalidInput)
+		}
+		for _, f := range files {
+			if f.UserID != senderID {
+				return nil, fmt.Errorf("%w: you can only attach your own files", ErrForbidden)
+			}
+		}
+		message.Attachments = files
+	}
+
+	if err := s.deps.Repos.Message.Create(ctx, message); err != nil {
+		return nil, err
+	}
+
+	s.publishToConversation(ctx, message.ConversationID, realtime.Event{Type: realtime.EventMessageCreated, Data: message})
+	return message, nil
+}
+
+// publishToConversation pushes the event to every participant, including the
+// acting user so their other devices stay in sync.
+func (s *MessageService) publishToConversation(ctx context.Context, conversationID *uint, event realtime.Event) {
+	if conversationID == nil {
+		return
+	}
+	userIDs, err := s.deps.Repos.Conversation.ListParticipantIDs(ctx, *conversationID)
+	if err != nil {
+		s.deps.Logger.Error("Failed to list conversation participants", "conversation_id", *conversationID, "error", err)
+		return
+	}
+	publish(ctx, s.deps, event, userIDs...)
+}
+
+// markRead advances the read cursor and, in direct conversations, also
+// stamps the per-message read state.
+func (s *MessageService) markRead(ctx context.Context, conversation *models.Conversation, userID, messageID uint) error {
+	if err := s.deps.Repos.Conversation.AdvanceReadCursor(ctx, conversation.ID, userID, messageID); err != nil {
+		return err
+	}
+	if conversation.Type != models.ConversationDirect {
+		return nil
+	}
+	_, err := s.deps.Repos.Message.MarkReadUpTo(ctx, conversation.ID, userID, messageID, time.Now())
+	return err
+}
+
+// getOrCreateDirect returns the direct conversation between the two users,
+// creating it when they have never messaged before.
+func (s *MessageService) getOrCreateDirect(ctx context.Context, userID, peerID uint) (*models.Conversation, error) {
+	conversation, err := s.deps.Repos.Conversation.GetDirect(ctx, userID, peerID)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return conversation, err
+	}
+
+	key := models.DirectConversationKey(userID, peerID)
+	now := time.Now()
+	conversation = &models.Conversation{
+		Type:      models.ConversationDirect,
+		CreatorID: userID,
+		DirectKey: &key,
+	}
+	participants := []models.ConversationParticipant{
+		{UserID: userID, Role: models.ConversationRoleMember, JoinedAt: now},
+		{UserID: peerID, Role: models.ConversationRoleMember, JoinedAt: now},
+	}
+	if err := s.deps.Repos.Conversation.Create(ctx, conversation, participants); err != nil {
+		// Lost a race with the peer creating the same conversation.
+		if existing, getErr := s.deps.Repos.Conversation.GetDirect(ctx, userID, peerID); getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// getConversation loads a conversation the user participates in. Other
+// users see it as not found.
+func (s *MessageService) getConversation(ctx context.Context, id, userID uint) (*models.Conversation, error) {
+	conversation, err := s.deps.Repos.Conversation.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range conversation.Participants {
+		if p.UserID == userID {
+			return conversation, nil
+		}
+	}
+	return nil, ErrConversationNotFound
+}
+
+// getGroupConversation loads a group conversation and checks that the user
+// holds at least minRole in it.
+func (s *MessageService) getGroupConversation(ctx context.Context, id, userID uint, minRole string) (*models.Conversation, error) {
+	conversation, err := s.getConversation(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Type == models.ConversationDirect {
+		return nil, ErrDirectConversation
+	}
+	for _, p := range conversation.Participants {
+		if p.UserID == userID && conversationRoleRank[p.Role] < conversationRoleRank[minRole] {
+			return nil, fmt.Errorf("%w: requires %s role", ErrForbidden, minRole)
+		}
+	}
+	return conversation, nil
+}
+
+func (s *MessageService) getParticipant(ctx context.Context, id, userID uint) (*models.ConversationParticipant, error) {
+	participant, err := s.deps.Repos.Conversation.GetParticipant(ctx, id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConversationNotFound
+	}
+	return participant, err
+}
+
+func (s *MessageService) checkCanMessage(ctx context.Context, senderID, receiverID uint) error {
+	receiver, err := s.deps.Repos.User.GetByID(ctx, receiverID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !receiver.IsActive) {
+		return fmt.Errorf("%w: recipient not found", ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, senderID, receiverID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrRecipientBlocked
+	}
+
+	settings, err := s.deps.Repos.UserSettings.GetByUserID(ctx, receiverID)
+	if err != nil {
+		return err
+	}
+	if settings.MessagePrivacy == models.MessagePrivacyConnections {
+		connected, err := s.deps.Repos.Connection.AreConnected(ctx, senderID, receiverID)
+		if err != nil {
+			return err
+		}
+		if !connected {
+			return ErrConnectionsOnly
+		}
+	}
+
+	return nil
+}
+
+func (s *MessageService) getMessage(ctx context.Context, id uint) (*models.Message, error) {
+	message, err := s.deps.Repos.Message.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMessageNotFound
+	}
+	return message, err
+}
+
+// getParticipantMessage loads a message from a conversation the user
+// participates in. Other users see it as not found.
+func (s *MessageService) getParticipantMessage(ctx context.Context, id, userID uint) (*models.Message, error) {
+	message, err := s.getMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if message.ConversationID == nil {
+		return nil, ErrMessageNotFound
+	}
+	if _, err := s.getParticipant(ctx, *message.ConversationID, userID); err != nil {
+		if errors.Is(err, ErrConversationNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+func deletedEvent(message *models.Message) MessageDeletedEvent {
+	event := MessageDeletedEvent{MessageID: message.ID}
+	if message.ConversationID != nil {
+		event.ConversationID = *message.ConversationID
+	}
+	return event
+}
+
+// isEmoji accepts short strings made of emoji code points. ASCII is only
+// allowed for keycap sequences such as "1️⃣".
+func isEmoji(s string) bool {
+	if s == "" || len(s) > 32 {
+		return false
+	}
+	hasEmoji := false
+	for _, r := range s {
+		switch {
+		case r >= utf8.RuneSelf:
+			if unicode.IsLetter(r) || unicode.IsSpace(r) {
+				return false
+			}
+			hasEmoji = true
+		case r == '#' || r == '*' || (r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return hasEmoji
+}
+
+// directPeerID returns the other participant of a direct conversation.
+func directPeerID(conversation *models.Conversation, userID uint) uint {
+	for _, p := range conversation.Participants {
+		if p.UserID != userID {
+			return p.UserID
+		}
+	}
+	return 0
+}
+
+// uniqueIDs removes duplicates and exclude from ids, keeping their order.
+func uniqueIDs(ids []uint, exclude uint) []uint {
+	seen := map[uint]bool{exclude: true}
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
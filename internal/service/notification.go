@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/realtime"
+	"gorm.io/gorm"
+)
+
+// unreadCountTTL bounds how long a cached unread count may be served. The
+// cache is invalidated on every change, so this only matters if an
+// invalidation is lost.
+const unreadCountTTL = time.Hour
+
+// aggregationWindow is how long an unread aggregated notification keeps
+// absorbing new activity on its target.
+const aggregationWindow = 24 * time.Hour
+
+var ErrNotificationNotFound = fmt.Errorf("%w: notification not found", ErrNotFound)
+
+type NotificationService struct {
+	deps ServicesDeps
+}
+
+func NewNotificationService(deps ServicesDeps) *NotificationService {
+	return &NotificationService{deps: deps}
+}
+
+// Notice describes a notification to deliver to one or more users. The
+// notification type is taken from Data. When Action is set the message names
+// the actors followed by Action, e.g. "Alice and Bob reacted to your post",
+// and Message is ignored.
+type Notice struct {
+	ActorID uint
+	Title   string
+	Message string
+	Action  string
+	Link    string
+	Data    models.NotificationData
+}
+
+func unreadCountKey(userID uint) string { return fmt.Sprintf("notifications:unread:%d", userID) }
+
+// Notify delivers the notice to each user. Users never receive notifications
+// about their own actions.
+func (s *NotificationService) Notify(ctx context.Context, n Notice, userIDs ...uint) {
+	notify(ctx, s.deps, n, userIDs...)
+}
+
+// NotificationPreferences is a user's complete notification configuration.
+type NotificationPreferences struct {
+	Categories      []models.NotificationPreference `json:"categories"`
+	DoNotDisturb    *models.NotificationSettings    `json:"do_not_disturb"`
+	DigestFrequency string                          `json:"digest_frequency"`
+}
+
+// CategoryPreferenceInput changes the channels of one category. Omitted
+// channels keep their current setting.
+type CategoryPreferenceInput struct {
+	Category string `json:"category" binding:"required,oneof=comment reaction mention connection message endorsement skill group"`
+	InApp    *bool  `json:"in_app"`
+	Push     *bool  `json:"push"`
+	Email    *bool  `json:"email"`
+	Digest   *bool  `json:"digest"`
+}
+
+type DoNotDisturbInput struct {
+	Enabled  *bool  `json:"enabled"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+type UpdatePreferencesInput struct {
+	Categories      []CategoryPreferenceInput `json:"categories" binding:"dive"`
+	DoNotDisturb    *DoNotDisturbInput        `json:"do_not_disturb"`
+	DigestFrequency string                    `json:"digest_frequency" binding:"omitempty,oneof=off daily weekly"`
+}
+
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uint) (*NotificationPreferences, error) {
+	categories, err := s.deps.Repos.Notification.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.deps.Repos.Notification.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &NotificationPreferences{
+		Categories:      categories,
+		DoNotDisturb:    settings,
+		DigestFrequency: settings.DigestFrequency,
+	}, nil
+}
+
+// UpdatePreferences applies the changes and returns the resulting
+// configuration. Times are "HH:MM" and timezones IANA names.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uint, input UpdatePreferencesInput) (*NotificationPreferences, error) {
+	current, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dnd := input.DoNotDisturb; dnd != nil || input.DigestFrequency != "" {
+		settings := current.DoNotDisturb
+		if input.DigestFrequency != "" {
+			settings.DigestFrequency = input.DigestFrequency
+			current.DigestFrequency = input.DigestFrequency
+		}
+		if dnd == nil {
+			dnd = &DoNotDisturbInput{}
+		}
+		if dnd.Enabled != nil {
+			settings.DNDEnabled = *dnd.Enabled
+		}
+		if err := setClock(&settings.DNDStart, dnd.Start, "start"); err != nil {
+			return nil, err
+		}
+		if err := setClock(&settings.DNDEnd, dnd.End, "end"); err != nil {
+			return nil, err
+		}
+		if dnd.Timezone != "" {
+			if _, err := time.LoadLocation(dnd.Timezone); err != nil {
+				return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, dnd.Timezone)
+			}
+			settings.Timezone = dnd.Timezone
+		}
+		if err := s.deps.Repos.Notification.SaveSettings(ctx, settings); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(input.Categories) > 0 {
+		byCategory := make(map[string]*models.NotificationPreference, len(current.Categories))
+		for i := range current.Categories {
+			byCategory[current.Categories[i].Category] = &current.Categories[i]
+		}
+		changed := make([]models.NotificationPreference, 0, len(input.Categories))
+		for _, in := range input.Categories {
+			pref := byCategory[in.Category]
+			setIfPresent(&pref.InApp, in.InApp)
+			setIfPresent(&pref.Push, in.Push)
+			setIfPresent(&pref.Email, in.Email)
+			setIfPresent(&pref.Digest, in.Digest)
+			changed = append(changed, *pref)
+		}
+		if err := s.deps.Repos.Notification.SavePreferences(ctx, changed); err != nil {
+			return nil, err
+		}
+	}
+
+	return current, nil
+}
+
+// setClock validates an optional "HH:MM" time and stores it in dst.
+func setClock(dst *string, value, name string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.Parse("15:04", value); err != nil {
+		return fmt.Errorf("%w: %s must be HH:MM", ErrInvalidInput, name)
+	}
+	*dst = value
+	return nil
+}
+
+func setIfPresent(dst *bool, value *bool) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// List returns the user's notifications, newest first.
+func (s *NotificationService) List(ctx context.Context, userID uint, unreadOnly bool, page, limit int) ([]models.Notification, error) {
+	return s.deps.Repos.Notification.List(ctx, userID, unreadOnly, page, limit)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, id, userID uint) error {
+	n, err := s.deps.Repos.Notification.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && (n.UserID != userID || n.Hidden)) {
+		return ErrNotificationNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.deps.Repos.Notification.MarkRead(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		invalidateUnreadCount(ctx, s.deps, userID)
+	}
+	return nil
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uint) error {
+	updated, err := s.deps.Repos.Notification.MarkAllRead(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		invalidateUnreadCount(ctx, s.deps, userID)
+	}
+	return nil
+}
+
+// UnreadCount returns the number of unread notifications, served from Redis
+// when cached.
+func (s *NotificationService) UnreadCount(ctx context.Context, userID uint) (int64, error) {
+	key := unreadCountKey(userID)
+	cached, err := s.deps.Cache.Get(ctx, key).Int64()
+	if err == nil {
+		return cached, nil
+	}
+	if err != redis.Nil {
+		s.deps.Logger.Warn("Failed to read cached unread count", "user_id", userID, "error", err)
+	}
+
+	count, err := s.deps.Repos.Notification.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.deps.Cache.Set(ctx, key, count, unreadCountTTL).Err(); err != nil {
+		s.deps.Logger.Warn("Failed to cache unread count", "user_id", userID, "error", err)
+	}
+	return count, nil
+}
+
+// notify stores the notice for each recipient and delivers it on the
+// channels their preferences enable for its category. The actor is never
+// notified, and neither are users who blocked the actor or were blocked by
+// them. The push channel covers both the live WebSocket and Web Push to the
+// recipient's browsers; during do-not-disturb hours neither is used.
+//
+// Notices with aggregatable data are folded into the recipient's unread
+// notification for the same target, if one was created within
+// aggregationWindow, instead of adding a row. Once that notification is
+// read, new activity starts a new one.
+//
+// Delivery is best effort: failures are logged and never fail the calling
+// operation.
+func notify(ctx context.Context, deps ServicesDeps, n Notice, userIDs ...uint) {
+	notificationType := n.Data.NotificationType()
+	data, err := models.EncodeNotificationData(n.Data)
+	if err != nil {
+		deps.Logger.Error("Failed to encode notification data", "type", notificationType, "error", err)
+		return
+	}
+
+	var actorID *uint
+	var actorIDs models.IDList
+	if n.ActorID != 0 {
+		actorID = &n.ActorID
+		actorIDs = models.IDList{n.ActorID}
+	}
+	var groupKey string
+	if agg, ok := n.Data.(models.AggregatableData); ok && n.ActorID != 0 {
+		groupKey = notificationType + ":" + agg.AggregationKey()
+	}
+
+	recipients := uniqueIDs(userIDs, n.ActorID)
+	if len(recipients) == 0 {
+		return
+	}
+	category := models.NotificationCategory(notificationType)
+	preferences, err := deps.Repos.Notification.GetPreferencesFor(ctx, recipients, category)
+	if err != nil {
+		deps.Logger.Error("Failed to load notification preferences", "type", notificationType, "error", err)
+		return
+	}
+	settings, err := deps.Repos.Notification.GetSettingsFor(ctx, recipients)
+	if err != nil {
+		deps.Logger.Error("Failed to load notification settings", "type", notificationType, "error", err)
+		return
+	}
+	now := time.Now()
+	message := n.Message
+	if n.Action != "" {
+		message = actorPhrase(ctx, deps, actorIDs) + " " + n.Action
+	}
+
+	for _, userID := range recipients {
+		pref := preferences[userID]
+		if !pref.Enabled() {
+			continue
+		}
+		if n.ActorID != 0 {
+			blocked, err := deps.Repos.Connection.IsBlockedEitherWay(ctx, n.ActorID, userID)
+			if err != nil {
+				deps.Logger.Error("Failed to check block", "user_id", userID, "error", err)
+				continue
+			}
+			if blocked {
+				continue
+			}
+		}
+		push := pref.Push && !settings[userID].InQuietHours(now)
+
+		if groupKey != "" {
+			merged, changed, err := mergeNotice(ctx, deps, userID, groupKey, n, data, now)
+			if err != nil {
+				deps.Logger.Error("Failed to aggregate notification", "type", notificationType, "user_id", userID, "error", err)
+				continue
+			}
+			if merged != nil {
+				if changed && push {
+					publish(ctx, deps, realtime.Event{Type: realtime.EventNotificationUpdated, Data: merged}, userID)
+					sendWebPush(ctx, deps, userID, merged)
+				}
+				continue
+			}
+		}
+
+		created := &models.Notification{
+			UserID:   userID,
+			ActorID:  actorID,
+			ActorIDs: actorIDs,
+			Type:     notificationType,
+			GroupKey: groupKey,
+			Title:    n.Title,
+			Message:  message,
+			Link:     n.Link,
+			Data:     data,
+			Hidden:   !pref.InApp,
+			Email:    pref.Email,
+			Digest:   pref.Digest,
+		}
+		if err := deps.Repos.Notification.Create(ctx, created); err != nil {
+			deps.Logger.Error("Failed to create notification", "type", notificationType, "user_id", userID, "error", err)
+			continue
+		}
+		if pref.InApp {
+			invalidateUnreadCount(ctx, deps, userID)
+		}
+		if push {
+			publish(ctx, deps, realtime.Event{Type: realtime.EventNotificationCreated, Data: created}, userID)
+			sendWebPush(ctx, deps, userID, created)
+		}
+	}
+}
+
+// mergeNotice folds the notice into the user's open aggregated notification
+// for groupKey. It returns nil when there is none; changed is false when the
+// actor was already part of it.
+func mergeNotice(ctx context.Context, deps ServicesDeps, userID uint, groupKey string, n Notice, data models.NotificationPayload, now time.Time) (*models.Notification, bool, error) {
+	changed := false
+	merged, err := deps.Repos.Notification.Merge(ctx, userID, groupKey, now.Add(-aggregationWindow), func(existing *models.Notification) bool {
+		if existing.ActorIDs.Contains(n.ActorID) {
+			return false
+		}
+		existing.ActorIDs = append(models.IDList{n.ActorID}, existing.ActorIDs...)
+		existing.ActorID = &n.ActorID
+		existing.Data = data
+		existing.Link = n.Link
+		if n.Action != "" {
+			existing.Message = actorPhrase(ctx, deps, existing.ActorIDs) + " " + n.Action
+		}
+		changed = true
+		return true
+	})
+	return merged, changed, err
+}
+
+// actorPhrase names the first two actors and counts the rest: "Alice",
+// "Alice and Bob", "Alice, Bob and 3 others".
+func actorPhrase(ctx context.Context, deps ServicesDeps, actorIDs []uint) string {
+	shown := actorIDs
+	if len(shown) > 2 {
+		shown = shown[:2]
+	}
+	names := make(map[uint]string, len(shown))
+	users, err := deps.Repos.User.GetByIDs(ctx, shown)
+	if err != nil {
+		deps.Logger.Warn("Failed to load notification actors", "error", err)
+	}
+	for i := range users {
+		names[users[i].ID] = displayName(&users[i])
+	}
+	parts := make([]string, 0, len(shown))
+	for _, id := range shown {
+		name := names[id]
+		if name == "" {
+			name = "Someone"
+		}
+		parts = append(parts, name)
+	}
+
+	switch others := len(actorIDs) - len(shown); {
+	case len(parts) == 0:
+		return "Someone"
+	case len(parts) == 1:
+		return parts[0]
+	case others == 0:
+		return parts[0] + " and " + parts[1]
+	case others == 1:
+		return parts[0] + ", " + parts[1] + " and 1 other"
+	default:
+		return fmt.Sprintf("%s, %s and %d others", parts[0], parts[1], others)
+	}
+}
+
+func invalidateUnreadCount(ctx context.Context, deps ServicesDeps, userID uint) {
+	if err := deps.Cache.Del(ctx, unreadCountKey(userID)).Err(); err != nil {
+		deps.Logger.Warn("Failed to invalidate unread count", "user_id", userID, "error", err)
+	}
+}
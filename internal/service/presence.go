@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/vern/skillflow/internal/domain/models"
+)
+
+// Presence statuses.
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+const (
+	// presenceTTL is how long a connection counts as live without a
+	// heartbeat. WebSocket pongs arrive roughly every minute.
+	presenceTTL = 90 * time.Second
+	// maxPresenceBatch caps the number of users per presence lookup.
+	maxPresenceBatch = 100
+)
+
+// PresenceService tracks which users are connected. Every live WebSocket
+// connection is a member of a per-user sorted set in Redis, scored by the
+// time it expires unless refreshed by a heartbeat, so connections on crashed
+// replicas age out on their own.
+type PresenceService struct {
+	deps ServicesDeps
+}
+
+func NewPresenceService(deps ServicesDeps) *PresenceService {
+	return &PresenceService{deps: deps}
+}
+
+// Presence is a user's status as seen by the viewer. LastSeenAt is omitted
+// while the user is online or when their privacy settings hide it.
+type Presence struct {
+	UserID     uint       `json:"user_id"`
+	Status     string     `json:"status"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+func presenceConnsKey(userID uint) string { return fmt.Sprintf("presence:conns:%d", userID) }
+func presenceAwayKey(userID uint) string  { return fmt.Sprintf("presence:away:%d", userID) }
+
+// Connect registers a new connection. A fresh connection means the user is
+// active again, so any away status is cleared.
+func (s *PresenceService) Connect(ctx context.Context, userID uint, connID string) error {
+	pipe := s.deps.Cache.TxPipeline()
+	s.touch(ctx, pipe, userID, connID)
+	pipe.Del(ctx, presenceAwayKey(userID))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Heartbeat extends the connection's lifetime, and the away status along
+// with it.
+func (s *PresenceService) Heartbeat(ctx context.Context, userID uint, connID string) error {
+	pipe := s.deps.Cache.TxPipeline()
+	s.touch(ctx, pipe, userID, connID)
+	pipe.Expire(ctx, presenceAwayKey(userID), presenceTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Disconnect removes the connection. When it was the user's last live one,
+// their last-seen time is recorded and any away status cleared.
+func (s *PresenceService) Disconnect(ctx context.Context, userID uint, connID string) error {
+	key := presenceConnsKey(userID)
+	now := time.Now()
+	pipe := s.deps.Cache.TxPipeline()
+	pipe.ZRem(ctx, key, connID)
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Unix(), 10))
+	live := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if live.Val() > 0 {
+		return nil
+	}
+	if err := s.deps.Cache.Del(ctx, presenceAwayKey(userID)).Err(); err != nil {
+		return err
+	}
+	return s.deps.Repos.User.UpdateLastSeen(ctx, userID, now)
+}
+
+// SetStatus switches a connected user between online and away, e.g. when
+// their client goes idle.
+func (s *PresenceService) SetStatus(ctx context.Context, userID uint, status string) error {
+	switch status {
+	case PresenceOnline:
+		return s.deps.Cache.Del(ctx, presenceAwayKey(userID)).Err()
+	case PresenceAway:
+		// Expires with the connections unless heartbeats keep it alive.
+		return s.deps.Cache.Set(ctx, presenceAwayKey(userID), 1, presenceTTL).Err()
+	default:
+		return fmt.Errorf("%w: status must be online or away", ErrInvalidInput)
+	}
+}
+
+// GetPresence returns the presence of each user, in the order given.
+func (s *PresenceService) GetPresence(ctx context.Context, viewerID uint, userIDs []uint) ([]Presence, error) {
+	userIDs = uniqueIDs(userIDs, 0)
+	if len(userIDs) > maxPresenceBatch {
+		return nil, fmt.Errorf("%w: at most %d users per request", ErrInvalidInput, maxPresenceBatch)
+	}
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	pipe := s.deps.Cache.Pipeline()
+	live := make([]*redis.IntCmd, len(userIDs))
+	away := make([]*redis.IntCmd, len(userIDs))
+	for i, id := range userIDs {
+		live[i] = pipe.ZCount(ctx, presenceConnsKey(id), now, "+inf")
+		away[i] = pipe.Exists(ctx, presenceAwayKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	visible, err := s.lastSeenVisible(ctx, viewerID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.deps.Repos.User.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	lastSeen := make(map[uint]*time.Time, len(users))
+	for _, u := range users {
+		lastSeen[u.ID] = u.LastSeenAt
+	}
+
+	presences := make([]Presence, 0, len(userIDs))
+	for i, id := range userIDs {
+		p := Presence{UserID: id, Status: PresenceOffline}
+		switch {
+		case live[i].Val() > 0 && away[i].Val() > 0:
+			p.Status = PresenceAway
+		case live[i].Val() > 0:
+			p.Status = PresenceOnline
+		case visible[id]:
+			p.LastSeenAt = lastSeen[id]
+		}
+		presences = append(presences, p)
+	}
+	return presences, nil
+}
+
+// lastSeenVisible reports, per user, whether their last-seen time may be
+// shown to the viewer.
+func (s *PresenceService) lastSeenVisible(ctx context.Context, viewerID uint, userIDs []uint) (map[uint]bool, error) {
+	settings, err := s.deps.Repos.UserSettings.GetByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make(map[uint]bool, len(userIDs))
+	var connectionsOnly []uint
+	for _, id := range userIDs {
+		switch {
+		case id == viewerID:
+			visible[id] = true
+		case settings[id].LastSeenVisibility == models.LastSeenEveryone:
+			visible[id] = true
+		case settings[id].LastSeenVisibility == models.LastSeenConnections:
+			connectionsOnly = append(connectionsOnly, id)
+		}
+	}
+
+	connected, err := s.deps.Repos.Connection.FilterConnected(ctx, viewerID, connectionsOnly)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range connected {
+		visible[id] = true
+	}
+	return visible, nil
+}
+
+// touch adds or refreshes the connection and drops connections whose
+// heartbeats stopped.
+func (s *PresenceService) touch(ctx context.Context, pipe redis.Pipeliner, userID uint, connID string) {
+	key := presenceConnsKey(userID)
+	now := time.Now()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.Add(presenceTTL).Unix()), Member: connID})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Unix(), 10))
+	pipe.Expire(ctx, key, presenceTTL)
+}
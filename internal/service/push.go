@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/pkg/webpush"
+)
+
+// webPushTimeout bounds the delivery of one notification to all of a user's
+// browsers.
+const webPushTimeout = 30 * time.Second
+
+var (
+	ErrPushUnavailable          = fmt.Errorf("%w: web push is not configured", ErrNotFound)
+	ErrPushSubscriptionNotFound = fmt.Errorf("%w: push subscription not found", ErrNotFound)
+)
+
+// PushSubscriptionInput is the JSON form of a browser PushSubscription.
+type PushSubscriptionInput struct {
+	Endpoint string        `json:"endpoint" binding:"required,url"`
+	Keys     PushKeysInput `json:"keys"`
+}
+
+type PushKeysInput struct {
+	P256dh string `json:"p256dh" binding:"required"`
+	Auth   string `json:"auth" binding:"required"`
+}
+
+// webPushPayload is what the service worker receives for a notification.
+// Tag lets it replace the desktop notification when an aggregated
+// notification is updated.
+type webPushPayload struct {
+	ID    uint   `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+	Tag   string `json:"tag"`
+}
+
+// PushPublicKey returns the VAPID key browsers subscribe with.
+func (s *NotificationService) PushPublicKey() (string, error) {
+	if s.deps.WebPush == nil {
+		return "", ErrPushUnavailable
+	}
+	return s.deps.WebPush.PublicKey(), nil
+}
+
+// Subscribe registers a browser for Web Push. Subscribing the same endpoint
+// again refreshes its keys.
+func (s *NotificationService) Subscribe(ctx context.Context, userID uint, input PushSubscriptionInput, userAgent string) (*models.PushSubscription, error) {
+	if s.deps.WebPush == nil {
+		return nil, ErrPushUnavailable
+	}
+	sub := webpush.Subscription{Endpoint: input.Endpoint, P256dh: input.Keys.P256dh, Auth: input.Keys.Auth}
+	if err := sub.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	subscription := &models.PushSubscription{
+		UserID:    userID,
+		Endpoint:  input.Endpoint,
+		P256dh:    input.Keys.P256dh,
+		Auth:      input.Keys.Auth,
+		UserAgent: userAgent,
+	}
+	if err := s.deps.Repos.Notification.SavePushSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *NotificationService) ListSubscriptions(ctx context.Context, userID uint) ([]models.PushSubscription, error) {
+	return s.deps.Repos.Notification.ListPushSubscriptions(ctx, userID)
+}
+
+func (s *NotificationService) Unsubscribe(ctx context.Context, userID, id uint) error {
+	deleted, err := s.deps.Repos.Notification.DeletePushSubscription(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrPushSubscriptionNotFound
+	}
+	return nil
+}
+
+// sendWebPush delivers the notification to the user's browsers in the
+// background. Users with an active WebSocket connection already received it
+// live and are skipped. Subscriptions the push service reports as expired
+// (404 or 410) are deleted.
+func sendWebPush(ctx context.Context, deps ServicesDeps, userID uint, n *models.Notification) {
+	if deps.WebPush == nil {
+		return
+	}
+	body := n.Message
+	if body == "" {
+		body = n.Title
+	}
+	payload, err := json.Marshal(webPushPayload{
+		ID:    n.ID,
+		Type:  n.Type,
+		Title: n.Title,
+		Body:  body,
+		URL:   n.Link,
+		Tag:   fmt.Sprintf("notification-%d", n.ID),
+	})
+	if err != nil {
+		deps.Logger.Error("Failed to encode web push payload", "notification_id", n.ID, "error", err)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webPushTimeout)
+		defer cancel()
+
+		if isOnline(ctx, deps, userID) {
+			return
+		}
+		subscriptions, err := deps.Repos.Notification.ListPushSubscriptions(ctx, userID)
+		if err != nil {
+			deps.Logger.Error("Failed to load push subscriptions", "user_id", userID, "error", err)
+			return
+		}
+		for _, sub := range subscriptions {
+			err := deps.WebPush.Send(ctx, &webpush.Subscription{
+				Endpoint: sub.Endpoint,
+				P256dh:   sub.P256dh,
+				Auth:     sub.Auth,
+			}, payload, webpush.Options{Topic: fmt.Sprintf("n%d", n.ID)})
+			switch {
+			case errors.Is(err, webpush.ErrGone):
+				if err := deps.Repos.Notification.DeletePushSubscriptionByEndpoint(ctx, sub.Endpoint); err != nil {
+					deps.Logger.Warn("Failed to delete expired push subscription", "subscription_id", sub.ID, "error", err)
+				}
+			case err != nil:
+				deps.Logger.Warn("Failed to send web push", "subscription_id", sub.ID, "error", err)
+			default:
+				if err := deps.Repos.Notification.TouchPushSubscription(ctx, sub.ID, time.Now()); err != nil {
+					deps.Logger.Warn("Failed to update push subscription", "subscription_id", sub.ID, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// isOnline reports whether the user has a live connection that is not away.
+func isOnline(ctx context.Context, deps ServicesDeps, userID uint) bool {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	pipe := deps.Cache.Pipeline()
+	live := pipe.ZCount(ctx, presenceConnsKey(userID), now, "+inf")
+	away := pipe.Exists(ctx, presenceAwayKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		deps.Logger.Warn("Failed to read presence", "user_id", userID, "error", err)
+		return false
+	}
+	return live.Val() > 0 && away.Val() == 0
+}
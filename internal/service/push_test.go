@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/vern/skillflow/internal/config"
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+	"github.com/vern/skillflow/pkg/logger"
+	"github.com/vern/skillflow/pkg/webpush"
+)
+
+// fakePushRepository records what sendWebPush does with the user's
+// subscriptions. Other notification repository methods are not used.
+type fakePushRepository struct {
+	repository.NotificationRepositoryInterface
+	subscriptions []models.PushSubscription
+	deleted       chan string
+	touched       chan uint
+}
+
+func (r *fakePushRepository) ListPushSubscriptions(ctx context.Context, userID uint) ([]models.PushSubscription, error) {
+	return r.subscriptions, nil
+}
+
+func (r *fakePushRepository) DeletePushSubscriptionByEndpoint(ctx context.Context, endpoint string) error {
+	r.deleted <- endpoint
+	return nil
+}
+
+func (r *fakePushRepository) TouchPushSubscription(ctx context.Context, id uint, at time.Time) error {
+	r.touched <- id
+	return nil
+}
+
+func newPushSubscription(t *testing.T, id uint, endpoint string) models.PushSubscription {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatalf("generate auth secret: %v", err)
+	}
+	return models.PushSubscription{
+		ID:       id,
+		UserID:   1,
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}
+}
+
+func TestSendWebPushDeletesGoneSubscriptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/unknown":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKeys: %v", err)
+	}
+	client, err := webpush.New(config.WebPushConfig{PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:ops@example.com"})
+	if err != nil {
+		t.Fatalf("webpush.New: %v", err)
+	}
+
+	repo := &fakePushRepository{
+		subscriptions: []models.PushSubscription{
+			newPushSubscription(t, 1, server.URL+"/gone"),
+			newPushSubscription(t, 2, server.URL+"/ok"),
+			newPushSubscription(t, 3, server.URL+"/unknown"),
+		},
+		deleted: make(chan string, 3),
+		touched: make(chan uint, 3),
+	}
+	// No Redis is listening, so the user counts as offline.
+	cache := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer cache.Close()
+	deps := ServicesDeps{
+		Repos:   &repository.Repositories{Notification: repo},
+		Cache:   cache,
+		Logger:  logger.New(),
+		WebPush: client.WithHTTPClient(server.Client()),
+	}
+
+	sendWebPush(context.Background(), deps, 1, &models.Notification{ID: 7, Type: models.NotificationTypeMention, Title: "Mentioned"})
+
+	deleted := make(map[string]bool)
+	for range 2 {
+		select {
+		case endpoint := <-repo.deleted:
+			deleted[endpoint] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expired subscriptions not deleted, got %v", deleted)
+		}
+	}
+	if !deleted[server.URL+"/gone"] || !deleted[server.URL+"/unknown"] {
+		t.Errorf("deleted %v, want the 410 and 404 endpoints", deleted)
+	}
+
+	select {
+	case id := <-repo.touched:
+		if id != 2 {
+			t.Errorf("touched subscription %d, want 2", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivered subscription not touched")
+	}
+	select {
+	case endpoint := <-repo.deleted:
+		t.Errorf("deleted live subscription %s", endpoint)
+	default:
+	}
+}
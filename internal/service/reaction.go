@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+)
+
+var ErrReactionNotFound = fmt.Errorf("%w: reaction not found", ErrNotFound)
+
+type ReactionService struct {
+	deps ServicesDeps
+}
+
+func NewReactionService(deps ServicesDeps) *ReactionService {
+	return &ReactionService{deps: deps}
+}
+
+type ReactInput struct {
+	Type string `json:"type" binding:"required,oneof=like love celebrate support insightful"`
+}
+
+// ReactionSummary lists the reactions on a target with per-type totals.
+type ReactionSummary struct {
+	Counts    map[string]int    `json:"counts"`
+	Reactions []models.Reaction `json:"reactions"`
+}
+
+// ReactToPost sets the user's reaction on a post, replacing any earlier one.
+// The author is notified only of new reactions, not of changed ones.
+func (s *ReactionService) ReactToPost(ctx context.Context, postID, userID uint, input ReactInput) (*models.Reaction, error) {
+	post, err := getReadablePost(ctx, s.deps, postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reaction, created, err := s.deps.Repos.Reaction.Upsert(ctx, userID, repository.ReactionTarget{PostID: &post.ID}, input.Type)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		notify(ctx, s.deps, Notice{
+			ActorID: userID,
+			Title:   "New reaction",
+			Action:  "reacted to your post",
+			Link:    fmt.Sprintf("/posts/%d", post.ID),
+			Data:    models.ReactionData{PostID: &post.ID, Reaction: input.Type},
+		}, post.UserID)
+	}
+	return reaction, nil
+}
+
+// ReactToComment sets the user's reaction on a comment, replacing any
+// earlier one.
+func (s *ReactionService) ReactToComment(ctx context.Context, commentID, userID uint, input ReactInput) (*models.Reaction, error) {
+	comment, err := getComment(ctx, s.deps, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getReadablePost(ctx, s.deps, comment.PostID, userID); err != nil {
+		return nil, err
+	}
+
+	reaction, created, err := s.deps.Repos.Reaction.Upsert(ctx, userID, repository.ReactionTarget{CommentID: &comment.ID}, input.Type)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		notify(ctx, s.deps, Notice{
+			ActorID: userID,
+			Title:   "New reaction",
+			Action:  "reacted to your comment",
+			Link:    commentLink(comment),
+			Data:    models.ReactionData{CommentID: &comment.ID, Reaction: input.Type},
+		}, comment.UserID)
+	}
+	return reaction, nil
+}
+
+func (s *ReactionService) RemoveFromPost(ctx context.Context, postID, userID uint) error {
+	return s.remove(ctx, userID, repository.ReactionTarget{PostID: &postID})
+}
+
+func (s *ReactionService) RemoveFromComment(ctx context.Context, commentID, userID uint) error {
+	return s.remove(ctx, userID, repository.ReactionTarget{CommentID: &commentID})
+}
+
+func (s *ReactionService) ListForPost(ctx context.Context, postID, viewerID uint) (*ReactionSummary, error) {
+	if _, err := getReadablePost(ctx, s.deps, postID, viewerID); err != nil {
+		return nil, err
+	}
+	reactions, err := s.deps.Repos.Reaction.ListByTarget(ctx, repository.ReactionTarget{PostID: &postID})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ReactionSummary{Counts: make(map[string]int), Reactions: reactions}
+	for _, r := range reactions {
+		summary.Counts[r.Type]++
+	}
+	return summary, nil
+}
+
+func (s *ReactionService) remove(ctx context.Context, userID uint, target repository.ReactionTarget) error {
+	removed, err := s.deps.Repos.Reaction.Delete(ctx, userID, target)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrReactionNotFound
+	}
+	return nil
+}
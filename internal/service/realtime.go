@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vern/skillflow/internal/realtime"
+)
+
+// publish pushes the event to the users' live connections. Delivery is best
+// effort: without a configured publisher it is a no-op, and failures are
+// logged and never fail the calling operation.
+func publish(ctx context.Context, deps ServicesDeps, event realtime.Event, userIDs ...uint) {
+	if deps.Realtime == nil || len(userIDs) == 0 {
+		return
+	}
+	if err := deps.Realtime.Publish(ctx, userIDs, event); err != nil {
+		deps.Logger.Error("Failed to publish realtime event", "type", event.Type, "error", err)
+	}
+}
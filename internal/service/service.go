@@ -1,10 +1,24 @@
 package service
 
 import (
+	"errors"
+
 	"github.com/redis/go-redis/v9"
 	"github.com/vern/skillflow/internal/config"
+	"github.com/vern/skillflow/internal/realtime"
 	"github.com/vern/skillflow/internal/repository"
 	"github.com/vern/skillflow/pkg/logger"
+	"github.com/vern/skillflow/pkg/mailer"
+	"github.com/vern/skillflow/pkg/webpush"
+)
+
+// Sentinel errors returned by services. Handlers map them to HTTP status
+// codes; more specific errors wrap one of these with fmt.Errorf("%w: ...").
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrInvalidInput = errors.New("invalid input")
 )
 
 type Services struct {
@@ -15,17 +29,25 @@ type Services struct {
 	Reaction     *ReactionService
 	Connection   *ConnectionService
 	Notification *NotificationService
+	Email        *EmailService
 	Message      *MessageService
+	Presence     *PresenceService
 	Group        *GroupService
+	Event        *EventService
 	Skill        *SkillService
+	Goal         *GoalService
+	Mentorship   *MentorshipService
 	File         *FileService
 }
 
 type ServicesDeps struct {
-	Repos  *repository.Repositories
-	Cache  *redis.Client
-	Config *config.Config
-	Logger *logger.Logger
+	Repos    *repository.Repositories
+	Cache    *redis.Client
+	Config   *config.Config
+	Logger   *logger.Logger
+	Realtime realtime.Publisher
+	Mailer   mailer.Mailer
+	WebPush  *webpush.Client // nil when Web Push is not configured
 }
 
 func NewServices(deps ServicesDeps) *Services {
@@ -37,9 +59,14 @@ func NewServices(deps ServicesDeps) *Services {
 		Reaction:     NewReactionService(deps),
 		Connection:   NewConnectionService(deps),
 		Notification: NewNotificationService(deps),
+		Email:        NewEmailService(deps),
 		Message:      NewMessageService(deps),
+		Presence:     NewPresenceService(deps),
 		Group:        NewGroupService(deps),
+		Event:        NewEventService(deps),
 		Skill:        NewSkillService(deps),
+		Goal:         NewGoalService(deps),
+		Mentorship:   NewMentorshipService(deps),
 		File:         NewFileService(deps),
 	}
 }
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSkillNotFound     = fmt.Errorf("%w: skill not found", ErrNotFound)
+	ErrSkillExists       = fmt.Errorf("%w: skill already exists", ErrConflict)
+	ErrUserSkillNotFound = fmt.Errorf("%w: user skill not found", ErrNotFound)
+	ErrUserSkillExists   = fmt.Errorf("%w: skill is already on your profile", ErrConflict)
+	ErrAlreadyEndorsed   = fmt.Errorf("%w: you already endorsed this skill", ErrConflict)
+)
+
+type SkillService struct {
+	deps ServicesDeps
+}
+
+func NewSkillService(deps ServicesDeps) *SkillService {
+	return &SkillService{deps: deps}
+}
+
+type ListSkillsInput struct {
+	Category   string
+	CategoryID uint
+	ParentID   *uint
+	Query      string
+	Page       int
+	Limit      int
+}
+
+// CreateSkillInput adds a catalog skill. The category is given either by ID
+// or by name; an unknown category name creates the category.
+type CreateSkillInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Category    string `json:"category" binding:"max=100"`
+	CategoryID  *uint  `json:"category_id"`
+	ParentID    *uint  `json:"parent_id"`
+	Description string `json:"description" binding:"max=2000"`
+}
+
+type AddUserSkillInput struct {
+	UserID     uint   `json:"-"`
+	SkillID    uint   `json:"skill_id" binding:"required"`
+	Level      string `json:"level" binding:"required,oneof=beginner intermediate advanced expert"`
+	YearsOfExp int    `json:"years_of_experience" binding:"min=0,max=80"`
+}
+
+// UpdateUserSkillInput changes a user skill; omitted fields are unchanged.
+type UpdateUserSkillInput struct {
+	Level      string `json:"level" binding:"omitempty,oneof=beginner intermediate advanced expert"`
+	YearsOfExp *int   `json:"years_of_experience" binding:"omitempty,min=0,max=80"`
+}
+
+type EndorseSkillInput struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+// List returns the skill catalog, optionally filtered by category and a
+// search query.
+func (s *SkillService) List(ctx context.Context, input ListSkillsInput) ([]models.Skill, error) {
+	filter := repository.SkillFilter{
+		Category:   strings.TrimSpace(input.Category),
+		CategoryID: input.CategoryID,
+		ParentID:   input.ParentID,
+		Query:      strings.TrimSpace(input.Query),
+	}
+	return s.deps.Repos.Skill.List(ctx, filter, input.Page, input.Limit)
+}
+
+// Create adds a skill to the catalog. Names are unique ignoring case and
+// must not be a synonym of another skill.
+func (s *SkillService) Create(ctx context.Context, input CreateSkillInput) (*models.Skill, error) {
+	name := strings.Join(strings.Fields(input.Name), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if err := s.checkNameFree(ctx, name, 0); err != nil {
+		return nil, err
+	}
+
+	skill := &models.Skill{
+		Name:        name,
+		Description: strings.TrimSpace(input.Description),
+	}
+	category, err := s.resolveCategory(ctx, input.CategoryID, input.Category)
+	if err != nil {
+		return nil, err
+	}
+	if category != nil {
+		skill.CategoryID = &category.ID
+		skill.Category = category.Name
+	}
+	if input.ParentID != nil && *input.ParentID != 0 {
+		if _, err := s.getSkill(ctx, *input.ParentID); err != nil {
+			return nil, err
+		}
+		skill.ParentID = input.ParentID
+	}
+	if err := s.deps.Repos.Skill.Create(ctx, skill); err != nil {
+		return nil, err
+	}
+	return skill, nil
+}
+
+// ListForUser returns a user's skills with their visible, weighted
+// endorsements and whether their levels are verified. Users blocking or
+// blocked by the viewer are not found, and their endorsements are left out.
+func (s *SkillService) ListForUser(ctx context.Context, userID, viewerID uint) ([]models.UserSkill, error) {
+	if _, err := s.deps.Repos.User.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user not found", ErrNotFound)
+		}
+		return nil, err
+	}
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, fmt.Errorf("%w: user not found", ErrNotFound)
+	}
+	userSkills, err := s.deps.Repos.UserSkill.ListByUser(ctx, userID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setEndorsementWeights(ctx, userSkills); err != nil {
+		return nil, err
+	}
+	setVerification(userSkills)
+	return userSkills, nil
+}
+
+// AddUserSkill adds a catalog skill to the user's profile.
+func (s *SkillService) AddUserSkill(ctx context.Context, input AddUserSkillInput) (*models.UserSkill, error) {
+	skill, err := s.deps.Repos.Skill.GetByID(ctx, input.SkillID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSkillNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.deps.Repos.UserSkill.GetByUserAndSkill(ctx, input.UserID, input.SkillID)
+	if err == nil {
+		return nil, ErrUserSkillExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	userSkill := &models.UserSkill{
+		UserID:     input.UserID,
+		SkillID:    input.SkillID,
+		Level:      input.Level,
+		YearsOfExp: input.YearsOfExp,
+	}
+	if err := s.deps.Repos.UserSkill.Create(ctx, userSkill); err != nil {
+		return nil, err
+	}
+	userSkill.Skill = skill
+	userSkill.Verification = models.VerificationSelfDeclared
+	return userSkill, nil
+}
+
+// UpdateUserSkill changes the level or experience of one of the user's
+// skills. The verified level, if any, is unaffected.
+func (s *SkillService) UpdateUserSkill(ctx context.Context, id, userID uint, input UpdateUserSkillInput) (*models.UserSkill, error) {
+	userSkill, err := s.getOwnUserSkill(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Level != "" {
+		userSkill.Level = input.Level
+	}
+	if input.YearsOfExp != nil {
+		userSkill.YearsOfExp = *input.YearsOfExp
+	}
+	if err := s.deps.Repos.UserSkill.Update(ctx, userSkill); err != nil {
+		return nil, err
+	}
+	userSkill.Verification = userSkill.VerificationStatus(time.Now())
+	return userSkill, nil
+}
+
+// RemoveUserSkill removes one of the user's skills with its endorsements
+// and assessments.
+func (s *SkillService) RemoveUserSkill(ctx context.Context, id, userID uint) error {
+	userSkill, err := s.getOwnUserSkill(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	return s.deps.Repos.UserSkill.Delete(ctx, userSkill.ID)
+}
+
+// Endorse vouches for another user's skill. Users cannot endorse their own
+// skills, endorse a skill twice, or endorse users they blocked or were
+// blocked by. When skills.endorse_connections_only is set, only connections
+// may endorse.
+func (s *SkillService) Endorse(ctx context.Context, userSkillID, endorserID uint, input EndorseSkillInput) (*models.Endorsement, error) {
+	userSkill, err := getUserSkill(ctx, s.deps, userSkillID)
+	if err != nil {
+		return nil, err
+	}
+	if userSkill.UserID == endorserID {
+		return nil, fmt.Errorf("%w: cannot endorse your own skill", ErrInvalidInput)
+	}
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, endorserID, userSkill.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserSkillNotFound
+	}
+	if s.deps.Config.Skills.EndorseConnectionsOnly {
+		connected, err := s.deps.Repos.Connection.AreConnected(ctx, endorserID, userSkill.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !connected {
+			return nil, fmt.Errorf("%w: only connections can endorse skills", ErrForbidden)
+		}
+	}
+	endorsed, err := s.deps.Repos.Endorsement.Exists(ctx, userSkill.ID, endorserID)
+	if err != nil {
+		return nil, err
+	}
+	if endorsed {
+		return nil, ErrAlreadyEndorsed
+	}
+
+	endorsement := &models.Endorsement{
+		UserSkillID: userSkill.ID,
+		EndorserID:  endorserID,
+		Comment:     strings.TrimSpace(input.Comment),
+	}
+	if err := s.deps.Repos.Endorsement.Create(ctx, endorsement); err != nil {
+		return nil, err
+	}
+
+	skillName := "a skill"
+	if userSkill.Skill != nil {
+		skillName = userSkill.Skill.Name
+	}
+	notify(ctx, s.deps, Notice{
+		ActorID: endorserID,
+		Title:   "Skill endorsed",
+		Action:  fmt.Sprintf("endorsed you for %s", skillName),
+		Link:    fmt.Sprintf("/users/%d#skills", userSkill.UserID),
+		Data: models.SkillEndorsedData{
+			UserSkillID:   userSkill.ID,
+			SkillID:       userSkill.SkillID,
+			EndorsementID: endorsement.ID,
+		},
+	}, userSkill.UserID)
+	return endorsement, nil
+}
+
+func (s *SkillService) getOwnUserSkill(ctx context.Context, id, userID uint) (*models.UserSkill, error) {
+	userSkill, err := getUserSkill(ctx, s.deps, id)
+	if err != nil {
+		return nil, err
+	}
+	if userSkill.UserID != userID {
+		return nil, fmt.Errorf("%w: not your skill", ErrForbidden)
+	}
+	return userSkill, nil
+}
+
+func getUserSkill(ctx context.Context, deps ServicesDeps, id uint) (*models.UserSkill, error) {
+	userSkill, err := deps.Repos.UserSkill.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserSkillNotFound
+	}
+	return userSkill, err
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSkillAssessorNotFound = fmt.Errorf("%w: skill assessor not found", ErrNotFound)
+	ErrSkillAssessorExists   = fmt.Errorf("%w: user is already an assessor for this scope", ErrConflict)
+)
+
+// GrantAssessorInput makes a user an assessor of one skill, of the skills of
+// one category, or of every skill when neither is given.
+type GrantAssessorInput struct {
+	UserID     uint  `json:"user_id" binding:"required"`
+	SkillID    *uint `json:"skill_id"`
+	CategoryID *uint `json:"category_id"`
+}
+
+// AssessSkillInput verifies a user skill. AssessedAt defaults to now; without
+// ExpiresAt the verification lasts skills.verification_validity_days.
+type AssessSkillInput struct {
+	Level       string     `json:"level" binding:"required,oneof=beginner intermediate advanced expert"`
+	Evidence    string     `json:"evidence" binding:"required,max=2000"`
+	EvidenceURL string     `json:"evidence_url" binding:"omitempty,url,max=500"`
+	AssessedAt  *time.Time `json:"assessed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// ListAssessors returns assessor grants, optionally only those of one user
+// or one skill.
+func (s *SkillService) ListAssessors(ctx context.Context, userID, skillID uint) ([]models.SkillAssessor, error) {
+	return s.deps.Repos.SkillAssessment.ListAssessors(ctx, userID, skillID)
+}
+
+// GrantAssessor lets a user verify skill levels within a skill, a category,
+// or the whole catalog.
+func (s *SkillService) GrantAssessor(ctx context.Context, adminID uint, input GrantAssessorInput) (*models.SkillAssessor, error) {
+	skillID, categoryID := input.SkillID, input.CategoryID
+	if skillID != nil && *skillID == 0 {
+		skillID = nil
+	}
+	if categoryID != nil && *categoryID == 0 {
+		categoryID = nil
+	}
+	if skillID != nil && categoryID != nil {
+		return nil, fmt.Errorf("%w: give either skill_id or category_id, not both", ErrInvalidInput)
+	}
+
+	user, err := s.deps.Repos.User.GetByID(ctx, input.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: user not found", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	assessor := &models.SkillAssessor{
+		UserID:      user.ID,
+		SkillID:     skillID,
+		CategoryID:  categoryID,
+		GrantedByID: adminID,
+		User:        user,
+	}
+	if skillID != nil {
+		if assessor.Skill, err = s.getSkill(ctx, *skillID); err != nil {
+			return nil, err
+		}
+	}
+	if categoryID != nil {
+		if assessor.Category, err = s.getCategory(ctx, *categoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	grants, err := s.deps.Repos.SkillAssessment.ListAssessors(ctx, user.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range grants {
+		if sameID(grant.SkillID, skillID) && sameID(grant.CategoryID, categoryID) {
+			return nil, ErrSkillAssessorExists
+		}
+	}
+
+	if err := s.deps.Repos.SkillAssessment.CreateAssessor(ctx, assessor); err != nil {
+		return nil, err
+	}
+	s.deps.Logger.Info("Granted skill assessor", "user_id", user.ID, "granted_by", adminID)
+	return assessor, nil
+}
+
+// RevokeAssessor removes an assessor grant. Assessments made under it stay
+// valid.
+func (s *SkillService) RevokeAssessor(ctx context.Context, id uint) error {
+	if _, err := s.deps.Repos.SkillAssessment.GetAssessor(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSkillAssessorNotFound
+		}
+		return err
+	}
+	return s.deps.Repos.SkillAssessment.DeleteAssessor(ctx, id)
+}
+
+// Assess verifies the level of another user's skill. Only assessors whose
+// grant covers the skill may assess it, and never their own skills. The
+// assessment is kept in the skill's history and, unless a more recent one
+// exists, becomes the user skill's verified level; the owner is notified.
+func (s *SkillService) Assess(ctx context.Context, userSkillID, assessorID uint, input AssessSkillInput) (*models.SkillAssessment, error) {
+	userSkill, err := getUserSkill(ctx, s.deps, userSkillID)
+	if err != nil {
+		return nil, err
+	}
+	if userSkill.UserID == assessorID {
+		return nil, fmt.Errorf("%w: cannot assess your own skill", ErrInvalidInput)
+	}
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, assessorID, userSkill.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserSkillNotFound
+	}
+	allowed, err := s.deps.Repos.SkillAssessment.CanAssess(ctx, assessorID, userSkill.SkillID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: you are not an assessor for this skill", ErrForbidden)
+	}
+
+	evidence := strings.TrimSpace(input.Evidence)
+	if evidence == "" {
+		return nil, fmt.Errorf("%w: evidence is required", ErrInvalidInput)
+	}
+	now := time.Now()
+	assessedAt := now
+	if input.AssessedAt != nil {
+		if input.AssessedAt.After(now) {
+			return nil, fmt.Errorf("%w: assessed_at cannot be in the future", ErrInvalidInput)
+		}
+		assessedAt = *input.AssessedAt
+	}
+	expiresAt := input.ExpiresAt
+	if expiresAt != nil && !expiresAt.After(assessedAt) {
+		return nil, fmt.Errorf("%w: expires_at must be after assessed_at", ErrInvalidInput)
+	}
+	if days := s.deps.Config.Skills.VerificationValidityDays; expiresAt == nil && days > 0 {
+		t := assessedAt.AddDate(0, 0, days)
+		expiresAt = &t
+	}
+
+	assessment := &models.SkillAssessment{
+		UserSkillID: userSkill.ID,
+		UserID:      userSkill.UserID,
+		SkillID:     userSkill.SkillID,
+		AssessorID:  assessorID,
+		Level:       input.Level,
+		Evidence:    evidence,
+		EvidenceURL: strings.TrimSpace(input.EvidenceURL),
+		AssessedAt:  assessedAt,
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.deps.Repos.SkillAssessment.Create(ctx, assessment); err != nil {
+		return nil, err
+	}
+
+	skillName := "a skill"
+	if userSkill.Skill != nil {
+		skillName = userSkill.Skill.Name
+	}
+	notify(ctx, s.deps, Notice{
+		ActorID: assessorID,
+		Title:   "Skill verified",
+		Action:  fmt.Sprintf("verified your %s level as %s", skillName, input.Level),
+		Link:    fmt.Sprintf("/users/%d#skills", userSkill.UserID),
+		Data: models.SkillVerifiedData{
+			UserSkillID:  userSkill.ID,
+			SkillID:      userSkill.SkillID,
+			AssessmentID: assessment.ID,
+			Level:        input.Level,
+		},
+	}, userSkill.UserID)
+	return assessment, nil
+}
+
+// ListAssessments returns the assessment history of a user skill, most
+// recent first. The evidence is only shown to the skill's owner, to
+// assessors of the skill and to admins; other viewers see the levels and
+// dates alone.
+func (s *SkillService) ListAssessments(ctx context.Context, userSkillID, viewerID uint) ([]models.SkillAssessment, error) {
+	userSkill, err := getUserSkill(ctx, s.deps, userSkillID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, viewerID, userSkill.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserSkillNotFound
+	}
+
+	assessments, err := s.deps.Repos.SkillAssessment.ListByUserSkill(ctx, userSkillID)
+	if err != nil {
+		return nil, err
+	}
+	showEvidence, err := s.canSeeEvidence(ctx, userSkill, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range assessments {
+		if !showEvidence && assessments[i].AssessorID != viewerID {
+			assessments[i].Evidence = ""
+			assessments[i].EvidenceURL = ""
+		}
+	}
+	return assessments, nil
+}
+
+// canSeeEvidence reports whether the viewer may read the assessment evidence
+// of the user skill.
+func (s *SkillService) canSeeEvidence(ctx context.Context, userSkill *models.UserSkill, viewerID uint) (bool, error) {
+	if userSkill.UserID == viewerID {
+		return true, nil
+	}
+	assessor, err := s.deps.Repos.SkillAssessment.CanAssess(ctx, viewerID, userSkill.SkillID)
+	if err != nil || assessor {
+		return assessor, err
+	}
+	viewer, err := s.deps.Repos.User.GetByID(ctx, viewerID)
+	if err != nil {
+		return false, err
+	}
+	return viewer.Role == models.UserRoleAdmin, nil
+}
+
+// ListExpiringVerifications returns the user skills the assessor may assess
+// whose verification expires within the given number of days or already
+// has, soonest first.
+func (s *SkillService) ListExpiringVerifications(ctx context.Context, assessorID uint, days, page, limit int) ([]models.UserSkill, error) {
+	if days < 0 {
+		return nil, fmt.Errorf("%w: days cannot be negative", ErrInvalidInput)
+	}
+	userSkills, err := s.deps.Repos.SkillAssessment.ListExpiring(ctx, assessorID, time.Now().AddDate(0, 0, days), page, limit)
+	if err != nil {
+		return nil, err
+	}
+	setVerification(userSkills)
+	return userSkills, nil
+}
+
+// setVerification fills in the verification status of the user skills.
+func setVerification(userSkills []models.UserSkill) {
+	now := time.Now()
+	for i := range userSkills {
+		userSkills[i].Verification = userSkills[i].VerificationStatus(now)
+	}
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+)
+
+// Skill catalog formats.
+const (
+	CatalogCSV  = "csv"
+	CatalogJSON = "json"
+)
+
+// maxCatalogErrors caps the validation errors reported for one catalog.
+const maxCatalogErrors = 20
+
+// catalogColumns maps CSV headers to catalog fields. Besides SkillFlow's own
+// export it accepts the column names of ESCO skill exports.
+var catalogColumns = map[string]string{
+	"name":           "name",
+	"preferredlabel": "name",
+	"category":       "category",
+	"parent":         "parent",
+	"broaderlabel":   "parent",
+	"synonyms":       "synonyms",
+	"altlabels":      "synonyms",
+	"hiddenlabels":   "synonyms",
+	"description":    "description",
+	"definition":     "description",
+}
+
+// SkillCatalog is the import and export form of the skill catalog. CSV
+// carries skills only; categories are created from the skills' category
+// names.
+type SkillCatalog struct {
+	Categories []repository.CatalogCategory `json:"categories,omitempty"`
+	Skills     []repository.CatalogSkill    `json:"skills"`
+}
+
+// ParseCatalogFormat checks a catalog format name.
+func ParseCatalogFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case CatalogCSV:
+		return CatalogCSV, nil
+	case CatalogJSON:
+		return CatalogJSON, nil
+	}
+	return "", fmt.Errorf("%w: unknown catalog format %q, want csv or json", ErrInvalidInput, format)
+}
+
+// ImportCatalog reads a catalog in the given format and merges it into the
+// skill catalog, see repository.SkillRepository.ImportCatalog. The catalog
+// is validated as a whole first; a catalog with errors changes nothing.
+func (s *SkillService) ImportCatalog(ctx context.Context, r io.Reader, format string, dryRun bool) (*repository.CatalogReport, error) {
+	catalog, err := decodeCatalog(r, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCatalog(catalog); err != nil {
+		return nil, err
+	}
+
+	report, err := s.deps.Repos.Skill.ImportCatalog(ctx, catalog.Categories, catalog.Skills, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		s.deps.Logger.Info("Imported skill catalog", "changes", len(report.Changes), "conflicts", len(report.Conflicts))
+	}
+	return report, nil
+}
+
+// ExportCatalog writes the whole skill catalog in the given format. The
+// output can be imported again.
+func (s *SkillService) ExportCatalog(ctx context.Context, w io.Writer, format string) error {
+	skills, err := s.deps.Repos.Skill.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	catalog := &SkillCatalog{Skills: make([]repository.CatalogSkill, 0, len(skills))}
+	for _, skill := range skills {
+		entry := repository.CatalogSkill{
+			Name:        skill.Name,
+			Category:    skill.Category,
+			Description: skill.Description,
+		}
+		if skill.Parent != nil {
+			entry.Parent = skill.Parent.Name
+		}
+		for _, synonym := range skill.Synonyms {
+			entry.Synonyms = append(entry.Synonyms, synonym.Name)
+		}
+		catalog.Skills = append(catalog.Skills, entry)
+	}
+
+	switch format {
+	case CatalogCSV:
+		return encodeCatalogCSV(w, catalog)
+	case CatalogJSON:
+		categories, err := s.deps.Repos.Skill.ListCategories(ctx)
+		if err != nil {
+			return err
+		}
+		for _, category := range categories {
+			catalog.Categories = append(catalog.Categories, repository.CatalogCategory{
+				Name:        category.Name,
+				Description: category.Description,
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(catalog)
+	}
+	_, err = ParseCatalogFormat(format)
+	return err
+}
+
+func decodeCatalog(r io.Reader, format string) (*SkillCatalog, error) {
+	switch format {
+	case CatalogCSV:
+		return decodeCatalogCSV(r)
+	case CatalogJSON:
+		return decodeCatalogJSON(r)
+	}
+	_, err := ParseCatalogFormat(format)
+	return nil, err
+}
+
+// decodeCatalogJSON accepts a SkillCatalog object or a bare array of skills.
+func decodeCatalogJSON(r io.Reader) (*SkillCatalog, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty catalog", ErrInvalidInput)
+	}
+
+	var catalog SkillCatalog
+	if data[0] == '[' {
+		err = json.Unmarshal(data, &catalog.Skills)
+	} else {
+		err = json.Unmarshal(data, &catalog)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON catalog: %v", ErrInvalidInput, err)
+	}
+	return &catalog, nil
+}
+
+// decodeCatalogCSV reads a CSV file with a header row. Columns are matched
+// by name, ignoring case, spaces and underscores; unknown columns are
+// ignored. Synonyms are separated by "|" or newlines.
+func decodeCatalogCSV(r io.Reader) (*SkillCatalog, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: empty catalog", ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns := make(map[int]string, len(header))
+	hasName := false
+	for i, h := range header {
+		h = strings.TrimPrefix(h, "\ufeff")
+		h = strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(h)))
+		if field, ok := catalogColumns[h]; ok {
+			columns[i] = field
+			hasName = hasName || field == "name"
+		}
+	}
+	if !hasName {
+		return nil, fmt.Errorf("%w: CSV catalog needs a name column", ErrInvalidInput)
+	}
+
+	catalog := &SkillCatalog{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		var entry repository.CatalogSkill
+		for i, value := range record {
+			switch columns[i] {
+			case "name":
+				entry.Name = value
+			case "category":
+				entry.Category = value
+			case "parent":
+				entry.Parent = value
+			case "synonyms":
+				entry.Synonyms = append(entry.Synonyms, strings.FieldsFunc(value, func(r rune) bool {
+					return r == '|' || r == '\n'
+				})...)
+			case "description":
+				if entry.Description == "" {
+					entry.Description = value
+				}
+			}
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		catalog.Skills = append(catalog.Skills, entry)
+	}
+	return catalog, nil
+}
+
+// csvError reports malformed CSV as invalid input and passes read errors
+// through.
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%w: invalid CSV catalog: %v", ErrInvalidInput, err)
+	}
+	return err
+}
+
+func encodeCatalogCSV(w io.Writer, catalog *SkillCatalog) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"name", "category", "parent", "synonyms", "description"}); err != nil {
+		return err
+	}
+	for _, entry := range catalog.Skills {
+		record := []string{entry.Name, entry.Category, entry.Parent, strings.Join(entry.Synonyms, "|"), entry.Description}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// validateCatalog normalises names in place and checks that every skill has
+// a name, and that no name or synonym appears twice.
+func validateCatalog(catalog *SkillCatalog) error {
+	if len(catalog.Skills) == 0 && len(catalog.Categories) == 0 {
+		return fmt.Errorf("%w: catalog has no skills", ErrInvalidInput)
+	}
+
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	for i := range catalog.Categories {
+		category := &catalog.Categories[i]
+		category.Name = strings.Join(strings.Fields(category.Name), " ")
+		category.Description = strings.TrimSpace(category.Description)
+		if category.Name == "" || len(category.Name) > 100 {
+			report("category %d: name must be 1 to 100 characters", i+1)
+		}
+	}
+
+	// owners maps each name and synonym key to the skill that uses it.
+	owners := make(map[string]string)
+	for i := range catalog.Skills {
+		entry := &catalog.Skills[i]
+		entry.Name = strings.Join(strings.Fields(entry.Name), " ")
+		entry.Category = strings.Join(strings.Fields(entry.Category), " ")
+		entry.Parent = strings.Join(strings.Fields(entry.Parent), " ")
+		entry.Description = strings.TrimSpace(entry.Description)
+
+		if entry.Name == "" || len(entry.Name) > 100 {
+			report("skill %d: name must be 1 to 100 characters", i+1)
+			continue
+		}
+		if len(entry.Category) > 100 {
+			report("skill %q: category must be at most 100 characters", entry.Name)
+		}
+		key := models.SkillKey(entry.Name)
+		if owner, ok := owners[key]; ok {
+			report("skill %q: name already used by %q", entry.Name, owner)
+			continue
+		}
+		owners[key] = entry.Name
+		if entry.Parent != "" && models.SkillKey(entry.Parent) == key {
+			report("skill %q: cannot be its own parent", entry.Name)
+		}
+	}
+
+	for i := range catalog.Skills {
+		entry := &catalog.Skills[i]
+		if entry.Name == "" {
+			continue
+		}
+		key := models.SkillKey(entry.Name)
+		synonyms := entry.Synonyms[:0]
+		for _, synonym := range entry.Synonyms {
+			synonym = strings.Join(strings.Fields(synonym), " ")
+			synonymKey := models.SkillKey(synonym)
+			if synonym == "" || synonymKey == key {
+				continue
+			}
+			if len(synonym) > 100 {
+				report("skill %q: synonym %q must be at most 100 characters", entry.Name, synonym)
+				continue
+			}
+			if owner, ok := owners[synonymKey]; ok {
+				if owner != entry.Name {
+					report("skill %q: synonym %q already used by %q", entry.Name, synonym, owner)
+				}
+				continue
+			}
+			owners[synonymKey] = entry.Name
+			synonyms = append(synonyms, synonym)
+		}
+		entry.Synonyms = synonyms
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	if len(problems) > maxCatalogErrors {
+		problems = append(problems[:maxCatalogErrors], fmt.Sprintf("and %d more", len(problems)-maxCatalogErrors))
+	}
+	return fmt.Errorf("%w: invalid catalog: %s", ErrInvalidInput, strings.Join(problems, "; "))
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+var ErrEndorsementNotFound = fmt.Errorf("%w: endorsement not found", ErrNotFound)
+
+type EndorsementVisibilityInput struct {
+	Hidden *bool `json:"hidden" binding:"required"`
+}
+
+// ListEndorsements returns the endorsements of a user skill with their
+// endorsers and weights, newest first. Hidden endorsements are only listed
+// to the skill's owner.
+func (s *SkillService) ListEndorsements(ctx context.Context, userSkillID, viewerID uint) ([]models.Endorsement, error) {
+	userSkill, err := getUserSkill(ctx, s.deps, userSkillID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.deps.Repos.Connection.IsBlockedEitherWay(ctx, viewerID, userSkill.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserSkillNotFound
+	}
+
+	endorsements, err := s.deps.Repos.Endorsement.ListByUserSkill(ctx, userSkill.ID, userSkill.UserID == viewerID)
+	if err != nil {
+		return nil, err
+	}
+	endorserIDs := make([]uint, len(endorsements))
+	for i, endorsement := range endorsements {
+		endorserIDs[i] = endorsement.EndorserID
+	}
+	endorserSkills, err := s.deps.Repos.UserSkill.ListBySkillAndUsers(ctx, userSkill.SkillID, endorserIDs)
+	if err != nil {
+		return nil, err
+	}
+	weighEndorsements(endorsements, userSkill.SkillID, endorserSkills)
+	return endorsements, nil
+}
+
+// RevokeEndorsement withdraws the user's endorsement of a user skill.
+func (s *SkillService) RevokeEndorsement(ctx context.Context, userSkillID, endorserID uint) error {
+	endorsement, err := s.deps.Repos.Endorsement.GetByUserSkillAndEndorser(ctx, userSkillID, endorserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrEndorsementNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.deps.Repos.Endorsement.Delete(ctx, endorsement.ID)
+}
+
+// SetEndorsementHidden hides an endorsement of one of the user's skills
+// from their profile and from rankings, or shows it again.
+func (s *SkillService) SetEndorsementHidden(ctx context.Context, id, userID uint, hidden bool) (*models.Endorsement, error) {
+	endorsement, err := s.deps.Repos.Endorsement.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEndorsementNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if endorsement.UserSkill == nil || endorsement.UserSkill.UserID != userID {
+		if endorsement.EndorserID == userID {
+			return nil, fmt.Errorf("%w: only the endorsed user can hide an endorsement", ErrForbidden)
+		}
+		return nil, ErrEndorsementNotFound
+	}
+	if endorsement.Hidden != hidden {
+		if err := s.deps.Repos.Endorsement.SetHidden(ctx, endorsement.ID, hidden); err != nil {
+			return nil, err
+		}
+		endorsement.Hidden = hidden
+	}
+	return endorsement, nil
+}
+
+// setEndorsementWeights fills in the weights of the user skills'
+// endorsements.
+func (s *SkillService) setEndorsementWeights(ctx context.Context, userSkills []models.UserSkill) error {
+	var endorserIDs []uint
+	for _, userSkill := range userSkills {
+		for _, endorsement := range userSkill.Endorsements {
+			endorserIDs = append(endorserIDs, endorsement.EndorserID)
+		}
+	}
+	endorserSkills, err := s.deps.Repos.UserSkill.ListByUsers(ctx, endorserIDs)
+	if err != nil {
+		return err
+	}
+	for i := range userSkills {
+		weighEndorsements(userSkills[i].Endorsements, userSkills[i].SkillID, endorserSkills)
+	}
+	return nil
+}
+
+// weighEndorsements sets the weight of each endorsement of a skill from the
+// endorser's level in it, as found among endorserSkills.
+func weighEndorsements(endorsements []models.Endorsement, skillID uint, endorserSkills []models.UserSkill) {
+	now := time.Now()
+	levels := make(map[uint]string)
+	for i := range endorserSkills {
+		if endorserSkills[i].SkillID == skillID {
+			levels[endorserSkills[i].UserID] = endorserSkills[i].EffectiveLevel(now)
+		}
+	}
+	for i := range endorsements {
+		endorsements[i].Weight = models.EndorsementWeight(levels[endorsements[i].EndorserID])
+	}
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vern/skillflow/internal/domain/models"
+)
+
+// maxSkillTargets bounds the size of one target skill profile.
+const maxSkillTargets = 200
+
+// SkillMatrixScope selects the people of a skill matrix: a department or a
+// group, but not both.
+type SkillMatrixScope struct {
+	Department string
+	GroupID    uint
+}
+
+// SkillMatrixInput narrows a matrix to some skills or a category; by default
+// it has every skill someone in scope has or the target profile names.
+type SkillMatrixInput struct {
+	SkillMatrixScope
+	ViewerID   uint
+	SkillIDs   []uint
+	CategoryID uint
+}
+
+type SkillTargetsInput struct {
+	Targets []SkillTargetInput `json:"targets" binding:"dive"`
+}
+
+type SkillTargetInput struct {
+	SkillID   uint   `json:"skill_id" binding:"required"`
+	MinLevel  string `json:"min_level" binding:"required,oneof=beginner intermediate advanced expert"`
+	MinPeople int    `json:"min_people" binding:"required,min=1,max=1000"`
+}
+
+// SkillMatrix is a users × skills heatmap. Each row's levels line up with
+// Skills; an empty level means the user does not have the skill.
+type SkillMatrix struct {
+	Department string        `json:"department,omitempty"`
+	GroupID    uint          `json:"group_id,omitempty"`
+	Skills     []MatrixSkill `json:"skills"`
+	Rows       []MatrixRow   `json:"rows"`
+	Gaps       []SkillGap    `json:"gaps"`
+}
+
+// MatrixSkill summarises a skill across the matrix. Coverage is the share of
+// people with the skill at any level; BusFactor is how many have it at
+// advanced or above, and the skill is at risk when that is one or none.
+type MatrixSkill struct {
+	ID        uint                `json:"id"`
+	Name      string              `json:"name"`
+	Category  string              `json:"category"`
+	People    int                 `json:"people"`
+	Coverage  float64             `json:"coverage"`
+	BusFactor int                 `json:"bus_factor"`
+	AtRisk    bool                `json:"at_risk"`
+	Target    *models.SkillTarget `json:"target,omitempty"`
+}
+
+type MatrixRow struct {
+	UserID   uint     `json:"user_id"`
+	Username string   `json:"username"`
+	Name     string   `json:"name"`
+	Levels   []string `json:"levels"`
+}
+
+// SkillGap is a target the department or group falls short of: Qualified
+// people have the skill at the target level, Missing more are needed.
+type SkillGap struct {
+	SkillID   uint   `json:"skill_id"`
+	Skill     string `json:"skill"`
+	MinLevel  string `json:"min_level"`
+	MinPeople int    `json:"min_people"`
+	Qualified int    `json:"qualified"`
+	Missing   int    `json:"missing"`
+}
+
+// Matrix builds the skill matrix of a department or group and compares it
+// with the target skill profile. Department matrices are visible to admins
+// and to people in the department; group matrices to whoever may see the
+// group's members.
+func (s *SkillService) Matrix(ctx context.Context, input SkillMatrixInput) (*SkillMatrix, error) {
+	scopeKey, err := s.requireMatrixAccess(ctx, input.SkillMatrixScope, input.ViewerID, false)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.matrixUsers(ctx, input.SkillMatrixScope)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	userSkills, err := s.deps.Repos.UserSkill.ListByUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.deps.Repos.Skill.ListTargets(ctx, scopeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	include := func(skill *models.Skill) bool {
+		if skill == nil {
+			return false
+		}
+		if input.CategoryID != 0 && (skill.CategoryID == nil || *skill.CategoryID != input.CategoryID) {
+			return false
+		}
+		if len(input.SkillIDs) == 0 {
+			return true
+		}
+		for _, id := range input.SkillIDs {
+			if id == skill.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	columns := make(map[uint]*MatrixSkill)
+	column := func(skill *models.Skill) *MatrixSkill {
+		if c, ok := columns[skill.ID]; ok {
+			return c
+		}
+		c := &MatrixSkill{ID: skill.ID, Name: skill.Name, Category: skill.Category}
+		columns[skill.ID] = c
+		return c
+	}
+	levels := make(map[uint]map[uint]string, len(users))
+	for _, us := range userSkills {
+		if !include(us.Skill) {
+			continue
+		}
+		c := column(us.Skill)
+		c.People++
+		if models.SkillLevelRank(us.Level) >= models.SkillLevelRank(models.SkillLevelAdvanced) {
+			c.BusFactor++
+		}
+		if levels[us.UserID] == nil {
+			levels[us.UserID] = make(map[uint]string)
+		}
+		levels[us.UserID][us.SkillID] = us.Level
+	}
+	for i := range targets {
+		if include(targets[i].Skill) {
+			column(targets[i].Skill).Target = &targets[i]
+		}
+	}
+
+	matrix := &SkillMatrix{
+		Department: input.Department,
+		GroupID:    input.GroupID,
+		Skills:     make([]MatrixSkill, 0, len(columns)),
+		Rows:       make([]MatrixRow, 0, len(users)),
+		Gaps:       []SkillGap{},
+	}
+	for _, c := range columns {
+		if len(users) > 0 {
+			c.Coverage = math.Round(float64(c.People)/float64(len(users))*100) / 100
+		}
+		c.AtRisk = c.BusFactor <= 1
+		matrix.Skills = append(matrix.Skills, *c)
+	}
+	sort.Slice(matrix.Skills, func(i, j int) bool {
+		a, b := matrix.Skills[i], matrix.Skills[j]
+		if a.People != b.People {
+			return a.People > b.People
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+
+	for _, user := range users {
+		row := MatrixRow{UserID: user.ID, Username: user.Username, Name: displayName(&user), Levels: make([]string, len(matrix.Skills))}
+		for i, skill := range matrix.Skills {
+			row.Levels[i] = levels[user.ID][skill.ID]
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	sort.Slice(matrix.Rows, func(i, j int) bool {
+		return strings.ToLower(matrix.Rows[i].Name) < strings.ToLower(matrix.Rows[j].Name)
+	})
+
+	for i, skill := range matrix.Skills {
+		if skill.Target == nil {
+			continue
+		}
+		qualified := 0
+		for _, row := range matrix.Rows {
+			if row.Levels[i] != "" && models.SkillLevelRank(row.Levels[i]) >= models.SkillLevelRank(skill.Target.MinLevel) {
+				qualified++
+			}
+		}
+		if qualified < skill.Target.MinPeople {
+			matrix.Gaps = append(matrix.Gaps, SkillGap{
+				SkillID:   skill.ID,
+				Skill:     skill.Name,
+				MinLevel:  skill.Target.MinLevel,
+				MinPeople: skill.Target.MinPeople,
+				Qualified: qualified,
+				Missing:   skill.Target.MinPeople - qualified,
+			})
+		}
+	}
+	sort.SliceStable(matrix.Gaps, func(i, j int) bool { return matrix.Gaps[i].Missing > matrix.Gaps[j].Missing })
+	return matrix, nil
+}
+
+// WriteMatrixCSV writes the skill matrix as CSV: a row per person and a
+// column per skill holding their level.
+func (s *SkillService) WriteMatrixCSV(ctx context.Context, w io.Writer, input SkillMatrixInput) error {
+	matrix, err := s.Matrix(ctx, input)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	header := []string{"user_id", "username", "name"}
+	for _, skill := range matrix.Skills {
+		header = append(header, skill.Name)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range matrix.Rows {
+		record := append([]string{strconv.FormatUint(uint64(row.UserID), 10), row.Username, row.Name}, row.Levels...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ListTargets returns the target skill profile of a department or group.
+func (s *SkillService) ListTargets(ctx context.Context, scope SkillMatrixScope, viewerID uint) ([]models.SkillTarget, error) {
+	scopeKey, err := s.requireMatrixAccess(ctx, scope, viewerID, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Skill.ListTargets(ctx, scopeKey)
+}
+
+// SetTargets replaces the target skill profile of a department or group.
+// Admins manage department targets; group admins manage their group's.
+func (s *SkillService) SetTargets(ctx context.Context, scope SkillMatrixScope, userID uint, input SkillTargetsInput) ([]models.SkillTarget, error) {
+	scopeKey, err := s.requireMatrixAccess(ctx, scope, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(input.Targets) > maxSkillTargets {
+		return nil, fmt.Errorf("%w: at most %d targets", ErrInvalidInput, maxSkillTargets)
+	}
+
+	targets := make([]models.SkillTarget, 0, len(input.Targets))
+	seen := make(map[uint]bool, len(input.Targets))
+	ids := make([]uint, 0, len(input.Targets))
+	for _, t := range input.Targets {
+		if seen[t.SkillID] {
+			return nil, fmt.Errorf("%w: skill %d is listed twice", ErrInvalidInput, t.SkillID)
+		}
+		seen[t.SkillID] = true
+		ids = append(ids, t.SkillID)
+		target := models.SkillTarget{
+			ScopeKey:  scopeKey,
+			SkillID:   t.SkillID,
+			MinLevel:  t.MinLevel,
+			MinPeople: t.MinPeople,
+		}
+		if scope.GroupID != 0 {
+			target.GroupID = &scope.GroupID
+		} else {
+			target.Department = strings.TrimSpace(scope.Department)
+		}
+		targets = append(targets, target)
+	}
+	skills, err := s.deps.Repos.Skill.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(skills) != len(ids) {
+		return nil, ErrSkillNotFound
+	}
+
+	if err := s.deps.Repos.Skill.ReplaceTargets(ctx, scopeKey, targets); err != nil {
+		return nil, err
+	}
+	return s.deps.Repos.Skill.ListTargets(ctx, scopeKey)
+}
+
+// requireMatrixAccess checks that the user may see, or with manage set
+// change the targets of, the department or group, and returns the key its
+// targets are stored under.
+func (s *SkillService) requireMatrixAccess(ctx context.Context, scope SkillMatrixScope, userID uint, manage bool) (string, error) {
+	department := strings.TrimSpace(scope.Department)
+	if (department == "") == (scope.GroupID == 0) {
+		return "", fmt.Errorf("%w: either department or group_id is required", ErrInvalidInput)
+	}
+
+	if scope.GroupID != 0 {
+		group, err := getVisibleGroup(ctx, s.deps, scope.GroupID, userID)
+		if err != nil {
+			return "", err
+		}
+		if manage {
+			_, err = requireGroupRole(ctx, s.deps, group.ID, userID, models.GroupRoleAdmin)
+		} else {
+			err = requireGroupContentAccess(ctx, s.deps, group, userID)
+		}
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("group:%d", group.ID), nil
+	}
+
+	user, err := s.deps.Repos.User.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	inDepartment := user.Profile != nil && strings.EqualFold(strings.TrimSpace(user.Profile.Department), department)
+	if user.Role != models.UserRoleAdmin && (manage || !inDepartment) {
+		return "", fmt.Errorf("%w: not allowed to view this department", ErrForbidden)
+	}
+	return "department:" + models.SkillKey(department), nil
+}
+
+// matrixUsers returns the active people in the department or group.
+func (s *SkillService) matrixUsers(ctx context.Context, scope SkillMatrixScope) ([]models.User, error) {
+	if scope.GroupID == 0 {
+		return s.deps.Repos.User.ListByDepartment(ctx, strings.TrimSpace(scope.Department))
+	}
+	roles := []string{models.GroupRoleOwner, models.GroupRoleAdmin, models.GroupRoleModerator, models.GroupRoleMember}
+	ids, err := s.deps.Repos.GroupMember.ListUserIDsByRoles(ctx, scope.GroupID, roles...)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.deps.Repos.User.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	active := users[:0]
+	for _, user := range users {
+		if user.IsActive {
+			active = append(active, user)
+		}
+	}
+	return active, nil
+}
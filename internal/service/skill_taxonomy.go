@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSkillCategoryNotFound = fmt.Errorf("%w: skill category not found", ErrNotFound)
+	ErrSkillCategoryExists   = fmt.Errorf("%w: skill category already exists", ErrConflict)
+	ErrSkillSynonymNotFound  = fmt.Errorf("%w: synonym not found", ErrNotFound)
+	ErrSkillCycle            = fmt.Errorf("%w: a skill cannot be its own ancestor", ErrInvalidInput)
+)
+
+// UpdateSkillInput changes a catalog skill; omitted fields are unchanged. A
+// category_id of 0 removes the category and a parent_id of 0 makes the skill
+// top-level.
+type UpdateSkillInput struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	CategoryID  *uint   `json:"category_id"`
+	ParentID    *uint   `json:"parent_id"`
+}
+
+type SkillCategoryInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=2000"`
+}
+
+type AddSynonymInput struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type MergeSkillsInput struct {
+	DuplicateIDs []uint `json:"duplicate_ids" binding:"required,min=1,dive,required"`
+}
+
+// Get returns a skill with its parent, children and synonyms.
+func (s *SkillService) Get(ctx context.Context, id uint) (*models.Skill, error) {
+	return s.getSkill(ctx, id)
+}
+
+// Autocomplete suggests skills whose name or a synonym contains the query.
+func (s *SkillService) Autocomplete(ctx context.Context, query string, limit int) ([]repository.SkillSuggestion, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return []repository.SkillSuggestion{}, nil
+	}
+	return s.deps.Repos.Skill.Autocomplete(ctx, query, limit)
+}
+
+// Update changes a catalog skill. Moving a skill below one of its own
+// descendants is rejected.
+func (s *SkillService) Update(ctx context.Context, id uint, input UpdateSkillInput) (*models.Skill, error) {
+	skill, err := s.getSkill(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.Join(strings.Fields(*input.Name), " ")
+		if name == "" {
+			return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+		}
+		if err := s.checkNameFree(ctx, name, skill.ID); err != nil {
+			return nil, err
+		}
+		// Renaming a skill to one of its synonyms makes the synonym redundant.
+		for _, synonym := range skill.Synonyms {
+			if synonym.NameKey == models.SkillKey(name) {
+				if _, err := s.deps.Repos.Skill.DeleteSynonym(ctx, synonym.ID, skill.ID); err != nil {
+					return nil, err
+				}
+			}
+		}
+		skill.Name = name
+	}
+	if input.Description != nil {
+		skill.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.CategoryID != nil {
+		skill.CategoryID, skill.Category = nil, ""
+		if *input.CategoryID != 0 {
+			category, err := s.resolveCategory(ctx, input.CategoryID, "")
+			if err != nil {
+				return nil, err
+			}
+			skill.CategoryID, skill.Category = &category.ID, category.Name
+		}
+	}
+	if input.ParentID != nil {
+		skill.ParentID = nil
+		if *input.ParentID != 0 {
+			if err := s.checkParent(ctx, skill.ID, *input.ParentID); err != nil {
+				return nil, err
+			}
+			skill.ParentID = input.ParentID
+		}
+	}
+
+	if err := s.deps.Repos.Skill.Update(ctx, skill); err != nil {
+		return nil, err
+	}
+	return s.getSkill(ctx, skill.ID)
+}
+
+// AddSynonym adds an alternative name that resolves to the skill. It must
+// not already name a skill or be a synonym.
+func (s *SkillService) AddSynonym(ctx context.Context, skillID uint, input AddSynonymInput) (*models.SkillSynonym, error) {
+	name := strings.Join(strings.Fields(input.Name), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if _, err := s.getSkill(ctx, skillID); err != nil {
+		return nil, err
+	}
+	existing, err := s.deps.Repos.Skill.Resolve(ctx, name)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %q already refers to %s", ErrConflict, name, existing.Name)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	synonym := &models.SkillSynonym{SkillID: skillID, Name: name, NameKey: models.SkillKey(name)}
+	if err := s.deps.Repos.Skill.AddSynonym(ctx, synonym); err != nil {
+		return nil, err
+	}
+	return synonym, nil
+}
+
+func (s *SkillService) RemoveSynonym(ctx context.Context, skillID, synonymID uint) error {
+	deleted, err := s.deps.Repos.Skill.DeleteSynonym(ctx, synonymID, skillID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrSkillSynonymNotFound
+	}
+	return nil
+}
+
+// Merge folds duplicate skills into the canonical skill. User skills,
+// endorsements, child skills and synonyms move to the canonical skill, the
+// duplicates' names become synonyms and the duplicates are deleted. Either
+// all duplicates are merged or none are.
+func (s *SkillService) Merge(ctx context.Context, canonicalID uint, input MergeSkillsInput) (*models.Skill, error) {
+	seen := make(map[uint]bool, len(input.DuplicateIDs))
+	duplicateIDs := make([]uint, 0, len(input.DuplicateIDs))
+	for _, id := range input.DuplicateIDs {
+		if id == canonicalID {
+			return nil, fmt.Errorf("%w: cannot merge a skill into itself", ErrInvalidInput)
+		}
+		if !seen[id] {
+			seen[id] = true
+			duplicateIDs = append(duplicateIDs, id)
+		}
+	}
+	if _, err := s.getSkill(ctx, canonicalID); err != nil {
+		return nil, err
+	}
+	duplicates, err := s.deps.Repos.Skill.GetByIDs(ctx, duplicateIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(duplicates) != len(duplicateIDs) {
+		return nil, ErrSkillNotFound
+	}
+
+	if err := s.deps.Repos.Skill.Merge(ctx, canonicalID, duplicateIDs); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSkillNotFound
+		}
+		return nil, err
+	}
+	s.deps.Logger.Info("Merged skills", "canonical_id", canonicalID, "duplicate_ids", duplicateIDs)
+	return s.getSkill(ctx, canonicalID)
+}
+
+func (s *SkillService) ListCategories(ctx context.Context) ([]models.SkillCategory, error) {
+	return s.deps.Repos.Skill.ListCategories(ctx)
+}
+
+// CreateCategory adds a skill category. Names are unique ignoring case.
+func (s *SkillService) CreateCategory(ctx context.Context, input SkillCategoryInput) (*models.SkillCategory, error) {
+	name := strings.Join(strings.Fields(input.Name), " ")
+	if err := s.checkCategoryNameFree(ctx, name, 0); err != nil {
+		return nil, err
+	}
+	category := &models.SkillCategory{Name: name, Description: strings.TrimSpace(input.Description)}
+	if err := s.deps.Repos.Skill.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory renames or describes a category. Renaming it also renames
+// it on its skills.
+func (s *SkillService) UpdateCategory(ctx context.Context, id uint, input SkillCategoryInput) (*models.SkillCategory, error) {
+	category, err := s.getCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Join(strings.Fields(input.Name), " ")
+	if err := s.checkCategoryNameFree(ctx, name, category.ID); err != nil {
+		return nil, err
+	}
+	category.Name = name
+	category.Description = strings.TrimSpace(input.Description)
+	if err := s.deps.Repos.Skill.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category; its skills become uncategorised.
+func (s *SkillService) DeleteCategory(ctx context.Context, id uint) error {
+	if _, err := s.getCategory(ctx, id); err != nil {
+		return err
+	}
+	return s.deps.Repos.Skill.DeleteCategory(ctx, id)
+}
+
+func (s *SkillService) getSkill(ctx context.Context, id uint) (*models.Skill, error) {
+	skill, err := s.deps.Repos.Skill.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSkillNotFound
+	}
+	return skill, err
+}
+
+func (s *SkillService) getCategory(ctx context.Context, id uint) (*models.SkillCategory, error) {
+	category, err := s.deps.Repos.Skill.GetCategory(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSkillCategoryNotFound
+	}
+	return category, err
+}
+
+// checkNameFree reports ErrSkillExists when the name or one of its synonyms
+// belongs to a skill other than skillID.
+func (s *SkillService) checkNameFree(ctx context.Context, name string, skillID uint) error {
+	existing, err := s.deps.Repos.Skill.Resolve(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != skillID {
+		return ErrSkillExists
+	}
+	return nil
+}
+
+func (s *SkillService) checkCategoryNameFree(ctx context.Context, name string, categoryID uint) error {
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	existing, err := s.deps.Repos.Skill.GetCategoryByName(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != categoryID {
+		return ErrSkillCategoryExists
+	}
+	return nil
+}
+
+// checkParent verifies that parentID exists and is not the skill itself or
+// one of its descendants.
+func (s *SkillService) checkParent(ctx context.Context, skillID, parentID uint) error {
+	if parentID == skillID {
+		return ErrSkillCycle
+	}
+	if _, err := s.getSkill(ctx, parentID); err != nil {
+		return err
+	}
+	ancestors, err := s.deps.Repos.Skill.ListAncestorIDs(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ancestors {
+		if id == skillID {
+			return ErrSkillCycle
+		}
+	}
+	return nil
+}
+
+// resolveCategory returns the category with the given ID, or else the
+// category with the given name, creating it if needed. It returns nil when
+// neither is given.
+func (s *SkillService) resolveCategory(ctx context.Context, id *uint, name string) (*models.SkillCategory, error) {
+	if id != nil && *id != 0 {
+		return s.getCategory(ctx, *id)
+	}
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return nil, nil
+	}
+	category, err := s.deps.Repos.Skill.GetCategoryByName(ctx, name)
+	if err == nil {
+		return category, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	category = &models.SkillCategory{Name: name}
+	if err := s.deps.Repos.Skill.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/vern/skillflow/internal/domain/models"
+	"github.com/vern/skillflow/internal/repository"
+	"gorm.io/gorm"
 )
 
 type UserService struct {
@@ -20,6 +23,8 @@ type UpdateUserInput struct {
 	Username string `json:"username"`
 }
 
+// UpdateProfileInput replaces the user's profile. ManagerID names the
+// user's manager, who can see the learning goals the user shares.
 type UpdateProfileInput struct {
 	FirstName  string `json:"first_name"`
 	LastName   string `json:"last_name"`
@@ -28,6 +33,7 @@ type UpdateProfileInput struct {
 	Position   string `json:"position"`
 	Location   string `json:"location"`
 	Phone      string `json:"phone"`
+	ManagerID  *uint  `json:"manager_id"`
 }
 
 func (s *UserService) GetByID(ctx context.Context, id uint) (*models.User, error) {
@@ -71,6 +77,19 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID uint, input Upda
 	profile.Position = input.Position
 	profile.Location = input.Location
 	profile.Phone = input.Phone
+	profile.ManagerID = nil
+	if input.ManagerID != nil && *input.ManagerID != 0 {
+		if *input.ManagerID == userID {
+			return nil, fmt.Errorf("%w: you cannot be your own manager", ErrInvalidInput)
+		}
+		if _, err := s.deps.Repos.User.GetByID(ctx, *input.ManagerID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: manager not found", ErrNotFound)
+			}
+			return nil, err
+		}
+		profile.ManagerID = input.ManagerID
+	}
 
 	if err := s.deps.Repos.Profile.Update(ctx, profile); err != nil {
 		return nil, err
@@ -83,6 +102,52 @@ func (s *UserService) Search(ctx context.Context, query string) ([]models.User,
 	return s.deps.Repos.User.Search(ctx, query)
 }
 
+type UpdateSettingsInput struct {
+	MessagePrivacy     string `json:"message_privacy" binding:"omitempty,oneof=everyone connections"`
+	LastSeenVisibility string `json:"last_seen_visibility" binding:"omitempty,oneof=everyone connections nobody"`
+}
+
+func (s *UserService) GetSettings(ctx context.Context, userID uint) (*models.UserSettings, error) {
+	return s.deps.Repos.UserSettings.GetByUserID(ctx, userID)
+}
+
+func (s *UserService) UpdateSettings(ctx context.Context, userID uint, input UpdateSettingsInput) (*models.UserSettings, error) {
+	settings, err := s.deps.Repos.UserSettings.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.MessagePrivacy != "" {
+		settings.MessagePrivacy = input.MessagePrivacy
+	}
+	if input.LastSeenVisibility != "" {
+		settings.LastSeenVisibility = input.LastSeenVisibility
+	}
+
+	if err := s.deps.Repos.UserSettings.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *UserService) Block(ctx context.Context, userID, targetID uint) error {
+	if userID == targetID {
+		return fmt.Errorf("%w: cannot block yourself", ErrInvalidInput)
+	}
+	if _, err := s.deps.Repos.User.GetByID(ctx, targetID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: user not found", ErrNotFound)
+		}
+		return err
+	}
+	return s.deps.Repos.Connection.Block(ctx, userID, targetID)
+}
+
+func (s *UserService) Unblock(ctx context.Context, userID, targetID uint) error {
+	return s.deps.Repos.Connection.Unblock(ctx, userID, targetID)
+}
+
 type PostService struct {
 	deps ServicesDeps
 }
@@ -105,6 +170,12 @@ type UpdatePostInput struct {
 }
 
 func (s *PostService) Create(ctx context.Context, input CreatePostInput) (*models.Post, error) {
+	if input.GroupID != nil {
+		if _, err := requireGroupRole(ctx, s.deps, *input.GroupID, input.UserID, models.GroupRoleMember); err != nil {
+			return nil, err
+		}
+	}
+
 	post := &models.Post{
 		UserID:     input.UserID,
 		Content:    input.Content,
@@ -117,22 +188,34 @@ func (s *PostService) Create(ctx context.Context, input CreatePostInput) (*model
 		return nil, err
 	}
 
+	s.notifyMentions(ctx, post, "")
 	return post, nil
 }
 
-func (s *PostService) GetByID(ctx context.Context, id uint) (*models.Post, error) {
-	return s.deps.Repos.Post.GetByID(ctx, id)
+// GetByID returns the post when the viewer can read it; posts in private or
+// secret groups are not found for non-members.
+func (s *PostService) GetByID(ctx context.Context, id, viewerID uint) (*models.Post, error) {
+	return getReadablePost(ctx, s.deps, id, viewerID)
 }
 
 func (s *PostService) GetFeed(ctx context.Context, userID uint, page, limit int) ([]models.Post, error) {
 	return s.deps.Repos.Post.GetFeed(ctx, userID, page, limit)
 }
 
-func (s *PostService) GetUserPosts(ctx context.Context, userID uint, page, limit int) ([]models.Post, error) {
-	return s.deps.Repos.Post.GetByUserID(ctx, userID, page, limit)
+// GetUserPosts lists the user's posts, leaving out those in groups the viewer
+// cannot read.
+func (s *PostService) GetUserPosts(ctx context.Context, userID, viewerID uint, page, limit int) ([]models.Post, error) {
+	return s.deps.Repos.Post.GetByUserID(ctx, userID, viewerID, page, limit)
 }
 
-func (s *PostService) GetGroupPosts(ctx context.Context, groupID uint, page, limit int) ([]models.Post, error) {
+func (s *PostService) GetGroupPosts(ctx context.Context, groupID, viewerID uint, page, limit int) ([]models.Post, error) {
+	group, err := getVisibleGroup(ctx, s.deps, groupID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireGroupContentAccess(ctx, s.deps, group, viewerID); err != nil {
+		return nil, err
+	}
 	return s.deps.Repos.Post.GetByGroupID(ctx, groupID, page, limit)
 }
 
@@ -146,6 +229,7 @@ func (s *PostService) Update(ctx context.Context, id, userID uint, input UpdateP
 		return nil, errors.New("unauthorized")
 	}
 
+	previous := post.Content
 	post.Content = input.Content
 	if input.Visibility != "" {
 		post.Visibility = input.Visibility
@@ -155,6 +239,7 @@ func (s *PostService) Update(ctx context.Context, id, userID uint, input UpdateP
 		return nil, err
 	}
 
+	s.notifyMentions(ctx, post, previous)
 	return post, nil
 }
 
@@ -171,39 +256,138 @@ func (s *PostService) Delete(ctx context.Context, id, userID uint) error {
 	return s.deps.Repos.Post.Delete(ctx, id)
 }
 
-// Placeholder services
-type CommentService struct{ deps ServicesDeps }
+// Per-target limits on pinned posts.
+const (
+	maxGroupPins   = 5
+	maxProfilePins = 3
+)
 
-func NewCommentService(deps ServicesDeps) *CommentService { return &CommentService{deps: deps} }
+var ErrPostNotFound = fmt.Errorf("%w: post not found", ErrNotFound)
 
-type ReactionService struct{ deps ServicesDeps }
+var (
+	ErrPinLimitReached = fmt.Errorf("%w: pin limit reached", ErrConflict)
+	ErrAlreadyPinned   = fmt.Errorf("%w: post is already pinned", ErrConflict)
+)
+
+// PinToGroup pins a group post to the top of the group timeline. Only group
+// admins and owners may pin.
+func (s *PostService) PinToGroup(ctx context.Context, groupID, postID, userID uint) error {
+	if _, err := requireGroupRole(ctx, s.deps, groupID, userID, models.GroupRoleAdmin); err != nil {
+		return err
+	}
 
-func NewReactionService(deps ServicesDeps) *ReactionService { return &ReactionService{deps: deps} }
+	post, err := s.getPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post.GroupID == nil || *post.GroupID != groupID {
+		return fmt.Errorf("%w: post does not belong to this group", ErrInvalidInput)
+	}
 
-type ConnectionService struct{ deps ServicesDeps }
+	return s.pin(ctx, post.ID, models.PinTargetGroup, groupID, userID, maxGroupPins)
+}
 
-func NewConnectionService(deps ServicesDeps) *ConnectionService {
-	return &ConnectionService{deps: deps}
+func (s *PostService) UnpinFromGroup(ctx context.Context, groupID, postID, userID uint) error {
+	if _, err := requireGroupRole(ctx, s.deps, groupID, userID, models.GroupRoleAdmin); err != nil {
+		return err
+	}
+	return s.deps.Repos.Post.Unpin(ctx, postID, models.PinTargetGroup, groupID)
 }
 
-type NotificationService struct{ deps ServicesDeps }
+// PinToProfile pins one of the user's own posts to their profile.
+func (s *PostService) PinToProfile(ctx context.Context, postID, userID uint) error {
+	post, err := s.getPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post.UserID != userID {
+		return fmt.Errorf("%w: only the author can pin a post to their profile", ErrForbidden)
+	}
 
-func NewNotificationService(deps ServicesDeps) *NotificationService {
-	return &NotificationService{deps: deps}
+	return s.pin(ctx, post.ID, models.PinTargetProfile, userID, userID, maxProfilePins)
 }
 
-type MessageService struct{ deps ServicesDeps }
+func (s *PostService) UnpinFromProfile(ctx context.Context, postID, userID uint) error {
+	return s.deps.Repos.Post.Unpin(ctx, postID, models.PinTargetProfile, userID)
+}
 
-func NewMessageService(deps ServicesDeps) *MessageService { return &MessageService{deps: deps} }
+func (s *PostService) pin(ctx context.Context, postID uint, targetType string, targetID, userID uint, max int64) error {
+	pinned, err := s.deps.Repos.Post.IsPinned(ctx, postID, targetType, targetID)
+	if err != nil {
+		return err
+	}
+	if pinned {
+		return ErrAlreadyPinned
+	}
 
-type GroupService struct{ deps ServicesDeps }
+	err = s.deps.Repos.Post.Pin(ctx, &models.PinnedPost{
+		PostID:     postID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		PinnedByID: userID,
+	}, max)
+	switch {
+	case errors.Is(err, repository.ErrPinLimitReached):
+		return ErrPinLimitReached
+	case errors.Is(err, repository.ErrAlreadyPinned):
+		return ErrAlreadyPinned
+	}
+	return err
+}
 
-func NewGroupService(deps ServicesDeps) *GroupService { return &GroupService{deps: deps} }
+// notifyMentions notifies users mentioned in the post for the first time.
+func (s *PostService) notifyMentions(ctx context.Context, post *models.Post, previous string) {
+	notifyMentions(ctx, s.deps, Notice{
+		ActorID: post.UserID,
+		Title:   "You were mentioned",
+		Action:  "mentioned you in a post",
+		Link:    fmt.Sprintf("/posts/%d", post.ID),
+		Data:    models.MentionData{PostID: post.ID},
+	}, post.Content, previous, func(userID uint) bool {
+		return canReadPost(ctx, s.deps, post, userID) == nil
+	})
+}
+
+func (s *PostService) getPost(ctx context.Context, id uint) (*models.Post, error) {
+	return getPost(ctx, s.deps, id)
+}
 
-type SkillService struct{ deps ServicesDeps }
+func getPost(ctx context.Context, deps ServicesDeps, id uint) (*models.Post, error) {
+	post, err := deps.Repos.Post.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPostNotFound
+	}
+	return post, err
+}
+
+// getReadablePost loads a post the viewer may see. Posts in groups whose
+// content is hidden from the viewer are reported as not found.
+func getReadablePost(ctx context.Context, deps ServicesDeps, id, viewerID uint) (*models.Post, error) {
+	post, err := getPost(ctx, deps, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canReadPost(ctx, deps, post, viewerID); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
 
-func NewSkillService(deps ServicesDeps) *SkillService { return &SkillService{deps: deps} }
+func canReadPost(ctx context.Context, deps ServicesDeps, post *models.Post, viewerID uint) error {
+	if post.GroupID == nil {
+		return nil
+	}
+	group, err := getVisibleGroup(ctx, deps, *post.GroupID, viewerID)
+	if err == nil {
+		err = requireGroupContentAccess(ctx, deps, group, viewerID)
+	}
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+		return ErrPostNotFound
+	}
+	return err
+}
 
+// Placeholder services
 type FileService struct{ deps ServicesDeps }
 
 func NewFileService(deps ServicesDeps) *FileService { return &FileService{deps: deps} }
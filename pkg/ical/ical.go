@@ -0,0 +1,119 @@
+// Package ical renders iCalendar (RFC 5545) feeds.
+package ical
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLineOctets is the RFC 5545 content line limit, excluding CRLF.
+const maxLineOctets = 75
+
+const timeFormat = "20060102T150405Z"
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event is a VEVENT. Times are written in UTC.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Status       string // TENTATIVE, CONFIRMED or CANCELLED
+	Start        time.Time
+	End          time.Time
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Encode writes the calendar to w with CRLF line endings and folded lines.
+func (c *Calendar) Encode(w io.Writer) error {
+	var b strings.Builder
+	line := func(name, value string) {
+		writeFolded(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", escape(c.ProdID))
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+
+	stamp := time.Now().UTC().Format(timeFormat)
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", stamp)
+		line("DTSTART", e.Start.UTC().Format(timeFormat))
+		line("DTEND", e.End.UTC().Format(timeFormat))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if e.Status != "" {
+			line("STATUS", e.Status)
+		}
+		if !e.Created.IsZero() {
+			line("CREATED", e.Created.UTC().Format(timeFormat))
+		}
+		if !e.LastModified.IsZero() {
+			line("LAST-MODIFIED", e.LastModified.UTC().Format(timeFormat))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeFolded writes a content line, folding it at 75 octets without
+// splitting UTF-8 sequences. Continuation lines start with a single space.
+func writeFolded(w *strings.Builder, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts towards the next line's length.
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
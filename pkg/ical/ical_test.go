@@ -0,0 +1,127 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWriteFolded(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{"short", "SUMMARY:Go meetup", 1},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67), 1},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68), 2},
+		{"long ascii", "DESCRIPTION:" + strings.Repeat("abcdefghij", 20), 3},
+		// "é" is two octets; with the 12-octet name it straddles octet 75.
+		{"two-octet rune at the boundary", "DESCRIPTION:" + strings.Repeat("a", 62) + strings.Repeat("é", 10), 2},
+		// "€" is three octets; the first fold lands inside one.
+		{"three-octet runes", "SUMMARY:" + strings.Repeat("€", 60), 3},
+		// "🎉" is four octets.
+		{"four-octet runes", "SUMMARY:" + strings.Repeat("🎉", 40), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeFolded(&b, tt.line)
+			out := b.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("got %d lines, want %d: %q", len(lines), tt.lines, lines)
+			}
+			for i, l := range lines {
+				if len(l) > maxLineOctets {
+					t.Errorf("line %d is %d octets, want at most %d", i, len(l), maxLineOctets)
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d %q does not start with a space", i, l)
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d %q splits a UTF-8 sequence", i, l)
+				}
+			}
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded to %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"Room 1, Floor 2", `Room 1\, Floor 2`},
+		{"a;b", `a\;b`},
+		{`C:\temp`, `C:\\temp`},
+		{"line one\nline two", `line one\nline two`},
+		{"line one\r\nline two", `line one\nline two`},
+		{`\,;`, `\\\,\;`},
+		{"Café ☕", "Café ☕"},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+	cal := &Calendar{
+		ProdID: "-//SkillFlow//Events//EN",
+		Name:   "Go, Rust; and more",
+		Events: []Event{{
+			UID:          "event-1@example.com",
+			Summary:      "Meetup",
+			Location:     "Room 1, Floor 2",
+			Status:       "TENTATIVE",
+			Start:        time.Date(2024, 6, 1, 18, 30, 0, 0, berlin),
+			End:          time.Date(2024, 6, 1, 20, 0, 0, 0, berlin),
+			Created:      time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+			LastModified: time.Date(2024, 5, 2, 12, 0, 0, 0, berlin),
+		}},
+	}
+
+	var b strings.Builder
+	if err := cal.Encode(&b); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Go\\, Rust\\; and more\r\n",
+		"DTSTART:20240601T163000Z\r\n",
+		"DTEND:20240601T180000Z\r\n",
+		"CREATED:20240501T090000Z\r\n",
+		"LAST-MODIFIED:20240502T100000Z\r\n",
+		"LOCATION:Room 1\\, Floor 2\r\n",
+		"STATUS:TENTATIVE\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	for _, absent := range []string{"DESCRIPTION:", "URL:"} {
+		if strings.Contains(out, absent) {
+			t.Errorf("output contains %q for an empty value", absent)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Error("output contains a bare LF")
+	}
+}
@@ -0,0 +1,174 @@
+// Package mailer sends e-mail through SMTP or, for local development, writes
+// it to .eml files.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vern/skillflow/internal/config"
+)
+
+// Message is a multipart/alternative e-mail. ID becomes the local part of
+// the Message-ID header; giving the same logical message the same ID lets
+// receiving systems and the file sink recognise repeats.
+type Message struct {
+	ID      string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by cfg.Transport: "smtp", or "file" (the
+// default) to write messages to cfg.FileDir.
+func New(cfg config.EmailConfig) (Mailer, error) {
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", cfg.From, err)
+	}
+	switch cfg.Transport {
+	case "", "file":
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	case "smtp":
+		return &SMTPMailer{cfg: cfg.SMTP, from: cfg.From}, nil
+	default:
+		return nil, fmt.Errorf("unknown email transport %q", cfg.Transport)
+	}
+}
+
+// SMTPMailer delivers through an SMTP relay, upgrading to TLS when the
+// server offers STARTTLS.
+type SMTPMailer struct {
+	cfg  config.SMTPConfig
+	from string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	raw, err := encode(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	sender, _ := mail.ParseAddress(m.from)
+	addr := fmt.Sprintf("%s:%d", m.cfg.Host, m.cfg.Port)
+	return smtp.SendMail(addr, auth, sender.Address, []string{msg.To}, raw)
+}
+
+// FileMailer writes each message to <dir>/<id>.eml, so re-sending the same
+// message overwrites the earlier file.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir == "" {
+		dir = "mail"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// unsafeIDChars matches characters replaced in file names and Message-IDs.
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	raw, err := encode(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	name := unsafeIDChars.ReplaceAllString(msg.ID, "_")
+	if name == "" {
+		name = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return os.WriteFile(filepath.Join(m.dir, name+".eml"), raw, 0o644)
+}
+
+// encode renders msg as an RFC 5322 message with quoted-printable text and
+// HTML parts.
+func encode(from string, msg Message, now time.Time) ([]byte, error) {
+	id := unsafeIDChars.ReplaceAllString(msg.ID, ".")
+	if id == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		id = hex.EncodeToString(b)
+	}
+	domain := "localhost"
+	if sender, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(sender.Address, "@"); at >= 0 {
+			domain = sender.Address[at+1:]
+		}
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		if part.content == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&out, "%s: %s\r\n", name, value) }
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", id, domain))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
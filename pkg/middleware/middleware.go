@@ -44,6 +44,11 @@ func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
 func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		// Browsers cannot set headers on WebSocket upgrades or EventSource
+		// requests, so those may pass the access token as a query parameter.
+		if authHeader == "" && isStreamingRequest(c) && c.Query("token") != "" {
+			authHeader = "Bearer " + c.Query("token")
+		}
 		if authHeader == "" {
 			c.JSON(401, gin.H{"error": "Authorization header required"})
 			c.Abort()
@@ -80,6 +85,11 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	}
 }
 
+func isStreamingRequest(c *gin.Context) bool {
+	return strings.EqualFold(c.GetHeader("Upgrade"), "websocket") ||
+		strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
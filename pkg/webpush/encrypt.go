@@ -0,0 +1,103 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// recordSize is the aes128gcm record size. The whole payload is sent as
+	// a single record.
+	recordSize = 4096
+	saltLength = 16
+	// headerLength is salt, record size, key ID length and the 65-byte
+	// uncompressed public key used as key ID.
+	headerLength = saltLength + 4 + 1 + 65
+	tagLength    = 16
+	// MaxPayload is the largest payload that fits in one record: the record
+	// holds the payload, the 0x02 delimiter and the GCM tag.
+	MaxPayload = recordSize - tagLength - 1
+)
+
+var ErrPayloadTooLarge = errors.New("webpush: payload too large")
+
+// encrypt encrypts the payload for the subscription as described in RFC 8291
+// and encodes it with the aes128gcm content coding of RFC 8188.
+func encrypt(sub *Subscription, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayload {
+		return nil, ErrPayloadTooLarge
+	}
+	uaPublic, authSecret, err := sub.keys()
+	if err != nil {
+		return nil, err
+	}
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWith(uaPublic, authSecret, asPrivate, salt, payload)
+}
+
+func encryptWith(uaPublic *ecdh.PublicKey, authSecret []byte, asPrivate *ecdh.PrivateKey, salt, payload []byte) ([]byte, error) {
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic.Bytes()...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := expand(sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := expand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, headerLength, headerLength+len(payload)+1+tagLength)
+	copy(body, salt)
+	binary.BigEndian.PutUint32(body[saltLength:], recordSize)
+	body[saltLength+4] = byte(len(asPublic))
+	copy(body[saltLength+5:], asPublic)
+
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+	return gcm.Seal(body, nonce, plaintext, nil), nil
+}
+
+// expand derives length bytes with HKDF-SHA-256.
+func expand(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestEncryptWithRFC8291Vector checks the example of RFC 8291 Appendix A.
+func TestEncryptWithRFC8291Vector(t *testing.T) {
+	const (
+		plaintext  = "When I grow up, I want to be a watermelon"
+		asPrivate  = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+		uaPublic   = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+		authSecret = "BTBZMqHH6r4Tts7J_aSIgg"
+		salt       = "DGv6ra1nlYgDCS1FRnbzlw"
+		want       = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	)
+
+	sub := &Subscription{Endpoint: "https://push.example.net/push/JzLQ3raZJfFBR0aqvOMsLrt54w4rJUsV", P256dh: uaPublic, Auth: authSecret}
+	uaKey, auth, err := sub.keys()
+	if err != nil {
+		t.Fatalf("keys: %v", err)
+	}
+	rawPrivate, err := decodeKey(asPrivate)
+	if err != nil {
+		t.Fatalf("decode private key: %v", err)
+	}
+	asKey, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		t.Fatalf("private key: %v", err)
+	}
+	rawSalt, err := decodeKey(salt)
+	if err != nil {
+		t.Fatalf("decode salt: %v", err)
+	}
+
+	body, err := encryptWith(uaKey, auth, asKey, rawSalt, []byte(plaintext))
+	if err != nil {
+		t.Fatalf("encryptWith: %v", err)
+	}
+	if got := b64.EncodeToString(body); got != want {
+		t.Errorf("encryptWith =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEncryptPayloadTooLarge(t *testing.T) {
+	sub := &Subscription{
+		P256dh: "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:   "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+	if _, err := encrypt(sub, []byte(strings.Repeat("a", MaxPayload))); err != nil {
+		t.Errorf("encrypt of MaxPayload bytes: %v", err)
+	}
+	if _, err := encrypt(sub, []byte(strings.Repeat("a", MaxPayload+1))); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("encrypt of MaxPayload+1 bytes = %v, want ErrPayloadTooLarge", err)
+	}
+}
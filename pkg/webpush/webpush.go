@@ -0,0 +1,231 @@
+// Package webpush delivers Web Push messages (RFC 8030) with encrypted
+// payloads (RFC 8291) and VAPID authentication (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vern/skillflow/internal/config"
+)
+
+const (
+	// defaultTTL is how long the push service keeps an undelivered message.
+	defaultTTL = 24 * time.Hour
+	// tokenLifetime is the validity of VAPID tokens; RFC 8292 allows at
+	// most 24 hours.
+	tokenLifetime = 12 * time.Hour
+)
+
+var (
+	// ErrGone is returned when the push service reports that the
+	// subscription no longer exists. It should be deleted.
+	ErrGone = errors.New("webpush: subscription expired or unsubscribed")
+	// ErrInvalidSubscription is returned for malformed subscription keys.
+	ErrInvalidSubscription = errors.New("webpush: invalid subscription")
+)
+
+var b64 = base64.RawURLEncoding
+
+// Subscription is a browser's PushSubscription: the push service endpoint
+// and the base64url-encoded keys the payload is encrypted for.
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Validate checks that the endpoint is an absolute HTTPS URL and the keys
+// are well formed.
+func (s *Subscription) Validate() error {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidSubscription)
+	}
+	_, _, err = s.keys()
+	return err
+}
+
+func (s *Subscription) keys() (*ecdh.PublicKey, []byte, error) {
+	raw, err := decodeKey(s.P256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: p256dh is not base64url", ErrInvalidSubscription)
+	}
+	public, err := ecdh.P256().NewPublicKey(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: p256dh is not a P-256 public key", ErrInvalidSubscription)
+	}
+	auth, err := decodeKey(s.Auth)
+	if err != nil || len(auth) != 16 {
+		return nil, nil, fmt.Errorf("%w: auth must be 16 bytes", ErrInvalidSubscription)
+	}
+	return public, auth, nil
+}
+
+// decodeKey accepts base64url with or without padding, as browsers differ.
+func decodeKey(s string) ([]byte, error) {
+	return b64.DecodeString(string(bytes.TrimRight([]byte(s), "=")))
+}
+
+// Options control how the push service handles a message.
+type Options struct {
+	TTL     time.Duration
+	Urgency string // very-low, low, normal (default) or high
+	Topic   string // replaces an undelivered message with the same topic
+}
+
+// Client sends messages signed with the application's VAPID key.
+type Client struct {
+	key        *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+	ttl        time.Duration
+	httpClient *http.Client
+}
+
+// New returns a client for cfg, or nil when no VAPID key is configured.
+func New(cfg config.WebPushConfig) (*Client, error) {
+	if cfg.PrivateKey == "" && cfg.PublicKey == "" {
+		return nil, nil
+	}
+	if cfg.Subject == "" {
+		return nil, errors.New("webpush: subject is required (mailto: or https: URL)")
+	}
+	key, err := parsePrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := b64.EncodeToString(elliptic.Marshal(elliptic.P256(), key.X, key.Y))
+	if cfg.PublicKey != "" && cfg.PublicKey != publicKey {
+		return nil, errors.New("webpush: public key does not match private key")
+	}
+	ttl := time.Duration(cfg.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &Client{
+		key:        key,
+		publicKey:  publicKey,
+		subject:    cfg.Subject,
+		ttl:        ttl,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// WithHTTPClient replaces the HTTP client, e.g. to reach a local push
+// service stand-in.
+func (c *Client) WithHTTPClient(client *http.Client) *Client {
+	c.httpClient = client
+	return c
+}
+
+// PublicKey returns the base64url-encoded application server key that
+// browsers pass to pushManager.subscribe.
+func (c *Client) PublicKey() string { return c.publicKey }
+
+// Send encrypts the payload for the subscription and posts it to the push
+// service. It returns ErrGone when the subscription no longer exists.
+func (c *Client) Send(ctx context.Context, sub *Subscription, payload []byte, opts Options) error {
+	body, err := encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+	auth, err := c.authorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	if opts.Urgency != "" {
+		req.Header.Set("Urgency", opts.Urgency)
+	}
+	if opts.Topic != "" {
+		req.Header.Set("Topic", opts.Topic)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode == http.StatusRequestEntityTooLarge:
+		return ErrPayloadTooLarge
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("webpush: push service returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// authorization builds the "vapid" Authorization header for the endpoint's
+// origin.
+func (c *Client) authorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(tokenLifetime).Unix(),
+		"sub": c.subject,
+	}).SignedString(c.key)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", token, c.publicKey), nil
+}
+
+// GenerateVAPIDKeys returns a new base64url-encoded key pair for the
+// webpush configuration.
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return b64.EncodeToString(key.PublicKey().Bytes()), b64.EncodeToString(key.Bytes()), nil
+}
+
+func parsePrivateKey(encoded string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeKey(encoded)
+	if err != nil {
+		return nil, errors.New("webpush: private key is not base64url")
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, errors.New("webpush: private key is not a P-256 key")
+	}
+	x, y := elliptic.Unmarshal(elliptic.P256(), key.PublicKey().Bytes())
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y},
+		D:         new(big.Int).SetBytes(raw),
+	}, nil
+}
@@ -0,0 +1,190 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/vern/skillflow/internal/config"
+)
+
+// newTestSubscription returns a subscription to endpoint with fresh keys.
+func newTestSubscription(t *testing.T, endpoint string) *Subscription {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatalf("generate auth secret: %v", err)
+	}
+	return &Subscription{
+		Endpoint: endpoint,
+		P256dh:   b64.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     b64.EncodeToString(auth),
+	}
+}
+
+func newTestClient(t *testing.T, ttl int) *Client {
+	t.Helper()
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKeys: %v", err)
+	}
+	client, err := New(config.WebPushConfig{
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		Subject:    "mailto:ops@example.com",
+		TTL:        ttl,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return client
+}
+
+func TestSendHeaders(t *testing.T) {
+	var req *http.Request
+	var body []byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, 3600).WithHTTPClient(server.Client())
+	sub := newTestSubscription(t, server.URL+"/push/abc")
+	payload := []byte(`{"title":"Hello"}`)
+
+	tests := []struct {
+		name    string
+		opts    Options
+		ttl     string
+		topic   string
+		urgency string
+	}{
+		{"defaults", Options{}, "3600", "", ""},
+		{"options", Options{TTL: 90 * time.Second, Topic: "notification-7", Urgency: "high"}, "90", "notification-7", "high"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := client.Send(context.Background(), sub, payload, tt.opts); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if req.Method != http.MethodPost || req.URL.Path != "/push/abc" {
+				t.Errorf("request = %s %s, want POST /push/abc", req.Method, req.URL.Path)
+			}
+			for header, want := range map[string]string{
+				"TTL":              tt.ttl,
+				"Topic":            tt.topic,
+				"Urgency":          tt.urgency,
+				"Content-Encoding": "aes128gcm",
+				"Content-Type":     "application/octet-stream",
+			} {
+				if got := req.Header.Get(header); got != want {
+					t.Errorf("%s header = %q, want %q", header, got, want)
+				}
+			}
+			if want := headerLength + len(payload) + 1 + tagLength; len(body) != want {
+				t.Errorf("body is %d bytes, want %d", len(body), want)
+			}
+			checkVAPID(t, req.Header.Get("Authorization"), client, server.URL)
+		})
+	}
+}
+
+// checkVAPID verifies the RFC 8292 Authorization header: a JWT for the push
+// service's origin signed with the client's key, which is sent along.
+func checkVAPID(t *testing.T, header string, client *Client, origin string) {
+	t.Helper()
+	rest, ok := strings.CutPrefix(header, "vapid ")
+	if !ok {
+		t.Fatalf("Authorization = %q, want vapid scheme", header)
+	}
+	params := make(map[string]string)
+	for _, param := range strings.Split(rest, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		params[name] = value
+	}
+	if params["k"] != client.PublicKey() {
+		t.Errorf("k = %q, want %q", params["k"], client.PublicKey())
+	}
+
+	raw, err := b64.DecodeString(params["k"])
+	if err != nil {
+		t.Fatalf("decode k: %v", err)
+	}
+	x, y := elliptic.Unmarshal(elliptic.P256(), raw)
+	if x == nil {
+		t.Fatalf("k is not an uncompressed P-256 point")
+	}
+	publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(params["t"], claims, func(token *jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(origin), jwt.WithExpirationRequired())
+	if err != nil {
+		t.Fatalf("token does not verify: %v", err)
+	}
+	if claims["sub"] != "mailto:ops@example.com" {
+		t.Errorf("sub = %v, want mailto:ops@example.com", claims["sub"])
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("exp = %v, want within 24 hours", exp)
+	}
+}
+
+func TestSendStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusCreated, nil},
+		{http.StatusNotFound, ErrGone},
+		{http.StatusGone, ErrGone},
+		{http.StatusRequestEntityTooLarge, ErrPayloadTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := newTestClient(t, 0).WithHTTPClient(server.Client())
+			err := client.Send(context.Background(), newTestSubscription(t, server.URL), []byte("hi"), Options{})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Send = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("server error", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := newTestClient(t, 0).WithHTTPClient(server.Client())
+		err := client.Send(context.Background(), newTestSubscription(t, server.URL), []byte("hi"), Options{})
+		if err == nil || errors.Is(err, ErrGone) || !strings.Contains(err.Error(), "overloaded") {
+			t.Errorf("Send = %v, want push service error with its detail", err)
+		}
+	})
+}